@@ -0,0 +1,123 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/thanhphuocnguyen/go-simple-bank/auth"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+type addAccountMemberRequest struct {
+	Username   string `json:"username" binding:"required,alphanum"`
+	Permission string `json:"permission" binding:"required,oneof=view transact manage"`
+}
+
+func (server *Server) addAccountMember(ctx *gin.Context) {
+	var params getAccountParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req addAccountMemberRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.getAuthorizedAccount(ctx, params.ID, db.PermissionManage)
+	if !valid {
+		return
+	}
+
+	if req.Username == account.Owner {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("the owner is already a member of the account")))
+		return
+	}
+
+	member, err := server.store.CreateAccountMember(ctx, db.CreateAccountMemberParams{
+		AccountID:  account.ID,
+		Username:   req.Username,
+		Permission: req.Permission,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505", "23503": // unique_violation, foreign_key_violation
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+func (server *Server) getAccountMembers(ctx *gin.Context) {
+	var params getAccountParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.getAuthorizedAccount(ctx, params.ID, db.PermissionView)
+	if !valid {
+		return
+	}
+
+	members, err := server.store.ListAccountMembers(ctx, account.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, members)
+}
+
+type removeAccountMemberParams struct {
+	ID       int64  `uri:"id" binding:"required,min=1"`
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+// removeAccountMember lets a manager remove anyone, and any member leave
+// the account on their own.
+func (server *Server) removeAccountMember(ctx *gin.Context) {
+	var params removeAccountMemberParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	permission := db.PermissionManage
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	if authPayload.Username == params.Username {
+		permission = db.PermissionView
+	}
+
+	_, valid := server.getAuthorizedAccount(ctx, params.ID, permission)
+	if !valid {
+		return
+	}
+
+	member, err := server.store.DeleteAccountMember(ctx, db.DeleteAccountMemberParams{
+		AccountID: params.ID,
+		Username:  params.Username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
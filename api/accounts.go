@@ -1,9 +1,7 @@
 package api
 
 import (
-	"database/sql"
 	"errors"
-	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -12,8 +10,26 @@ import (
 	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
 )
 
+type accountResponse struct {
+	db.Account
+	AvailableBalance int64 `json:"available_balance"`
+}
+
+// newAccountResponse computes the available balance, which is the balance
+// less every active hold on the account.
+func (server *Server) newAccountResponse(ctx *gin.Context, account db.Account) (accountResponse, error) {
+	held, err := server.store.GetActiveHoldsAmount(ctx, account.ID)
+	if err != nil {
+		return accountResponse{}, err
+	}
+
+	return accountResponse{Account: account, AvailableBalance: account.Balance - held}, nil
+}
+
 type createAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
+	Type     string `json:"type" binding:"omitempty,oneof=checking savings"`
+	Nickname string `json:"nickname" binding:"max=50"`
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
@@ -22,22 +38,32 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
+	if req.Type == "" {
+		req.Type = db.AccountTypeChecking
+	}
+
 	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
-	arg := db.CreateAccountParams{
-		Owner:    authPayload.Username,
-		Balance:  0,
-		Currency: req.Currency,
+	arg := db.CreateAccountTxParams{
+		CreateAccountParams: db.CreateAccountParams{
+			Owner:    authPayload.Username,
+			Balance:  0,
+			Currency: req.Currency,
+			Type:     req.Type,
+			Nickname: req.Nickname,
+		},
+		MaxAccounts: server.maxAccounts(req.Type),
 	}
 
-	account, err := server.store.CreateAccount(ctx, arg)
+	account, err := server.store.CreateAccountTx(ctx, arg)
 	if err != nil {
+		if db.ErrorKindOf(err) != 0 {
+			storeErrorResponse(ctx, err)
+			return
+		}
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
 			switch pgErr.Code {
-			case "23505": // unique_violation
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-				// foreign_key_violation
+			// foreign_key_violation
 			case "23503":
 				ctx.JSON(http.StatusForbidden, errorResponse(err))
 			// Add more cases as needed
@@ -50,7 +76,15 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, accountResponse{Account: account, AvailableBalance: account.Balance})
+}
+
+// maxAccounts returns the configured per-user limit for an account type.
+func (server *Server) maxAccounts(accountType string) int64 {
+	if accountType == db.AccountTypeSavings {
+		return server.config.MaxSavingsAccounts
+	}
+	return server.config.MaxCheckingAccounts
 }
 
 type getAccountParams struct {
@@ -64,20 +98,70 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	account, err := server.store.GetAccount(ctx, params.ID)
-	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
-	log.Println(authPayload)
+	account, valid := server.getAuthorizedAccount(ctx, params.ID, db.PermissionView)
+	if !valid {
+		return
+	}
+
+	res, err := server.newAccountResponse(ctx, account)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type setApprovalThresholdRequest struct {
+	ApprovalThreshold int64 `json:"approval_threshold" binding:"min=0"`
+}
+
+// setApprovalThreshold makes transfers above the threshold wait for a
+// second approver. A threshold of zero turns approvals off. An account
+// without a threshold gets one at once, but once it has one, any change,
+// switching it off included, is only requested here and takes effect when
+// another manage member approves it, so no single member can undo dual
+// control.
+func (server *Server) setApprovalThreshold(ctx *gin.Context) {
+	var params getAccountParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req setApprovalThresholdRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.getAuthorizedAccount(ctx, params.ID, db.PermissionManage)
+	if !valid {
+		return
+	}
+
+	if account.ApprovalThreshold > 0 {
+		authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+		change, err := server.store.CreateApprovalThresholdChange(ctx, db.CreateApprovalThresholdChangeParams{
+			AccountID:         account.ID,
+			ApprovalThreshold: req.ApprovalThreshold,
+			RequestedBy:       authPayload.Username,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		ctx.JSON(http.StatusAccepted, change)
 		return
 	}
 
-	if account.Owner != authPayload.Username {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("account doesn't belong to the authenticated user")))
+	account, err := server.store.SetApprovalThreshold(ctx, db.SetApprovalThresholdParams{
+		ID:                account.ID,
+		ApprovalThreshold: req.ApprovalThreshold,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
@@ -110,5 +194,14 @@ func (server *Server) getAccounts(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	res := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		res[i], err = server.newAccountResponse(ctx, account)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, res)
 }
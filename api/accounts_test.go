@@ -42,12 +42,57 @@ func TestGetAccountAPI(t *testing.T) {
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
 					Times(1).
 					Return(account, nil)
+				store.EXPECT().
+					GetActiveHoldsAmount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(int64(0), nil)
 			},
 			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 				requireBodyMatchAccount(t, recorder.Body, account)
 			},
 		},
+		{
+			name:      "AvailableBalance",
+			accountID: account.ID,
+			setupAuthHeader: func(t *testing.T, request *http.Request, tokenGenerator auth.TokenGenerator) {
+				addAuthHeader(t, request, tokenGenerator, authorizationType, account.Owner, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					GetActiveHoldsAmount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(int64(40), nil)
+			},
+			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAvailableBalance(t, recorder.Body, account.Balance-40)
+			},
+		},
+		{
+			name:      "GetActiveHoldsAmountError",
+			accountID: account.ID,
+			setupAuthHeader: func(t *testing.T, request *http.Request, tokenGenerator auth.TokenGenerator) {
+				addAuthHeader(t, request, tokenGenerator, authorizationType, account.Owner, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					GetActiveHoldsAmount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+			},
+			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
 		{
 			name:      "Wrong User",
 			accountID: account.ID,
@@ -59,6 +104,10 @@ func TestGetAccountAPI(t *testing.T) {
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
 					Times(1).
 					Return(account, nil)
+				store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountMember{}, sql.ErrNoRows)
 			},
 			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -171,13 +220,17 @@ func TestCreateAccountAPI(t *testing.T) {
 				addAuthHeader(t, request, tokenGenerator, authorizationType, account.Owner, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				arg := db.CreateAccountParams{
-					Owner:    account.Owner,
-					Balance:  0,
-					Currency: account.Currency,
+				arg := db.CreateAccountTxParams{
+					CreateAccountParams: db.CreateAccountParams{
+						Owner:    account.Owner,
+						Balance:  0,
+						Currency: account.Currency,
+						Type:     db.AccountTypeChecking,
+					},
+					MaxAccounts: 3,
 				}
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(arg)).
+					CreateAccountTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(account, nil)
 			},
@@ -186,6 +239,72 @@ func TestCreateAccountAPI(t *testing.T) {
 				requireBodyMatchAccount(t, recorder.Body, account)
 			},
 		},
+		{
+			name: "SavingsWithNickname",
+			body: gin.H{
+				"currency": account.Currency,
+				"type":     db.AccountTypeSavings,
+				"nickname": "Rainy day",
+			},
+			setupAuthHeader: func(t *testing.T, request *http.Request, tokenGenerator auth.TokenGenerator) {
+				addAuthHeader(t, request, tokenGenerator, authorizationType, account.Owner, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountTxParams{
+					CreateAccountParams: db.CreateAccountParams{
+						Owner:    account.Owner,
+						Balance:  0,
+						Currency: account.Currency,
+						Type:     db.AccountTypeSavings,
+						Nickname: "Rainy day",
+					},
+					MaxAccounts: 5,
+				}
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(account, nil)
+			},
+			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidType",
+			body: gin.H{
+				"currency": account.Currency,
+				"type":     "brokerage",
+			},
+			setupAuthHeader: func(t *testing.T, request *http.Request, tokenGenerator auth.TokenGenerator) {
+				addAuthHeader(t, request, tokenGenerator, authorizationType, account.Owner, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AccountLimitReached",
+			body: gin.H{
+				"currency": account.Currency,
+			},
+			setupAuthHeader: func(t *testing.T, request *http.Request, tokenGenerator auth.TokenGenerator) {
+				addAuthHeader(t, request, tokenGenerator, authorizationType, account.Owner, time.Minute)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, db.ErrAccountLimitReached)
+			},
+			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "BadRequest",
 			body: gin.H{
@@ -196,7 +315,7 @@ func TestCreateAccountAPI(t *testing.T) {
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(account.ID)).
+					CreateAccountTx(gomock.Any(), gomock.Eq(account.ID)).
 					Times(0)
 			},
 			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -212,19 +331,23 @@ func TestCreateAccountAPI(t *testing.T) {
 				addAuthHeader(t, request, tokenGenerator, authorizationType, account.Owner, time.Minute)
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				arg := db.CreateAccountParams{
-					Owner:    account.Owner,
-					Balance:  0,
-					Currency: account.Currency,
+				arg := db.CreateAccountTxParams{
+					CreateAccountParams: db.CreateAccountParams{
+						Owner:    account.Owner,
+						Balance:  0,
+						Currency: account.Currency,
+						Type:     db.AccountTypeChecking,
+					},
+					MaxAccounts: 3,
 				}
 				pgErr := &pgconn.PgError{Code: "23505"}
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(arg)).
+					CreateAccountTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(db.Account{}, pgErr)
 			},
 			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
 		{
@@ -233,14 +356,18 @@ func TestCreateAccountAPI(t *testing.T) {
 				"currency": account.Currency,
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				arg := db.CreateAccountParams{
-					Owner:    account.Owner,
-					Balance:  0,
-					Currency: account.Currency,
+				arg := db.CreateAccountTxParams{
+					CreateAccountParams: db.CreateAccountParams{
+						Owner:    account.Owner,
+						Balance:  0,
+						Currency: account.Currency,
+						Type:     db.AccountTypeChecking,
+					},
+					MaxAccounts: 3,
 				}
 				pgErr := &pgconn.PgError{Code: "23503"}
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(arg)).
+					CreateAccountTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(db.Account{}, pgErr)
 			},
@@ -258,7 +385,7 @@ func TestCreateAccountAPI(t *testing.T) {
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Any()).
+					CreateAccountTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.Account{}, sql.ErrConnDone)
 			},
@@ -279,7 +406,7 @@ func TestCreateAccountAPI(t *testing.T) {
 			},
 			buildStub: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Any()).
+					CreateAccountTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -350,6 +477,10 @@ func TestGetAccountsAPI(t *testing.T) {
 					ListAccounts(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(accounts, nil)
+				store.EXPECT().
+					GetActiveHoldsAmount(gomock.Any(), gomock.Any()).
+					Times(n).
+					Return(int64(0), nil)
 			},
 			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -460,11 +591,15 @@ func TestGetAccountsAPI(t *testing.T) {
 }
 
 func randomAccount(username string) db.Account {
+	accountNumber, _ := utils.NewAccountNumber()
 	return db.Account{
-		ID:       utils.RandomInt(1, 1000),
-		Owner:    username,
-		Balance:  utils.RandomMoney(),
-		Currency: utils.RandomCurrency(),
+		ID:            utils.RandomInt(1, 1000),
+		Owner:         username,
+		Balance:       utils.RandomMoney(),
+		Currency:      utils.RandomCurrency(),
+		Type:          db.AccountTypeChecking,
+		Nickname:      utils.RandomString(6),
+		AccountNumber: accountNumber,
 	}
 }
 
@@ -487,3 +622,13 @@ func requireBodyMatchAccounts(t *testing.T, body *bytes.Buffer, accounts []db.Ac
 	require.NoError(t, err)
 	require.Equal(t, accounts, gotAccounts)
 }
+
+func requireBodyMatchAvailableBalance(t *testing.T, body *bytes.Buffer, availableBalance int64) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotAccount accountResponse
+	err = json.Unmarshal(data, &gotAccount)
+	require.NoError(t, err)
+	require.Equal(t, availableBalance, gotAccount.AvailableBalance)
+}
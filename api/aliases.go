@@ -0,0 +1,256 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/thanhphuocnguyen/go-simple-bank/auth"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+var (
+	errAliasNotFound        = errors.New("no verified recipient with this alias")
+	errAliasTaken           = errors.New("alias is already claimed")
+	errAliasUsername        = errors.New("usernames are paid without claiming them as an alias")
+	errAliasAlreadyVerified = errors.New("alias is already verified")
+)
+
+type claimAliasRequest struct {
+	Alias string `json:"alias" binding:"required,alias"`
+}
+
+// claimAlias registers an email or phone alias for the authenticated user.
+// The user's registered email is verified straight away; any other email
+// and phone numbers stay unverified, and so cannot be paid, until a banker
+// verifies them. A verified claim takes the alias over from an unverified
+// one, so nobody can squat another user's registered email. Usernames are
+// not claimed: every user can be paid by theirs.
+func (server *Server) claimAlias(ctx *gin.Context) {
+	var req claimAliasRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	alias, kind, _ := utils.NormalizeAlias(req.Alias)
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+
+	var verifiedAt pgtype.Timestamptz
+	switch kind {
+	case utils.AliasUsername:
+		ctx.JSON(http.StatusBadRequest, errorResponse(errAliasUsername))
+		return
+	case utils.AliasEmail:
+		user, err := server.store.GetUser(ctx, authPayload.Username)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if strings.EqualFold(user.Email, alias) {
+			verifiedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+		}
+	}
+
+	claimed, err := server.store.CreateAlias(ctx, db.CreateAliasParams{
+		Alias:      alias,
+		Kind:       kind,
+		Username:   authPayload.Username,
+		VerifiedAt: verifiedAt,
+	})
+	if err != nil {
+		// someone holds it already, and this claim may not take it over
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(errAliasTaken))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, claimed)
+}
+
+func (server *Server) listAliases(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	aliases, err := server.store.ListAliases(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, aliases)
+}
+
+type aliasParams struct {
+	Alias string `uri:"alias" binding:"required,alias"`
+}
+
+// releaseAlias gives up one of the authenticated user's aliases so that
+// someone else can claim it.
+func (server *Server) releaseAlias(ctx *gin.Context) {
+	var params aliasParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	alias, _, _ := utils.NormalizeAlias(params.Alias)
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	released, err := server.store.DeleteAlias(ctx, db.DeleteAliasParams{
+		Alias:    alias,
+		Username: authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, released)
+}
+
+func (server *Server) verifyAlias(ctx *gin.Context) {
+	var params aliasParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	alias, _, _ := utils.NormalizeAlias(params.Alias)
+	verified, err := server.store.VerifyAlias(ctx, alias)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		// tell a missing alias apart from one that is already verified
+		if _, err = server.store.GetAlias(ctx, alias); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				ctx.JSON(http.StatusNotFound, errorResponse(err))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusConflict, errorResponse(errAliasAlreadyVerified))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, verified)
+}
+
+type lookupAliasRequest struct {
+	Alias    string `form:"alias" binding:"required,alias"`
+	Currency string `form:"currency" binding:"required,currency"`
+}
+
+// lookupAliasResponse lets a sender confirm who they are about to pay
+// without learning the recipient's full name or account.
+type lookupAliasResponse struct {
+	Alias         string `json:"alias"`
+	Kind          string `json:"kind"`
+	RecipientName string `json:"recipient_name"`
+	Currency      string `json:"currency"`
+}
+
+func (server *Server) lookupAlias(ctx *gin.Context) {
+	var req lookupAliasRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	alias, _, valid := server.resolveAlias(ctx, req.Alias, req.Currency)
+	if !valid {
+		return
+	}
+
+	recipient, err := server.store.GetUser(ctx, alias.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, lookupAliasResponse{
+		Alias:         alias.Alias,
+		Kind:          alias.Kind,
+		RecipientName: utils.MaskName(recipient.FullName),
+		Currency:      req.Currency,
+	})
+}
+
+// resolveAlias finds the recipient's default account in currency: their
+// oldest checking account, or their oldest account when they hold no
+// checking account in it. A username resolves to its user directly, as an
+// alias of itself; emails and phone numbers resolve through a verified
+// alias only.
+func (server *Server) resolveAlias(ctx *gin.Context, rawAlias string, currency string) (db.Alias, db.Account, bool) {
+	normalized, kind, err := utils.NormalizeAlias(rawAlias)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.Alias{}, db.Account{}, false
+	}
+
+	var alias db.Alias
+	if kind == utils.AliasUsername {
+		alias, err = server.usernameAlias(ctx, normalized)
+	} else {
+		alias, err = server.store.GetAlias(ctx, normalized)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errAliasNotFound))
+			return alias, db.Account{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return alias, db.Account{}, false
+	}
+
+	if !alias.VerifiedAt.Valid {
+		ctx.JSON(http.StatusNotFound, errorResponse(errAliasNotFound))
+		return alias, db.Account{}, false
+	}
+
+	account, err := server.store.GetDefaultAccount(ctx, db.GetDefaultAccountParams{
+		Owner:    alias.Username,
+		Currency: currency,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("recipient has no %s account", currency)))
+			return alias, account, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return alias, account, false
+	}
+
+	return alias, account, true
+}
+
+// usernameAlias looks a username up in users and presents the user as a
+// verified alias of itself.
+func (server *Server) usernameAlias(ctx *gin.Context, username string) (db.Alias, error) {
+	user, err := server.store.GetUser(ctx, username)
+	if err != nil {
+		return db.Alias{}, err
+	}
+
+	return db.Alias{
+		Alias:      user.Username,
+		Kind:       utils.AliasUsername,
+		Username:   user.Username,
+		VerifiedAt: pgtype.Timestamptz{Time: user.CreatedAt.Time, Valid: true},
+		CreatedAt:  user.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,418 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func verifiedAlias(alias, kind, username string) db.Alias {
+	return db.Alias{
+		Alias:      alias,
+		Kind:       kind,
+		Username:   username,
+		VerifiedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+}
+
+func TestClaimAliasAPI(t *testing.T) {
+	user, _, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		alias         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "Username",
+			alias: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "RegisteredEmail",
+			alias: "  " + user.Email + " ",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAliasParams) (db.Alias, error) {
+						require.Equal(t, utils.AliasEmail, arg.Kind)
+						require.True(t, arg.VerifiedAt.Valid)
+						return db.Alias{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "OtherEmail",
+			alias: "Other@Example.com",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				arg := db.CreateAliasParams{Alias: "other@example.com", Kind: utils.AliasEmail, Username: user.Username}
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Alias{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "Phone",
+			alias: "+1 (415) 555-0100",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAliasParams{Alias: "+14155550100", Kind: utils.AliasPhone, Username: user.Username}
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Alias{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "Taken",
+			alias: "+14155550100",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.Alias{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidAlias",
+			alias: "not an alias",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"alias": tc.alias})
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/aliases", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReleaseAliasAPI(t *testing.T) {
+	user, _, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	arg := db.DeleteAliasParams{Alias: "+14155550100", Username: user.Username}
+	gomock.InOrder(
+		store.EXPECT().DeleteAlias(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Alias{Alias: arg.Alias}, nil),
+		store.EXPECT().DeleteAlias(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Alias{}, sql.ErrNoRows),
+	)
+
+	server := createNewServer(t, store)
+	for _, code := range []int{http.StatusOK, http.StatusNotFound} {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodDelete, "/aliases/"+url.PathEscape("+1-415-555-0100"), nil)
+		require.NoError(t, err)
+
+		addAuthHeader(t, req, server.tokenGenerator, authorizationType, user.Username, time.Minute)
+		server.router.ServeHTTP(recorder, req)
+		require.Equal(t, code, recorder.Code)
+	}
+}
+
+func TestVerifyAliasAPI(t *testing.T) {
+	banker, _, _ := randomUser(t)
+	banker.Role = db.UserRoleBanker
+	depositor, _, _ := randomUser(t)
+	depositor.Role = db.UserRoleDepositor
+	alias := "+14155550100"
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: banker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+				store.EXPECT().VerifyAlias(gomock.Any(), gomock.Eq(alias)).Times(1).
+					Return(verifiedAlias(alias, utils.AliasPhone, depositor.Username), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			user: banker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+				store.EXPECT().VerifyAlias(gomock.Any(), gomock.Eq(alias)).Times(1).Return(db.Alias{}, sql.ErrNoRows)
+				store.EXPECT().GetAlias(gomock.Any(), gomock.Eq(alias)).Times(1).
+					Return(verifiedAlias(alias, utils.AliasPhone, depositor.Username), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			user: banker,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+				store.EXPECT().VerifyAlias(gomock.Any(), gomock.Eq(alias)).Times(1).Return(db.Alias{}, sql.ErrNoRows)
+				store.EXPECT().GetAlias(gomock.Any(), gomock.Eq(alias)).Times(1).Return(db.Alias{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NotBanker",
+			user: depositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(depositor.Username)).Times(1).Return(depositor, nil)
+				store.EXPECT().VerifyAlias(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, "/aliases/"+alias+"/verify", nil)
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLookupAliasAPI(t *testing.T) {
+	sender, _, _ := randomUser(t)
+	recipient, _, _ := randomUser(t)
+	recipient.FullName = "Jane Doe"
+	account := randomAccount(recipient.Username)
+	account.Currency = utils.USD
+	email := "jane@example.com"
+
+	testCases := []struct {
+		name          string
+		alias         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			alias: "Jane@Example.com",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAlias(gomock.Any(), gomock.Eq(email)).Times(1).
+					Return(verifiedAlias(email, utils.AliasEmail, recipient.Username), nil)
+				arg := db.GetDefaultAccountParams{Owner: recipient.Username, Currency: utils.USD}
+				store.EXPECT().GetDefaultAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got lookupAliasResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, lookupAliasResponse{
+					Alias:         email,
+					Kind:          utils.AliasEmail,
+					RecipientName: "J*** D**",
+					Currency:      utils.USD,
+				}, got)
+				require.NotContains(t, recorder.Body.String(), account.AccountNumber)
+			},
+		},
+		{
+			name:  "Unverified",
+			alias: email,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAlias(gomock.Any(), gomock.Eq(email)).Times(1).
+					Return(db.Alias{Alias: email, Kind: utils.AliasEmail, Username: recipient.Username}, nil)
+				store.EXPECT().GetDefaultAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "Unknown",
+			alias: email,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAlias(gomock.Any(), gomock.Eq(email)).Times(1).Return(db.Alias{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "Username",
+			alias: recipient.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAlias(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.Username)).Times(2).Return(recipient, nil)
+				arg := db.GetDefaultAccountParams{Owner: recipient.Username, Currency: utils.USD}
+				store.EXPECT().GetDefaultAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got lookupAliasResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, lookupAliasResponse{
+					Alias:         recipient.Username,
+					Kind:          utils.AliasUsername,
+					RecipientName: "J*** D**",
+					Currency:      utils.USD,
+				}, got)
+			},
+		},
+		{
+			name:  "UnknownUsername",
+			alias: "nosuchuser",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq("nosuchuser")).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().GetDefaultAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "NoAccountInCurrency",
+			alias: email,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAlias(gomock.Any(), gomock.Eq(email)).Times(1).
+					Return(verifiedAlias(email, utils.AliasEmail, recipient.Username), nil)
+				store.EXPECT().GetDefaultAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			query := url.Values{"alias": {tc.alias}, "currency": {utils.USD}}
+			req, err := http.NewRequest(http.MethodGet, "/aliases/lookup?"+query.Encode(), nil)
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, sender.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateTransferToAlias(t *testing.T) {
+	sender, _, _ := randomUser(t)
+	recipient, _, _ := randomUser(t)
+	fromAccount := randomAccount(sender.Username)
+	fromAccount.Currency = utils.USD
+	toAccount := randomAccount(recipient.Username)
+	toAccount.Currency = utils.USD
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	// usernames are looked up in users, not in the alias registry
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+	store.EXPECT().GetAlias(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().GetDefaultAccount(gomock.Any(), gomock.Eq(db.GetDefaultAccountParams{
+		Owner:    recipient.Username,
+		Currency: utils.USD,
+	})).Times(1).Return(toAccount, nil)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+	})).Times(1)
+
+	server := createNewServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{
+		"from_account_id": fromAccount.ID,
+		"to_alias":        recipient.Username,
+		"amount":          10,
+		"currency":        utils.USD,
+	})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthHeader(t, req, server.tokenGenerator, authorizationType, sender.Username, time.Minute)
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
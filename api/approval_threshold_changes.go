@@ -0,0 +1,126 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhphuocnguyen/go-simple-bank/auth"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+type listApprovalThresholdChangesRequest struct {
+	AccountID int64 `form:"account_id" binding:"required,min=1"`
+	Page      int32 `form:"page" binding:"required,min=1"`
+	PageSize  int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listApprovalThresholdChanges(ctx *gin.Context) {
+	var req listApprovalThresholdChangesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.getAuthorizedAccount(ctx, req.AccountID, db.PermissionView); !valid {
+		return
+	}
+
+	changes, err := server.store.ListApprovalThresholdChanges(ctx, db.ListApprovalThresholdChangesParams{
+		AccountID: req.AccountID,
+		Limit:     req.PageSize,
+		Offset:    (req.Page - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, changes)
+}
+
+type thresholdChangeParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// approveThresholdChange applies a requested threshold change. The
+// approver must manage the account and must not be the one who requested
+// the change.
+func (server *Server) approveThresholdChange(ctx *gin.Context) {
+	var params thresholdChangeParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	change, valid := server.validThresholdChange(ctx, params.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	result, err := server.store.ApproveThresholdChangeTx(ctx, db.ApproveThresholdChangeTxParams{
+		ChangeID: change.ID,
+		Approver: authPayload.Username,
+	})
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// rejectThresholdChange drops a requested threshold change. Any manage
+// member may reject it, the requester included, since the threshold then
+// stays as it is.
+func (server *Server) rejectThresholdChange(ctx *gin.Context) {
+	var params thresholdChangeParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	change, valid := server.validThresholdChange(ctx, params.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	change, err := server.store.DecideApprovalThresholdChange(ctx, db.DecideApprovalThresholdChangeParams{
+		ID:        change.ID,
+		Status:    db.ThresholdChangeStatusRejected,
+		DecidedBy: authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(db.ErrThresholdChangeNotPending))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, change)
+}
+
+// validThresholdChange loads a threshold change and checks that the
+// authenticated user manages its account.
+func (server *Server) validThresholdChange(ctx *gin.Context, id int64) (db.ApprovalThresholdChange, bool) {
+	change, err := server.store.GetApprovalThresholdChange(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return change, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return change, false
+	}
+
+	if _, valid := server.getAuthorizedAccount(ctx, change.AccountID, db.PermissionManage); !valid {
+		return change, false
+	}
+
+	return change, true
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func TestDecideThresholdChangeAPI(t *testing.T) {
+	owner, _, _ := randomUser(t)
+	clerk, _, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	account.ApprovalThreshold = 1_000
+
+	change := db.ApprovalThresholdChange{
+		ID:          utils.RandomInt(1, 1000),
+		AccountID:   account.ID,
+		RequestedBy: clerk.Username,
+		Status:      db.ThresholdChangeStatusPending,
+	}
+	ownChange := change
+	ownChange.RequestedBy = owner.Username
+	clerkMember := db.AccountMember{AccountID: account.ID, Username: clerk.Username, Permission: db.PermissionTransact}
+
+	expectChange := func(store *mockdb.MockStore, change db.ApprovalThresholdChange) {
+		store.EXPECT().GetApprovalThresholdChange(gomock.Any(), gomock.Eq(change.ID)).Times(1).Return(change, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		action        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Approve",
+			username: owner.Username,
+			action:   "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectChange(store, change)
+				arg := db.ApproveThresholdChangeTxParams{ChangeID: change.ID, Approver: owner.Username}
+				store.EXPECT().ApproveThresholdChangeTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.ApproveThresholdChangeTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "SelfApproval",
+			username: owner.Username,
+			action:   "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectChange(store, ownChange)
+				arg := db.ApproveThresholdChangeTxParams{ChangeID: ownChange.ID, Approver: owner.Username}
+				store.EXPECT().ApproveThresholdChangeTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.ApproveThresholdChangeTxResult{}, db.ErrSelfThresholdApproval)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "ApproverCannotManage",
+			username: clerk.Username,
+			action:   "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectChange(store, change)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(clerkMember, nil)
+				store.EXPECT().ApproveThresholdChangeTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "Reject",
+			username: owner.Username,
+			action:   "reject",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectChange(store, change)
+				arg := db.DecideApprovalThresholdChangeParams{
+					ID:        change.ID,
+					Status:    db.ThresholdChangeStatusRejected,
+					DecidedBy: owner.Username,
+				}
+				store.EXPECT().DecideApprovalThresholdChange(gomock.Any(), gomock.Eq(arg)).Times(1).Return(change, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "RejectAlreadyDecided",
+			username: owner.Username,
+			action:   "reject",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectChange(store, change)
+				store.EXPECT().DecideApprovalThresholdChange(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ApprovalThresholdChange{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: owner.Username,
+			action:   "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetApprovalThresholdChange(gomock.Any(), gomock.Eq(change.ID)).Times(1).
+					Return(db.ApprovalThresholdChange{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/approval-threshold-changes/%d/%s", change.ID, tc.action)
+			req, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhphuocnguyen/go-simple-bank/auth"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+var (
+	errNotAccountMember       = errors.New("account doesn't belong to the authenticated user")
+	errInsufficientPermission = errors.New("insufficient permission on the account")
+	errInsufficientRole       = errors.New("the authenticated user lacks the required role")
+)
+
+// authorizeAccount checks that the authenticated user may act on the
+// account with at least the required permission. The owner always holds
+// manage; everyone else needs a membership. It writes the error response
+// and returns false when access is denied.
+func (server *Server) authorizeAccount(ctx *gin.Context, account db.Account, permission string) bool {
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	if account.Owner == authPayload.Username {
+		return true
+	}
+
+	member, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errNotAccountMember))
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if !db.PermissionAllows(member.Permission, permission) {
+		ctx.JSON(http.StatusForbidden, errorResponse(errInsufficientPermission))
+		return false
+	}
+
+	return true
+}
+
+// hasPermission is authorizeAccount without the error response, for
+// resources that may be reached through more than one account.
+func (server *Server) hasPermission(ctx *gin.Context, accountID int64, permission string) (bool, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return false, err
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	if account.Owner == authPayload.Username {
+		return true, nil
+	}
+
+	member, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return db.PermissionAllows(member.Permission, permission), nil
+}
+
+// getAuthorizedAccount loads an account and checks that the authenticated
+// user holds at least the required permission on it.
+func (server *Server) getAuthorizedAccount(ctx *gin.Context, accountID int64, permission string) (db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return account, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	if !server.authorizeAccount(ctx, account, permission) {
+		return account, false
+	}
+
+	return account, true
+}
+
+// requireRole only lets users holding role through. Roles are not carried
+// in the token, so a role change takes effect on the next request.
+func (server *Server) requireRole(role string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+		user, err := server.store.GetUser(ctx, authPayload.Username)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if user.Role != role {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errInsufficientRole))
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/thanhphuocnguyen/go-simple-bank/auth"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+const (
+	maxBatchRows       = 1000
+	maxBatchMemoLength = 140
+	batchFileField     = "file"
+)
+
+// batchTransferRow pays Amount to the account numbered ToAccount.
+type batchTransferRow struct {
+	ToAccount string `json:"to_account"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Memo      string `json:"memo"`
+	// err holds a parse error from a CSV upload.
+	err string
+}
+
+// batchTransferRequest is sent either as JSON or as a multipart form with
+// the rows in a CSV file under "file". The CSV needs a header naming the
+// to_account, amount and currency columns and may add a memo column.
+type batchTransferRequest struct {
+	FromAccountID     int64              `json:"from_account_id" form:"from_account_id" binding:"required_without=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string             `json:"from_account_number" form:"from_account_number" binding:"omitempty,account_number"`
+	AllowPartial      bool               `json:"allow_partial" form:"allow_partial"`
+	Rows              []batchTransferRow `json:"rows" form:"-"`
+}
+
+// createBatchTransfer validates every row before any money moves. Unless
+// allow_partial is set, a single invalid row rejects the whole batch with
+// a report of what is wrong with each row.
+func (server *Server) createBatchTransfer(ctx *gin.Context) {
+	req, err := bindBatchTransferRequest(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if len(req.Rows) == 0 || len(req.Rows) > maxBatchRows {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("a batch must have between 1 and %d rows", maxBatchRows)))
+		return
+	}
+
+	fromAccount, valid := server.getAccountRef(ctx, req.FromAccountID, req.FromAccountNumber)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, fromAccount, db.PermissionTransact) {
+		return
+	}
+
+	rows := make([]db.BatchTransferRow, len(req.Rows))
+	invalid := 0
+	for i, row := range req.Rows {
+		rows[i], err = server.validateBatchRow(ctx, fromAccount, int32(i+1), row)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if rows[i].Error != "" {
+			invalid++
+		}
+	}
+
+	if invalid > 0 && !req.AllowPartial {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("%d of %d rows are invalid", invalid, len(rows)),
+			"rows":  rows,
+		})
+		return
+	}
+
+	result, err := server.store.BatchTransferTx(ctx, db.BatchTransferTxParams{
+		Username:      ctx.MustGet(authorizationPayload).(*auth.Payload).Username,
+		FromAccountID: fromAccount.ID,
+		AllowPartial:  req.AllowPartial,
+		Rows:          rows,
+	})
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func bindBatchTransferRequest(ctx *gin.Context) (batchTransferRequest, error) {
+	var req batchTransferRequest
+	if ctx.ContentType() != binding.MIMEMultipartPOSTForm {
+		err := ctx.ShouldBindBodyWithJSON(&req)
+		return req, err
+	}
+
+	if err := ctx.ShouldBind(&req); err != nil {
+		return req, err
+	}
+
+	header, err := ctx.FormFile(batchFileField)
+	if err != nil {
+		return req, err
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return req, err
+	}
+	defer file.Close()
+
+	req.Rows, err = parseBatchCSV(file)
+	return req, err
+}
+
+// parseBatchCSV reads rows by header name. A malformed file is an error,
+// while an unparsable amount is reported on its row.
+func parseBatchCSV(r io.Reader) ([]batchTransferRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"to_account", "amount", "currency"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv header is missing the %s column", name)
+		}
+	}
+
+	var rows []batchTransferRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row := batchTransferRow{
+			ToAccount: record[columns["to_account"]],
+			Currency:  strings.ToUpper(record[columns["currency"]]),
+		}
+		if i, ok := columns["memo"]; ok {
+			row.Memo = record[i]
+		}
+
+		row.Amount, err = strconv.ParseInt(record[columns["amount"]], 10, 64)
+		if err != nil {
+			row.err = "invalid amount"
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+// validateBatchRow resolves the destination of a row, applies the payee
+// cooling-off limit and screens it for fraud. Problems with the row are
+// reported in its Error; only store failures are returned.
+func (server *Server) validateBatchRow(ctx *gin.Context, fromAccount db.Account, number int32, row batchTransferRow) (db.BatchTransferRow, error) {
+	result := db.BatchTransferRow{
+		RowNumber: number,
+		ToAccount: row.ToAccount,
+		Amount:    row.Amount,
+		Currency:  row.Currency,
+		Memo:      row.Memo,
+		Error:     row.err,
+	}
+
+	switch {
+	case result.Error != "":
+	case !utils.IsValidAccountNumber(row.ToAccount):
+		result.Error = "invalid account number"
+	case row.Amount <= 0:
+		result.Error = "amount must be positive"
+	case row.Currency != fromAccount.Currency:
+		result.Error = "currency must match the source account"
+	case fromAccount.RequiresApproval(row.Amount):
+		result.Error = db.ErrApprovalRequired.Error()
+	case len(row.Memo) > maxBatchMemoLength:
+		result.Error = fmt.Sprintf("memo is longer than %d characters", maxBatchMemoLength)
+	}
+	if result.Error != "" {
+		return result, nil
+	}
+
+	toAccount, err := server.store.GetAccountByNumber(ctx, utils.NormalizeAccountNumber(row.ToAccount))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			result.Error = "account not found"
+			return result, nil
+		}
+		return result, err
+	}
+
+	switch {
+	case toAccount.Currency != row.Currency:
+		result.Error = db.ErrCurrencyMismatch.Error()
+	case toAccount.ID == fromAccount.ID:
+		result.Error = "cannot pay the source account"
+	}
+	if result.Error != "" {
+		return result, nil
+	}
+
+	err = server.coolingOff(ctx, toAccount, row.Amount)
+	if errors.Is(err, errPayeeCoolingOff) {
+		result.Error = err.Error()
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+
+	// a row cannot be parked for review, so a hold fails it like a block
+	outcome, err := server.checkFraud(ctx, fromAccount, toAccount, row.Amount)
+	if err != nil {
+		return result, err
+	}
+
+	switch outcome {
+	case db.FraudOutcomeBlock:
+		result.Error = errTransferBlocked.Error()
+	case db.FraudOutcomeHold:
+		result.Error = errFraudReviewRequired.Error()
+	default:
+		result.ToAccountID = toAccount.ID
+	}
+
+	return result, nil
+}
+
+type batchTransferParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getBatchTransfer returns a batch with the result of each row, for the
+// user who submitted it or anyone who can view the source account.
+func (server *Server) getBatchTransfer(ctx *gin.Context) {
+	var params batchTransferParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	batch, err := server.store.GetTransferBatch(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	if batch.Username != authPayload.Username {
+		if _, valid := server.getAuthorizedAccount(ctx, batch.FromAccountID, db.PermissionView); !valid {
+			return
+		}
+	}
+
+	items, err := server.store.ListTransferBatchItems(ctx, batch.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, db.BatchTransferTxResult{Batch: batch, Items: items})
+}
@@ -0,0 +1,450 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/fraud"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func TestCreateBatchTransferAPI(t *testing.T) {
+	user1, _, _ := randomUser(t)
+	user2, _, _ := randomUser(t)
+	fromAccount := randomAccount(user1.Username)
+	payee1 := randomAccount(user2.Username)
+	payee2 := randomAccount(user2.Username)
+	fromAccount.ID, payee1.ID, payee2.ID = 1, 2, 3
+	fromAccount.Currency = utils.USD
+	payee1.Currency = utils.USD
+	payee2.Currency = utils.USD
+
+	jsonRows := []gin.H{
+		{"to_account": payee1.AccountNumber, "amount": 100, "currency": utils.USD, "memo": "march salary"},
+		{"to_account": payee2.AccountNumber, "amount": 200, "currency": utils.USD},
+	}
+	validRows := []db.BatchTransferRow{
+		{RowNumber: 1, ToAccount: payee1.AccountNumber, ToAccountID: payee1.ID, Amount: 100, Currency: utils.USD, Memo: "march salary"},
+		{RowNumber: 2, ToAccount: payee2.AccountNumber, ToAccountID: payee2.ID, Amount: 200, Currency: utils.USD},
+	}
+	result := db.BatchTransferTxResult{
+		Batch: db.TransferBatch{ID: 7, Status: db.TransferBatchStatusCompleted, Succeeded: 2},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user1.Username,
+			body:     gin.H{"from_account_id": fromAccount.ID, "rows": jsonRows},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(payee1.AccountNumber)).Times(1).Return(payee1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(payee2.AccountNumber)).Times(1).Return(payee2, nil)
+				arg := db.BatchTransferTxParams{
+					Username:      user1.Username,
+					FromAccountID: fromAccount.ID,
+					Rows:          validRows,
+				}
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.BatchTransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, result.Batch.ID, got.Batch.ID)
+			},
+		},
+		{
+			name:     "InvalidRowRejectsBatch",
+			username: user1.Username,
+			body: gin.H{"from_account_id": fromAccount.ID, "rows": []gin.H{
+				jsonRows[0],
+				{"to_account": "SB00000000000000", "amount": 200, "currency": utils.USD},
+			}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(payee1.AccountNumber)).Times(1).Return(payee1, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var got struct {
+					Rows []db.BatchTransferRow `json:"rows"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Len(t, got.Rows, 2)
+				require.Empty(t, got.Rows[0].Error)
+				require.Equal(t, "invalid account number", got.Rows[1].Error)
+			},
+		},
+		{
+			name:     "PartialKeepsInvalidRows",
+			username: user1.Username,
+			body: gin.H{"from_account_id": fromAccount.ID, "allow_partial": true, "rows": []gin.H{
+				jsonRows[0],
+				{"to_account": payee2.AccountNumber, "amount": 200, "currency": utils.EUR},
+			}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(payee1.AccountNumber)).Times(1).Return(payee1, nil)
+				arg := db.BatchTransferTxParams{
+					Username:      user1.Username,
+					FromAccountID: fromAccount.ID,
+					AllowPartial:  true,
+					Rows: []db.BatchTransferRow{
+						validRows[0],
+						{RowNumber: 2, ToAccount: payee2.AccountNumber, Amount: 200, Currency: utils.EUR, Error: "currency must match the source account"},
+					},
+				}
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "RowToCoolingOffPayee",
+			username: user1.Username,
+			body: gin.H{"from_account_id": fromAccount.ID, "allow_partial": true, "rows": []gin.H{
+				jsonRows[0],
+				{"to_account": payee2.AccountNumber, "amount": 1_000, "currency": utils.USD},
+			}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(payee1.AccountNumber)).Times(1).Return(payee1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(payee2.AccountNumber)).Times(1).Return(payee2, nil)
+				coolingOff := db.Payee{
+					Username:        user1.Username,
+					AccountID:       payee2.ID,
+					CoolingOffUntil: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+				}
+				store.EXPECT().GetPayeeByAccount(gomock.Any(), gomock.Eq(db.GetPayeeByAccountParams{
+					Username:  user1.Username,
+					AccountID: payee2.ID,
+				})).Times(1).Return(coolingOff, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.BatchTransferTxParams) (db.BatchTransferTxResult, error) {
+						require.Equal(t, validRows[0], arg.Rows[0])
+						require.Zero(t, arg.Rows[1].ToAccountID)
+						require.Contains(t, arg.Rows[1].Error, errPayeeCoolingOff.Error())
+						return result, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "RowAboveApprovalThreshold",
+			username: user1.Username,
+			body:     gin.H{"from_account_id": fromAccount.ID, "allow_partial": true, "rows": jsonRows},
+			buildStubs: func(store *mockdb.MockStore) {
+				guarded := fromAccount
+				guarded.ApprovalThreshold = 150
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(guarded, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(payee1.AccountNumber)).Times(1).Return(payee1, nil)
+				arg := db.BatchTransferTxParams{
+					Username:      user1.Username,
+					FromAccountID: fromAccount.ID,
+					AllowPartial:  true,
+					Rows: []db.BatchTransferRow{
+						validRows[0],
+						{RowNumber: 2, ToAccount: payee2.AccountNumber, Amount: 200, Currency: utils.USD, Error: db.ErrApprovalRequired.Error()},
+					},
+				}
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "UnknownPayee",
+			username: user1.Username,
+			body:     gin.H{"from_account_id": fromAccount.ID, "rows": jsonRows[:1]},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "account not found")
+			},
+		},
+		{
+			name:     "NoAuthorized",
+			username: user2.Username,
+			body:     gin.H{"from_account_id": fromAccount.ID, "rows": jsonRows},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "InsufficientFunds",
+			username: user1.Username,
+			body:     gin.H{"from_account_id": fromAccount.ID, "rows": jsonRows},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(payee1.AccountNumber)).Times(1).Return(payee1, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(payee2.AccountNumber)).Times(1).Return(payee2, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.BatchTransferTxResult{}, fmt.Errorf("row 2: %w", db.ErrInsufficientFunds))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "NoRows",
+			username: user1.Username,
+			body:     gin.H{"from_account_id": fromAccount.ID, "rows": []gin.H{}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, "/transfers/batch", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateBatchTransferFraudScreening(t *testing.T) {
+	user1, _, _ := randomUser(t)
+	user2, _, _ := randomUser(t)
+	fromAccount := randomAccount(user1.Username)
+	payee1 := randomAccount(user2.Username)
+	payee2 := randomAccount(user2.Username)
+	fromAccount.ID, payee1.ID, payee2.ID = 1, 2, 3
+	fromAccount.Currency = utils.USD
+	payee1.Currency = utils.USD
+	payee2.Currency = utils.USD
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(payee1.AccountNumber)).Times(1).Return(payee1, nil)
+	store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(payee2.AccountNumber)).Times(1).Return(payee2, nil)
+	// only the second row is above the rule's limit
+	store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+	store.EXPECT().RecordFraudDecisionTx(gomock.Any(), gomock.Any()).Times(2)
+	arg := db.BatchTransferTxParams{
+		Username:      user1.Username,
+		FromAccountID: fromAccount.ID,
+		AllowPartial:  true,
+		Rows: []db.BatchTransferRow{
+			{RowNumber: 1, ToAccount: payee1.AccountNumber, ToAccountID: payee1.ID, Amount: 100, Currency: utils.USD},
+			{RowNumber: 2, ToAccount: payee2.AccountNumber, Amount: 200, Currency: utils.USD, Error: errFraudReviewRequired.Error()},
+		},
+	}
+	store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+
+	server := createNewServer(t, store)
+	var err error
+	server.fraud, err = fraud.NewEngine([]fraud.Rule{
+		{Name: "first", Kind: fraud.KindNewRecipient, Limit: 150, Action: db.FraudOutcomeHold},
+	}, store)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"from_account_id": fromAccount.ID, "allow_partial": true, "rows": []gin.H{
+		{"to_account": payee1.AccountNumber, "amount": 100, "currency": utils.USD},
+		{"to_account": payee2.AccountNumber, "amount": 200, "currency": utils.USD},
+	}})
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "/transfers/batch", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthHeader(t, req, server.tokenGenerator, authorizationType, user1.Username, time.Minute)
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestCreateBatchTransferCSVAPI(t *testing.T) {
+	user1, _, _ := randomUser(t)
+	user2, _, _ := randomUser(t)
+	fromAccount := randomAccount(user1.Username)
+	payee := randomAccount(user2.Username)
+	fromAccount.ID, payee.ID = 1, 2
+	fromAccount.Currency = utils.USD
+	payee.Currency = utils.USD
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(payee.AccountNumber)).Times(1).Return(payee, nil)
+	arg := db.BatchTransferTxParams{
+		Username:      user1.Username,
+		FromAccountID: fromAccount.ID,
+		AllowPartial:  true,
+		Rows: []db.BatchTransferRow{
+			{RowNumber: 1, ToAccount: payee.AccountNumber, ToAccountID: payee.ID, Amount: 150, Currency: utils.USD, Memo: "bonus, Q1"},
+			{RowNumber: 2, ToAccount: payee.AccountNumber, Amount: 0, Currency: utils.USD, Error: "invalid amount"},
+		},
+	}
+	store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.BatchTransferTxResult{}, nil)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("from_account_id", fmt.Sprint(fromAccount.ID)))
+	require.NoError(t, writer.WriteField("allow_partial", "true"))
+	file, err := writer.CreateFormFile(batchFileField, "payroll.csv")
+	require.NoError(t, err)
+	fmt.Fprintf(file, "to_account,amount,currency,memo\n%s,150,usd,\"bonus, Q1\"\n%s,lots,USD,\n", payee.AccountNumber, payee.AccountNumber)
+	require.NoError(t, writer.Close())
+
+	server := createNewServer(t, store)
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/transfers/batch", body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	addAuthHeader(t, req, server.tokenGenerator, authorizationType, user1.Username, time.Minute)
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestParseBatchCSV(t *testing.T) {
+	rows, err := parseBatchCSV(strings.NewReader("Currency, Amount, To_Account\nEUR, 5, SB21000123456789\n"))
+	require.NoError(t, err)
+	require.Equal(t, []batchTransferRow{{ToAccount: "SB21000123456789", Amount: 5, Currency: utils.EUR}}, rows)
+
+	_, err = parseBatchCSV(strings.NewReader("to_account,amount\nSB21000123456789,5\n"))
+	require.ErrorContains(t, err, "currency")
+
+	_, err = parseBatchCSV(strings.NewReader("to_account,amount,currency\nSB21000123456789,5\n"))
+	require.Error(t, err)
+}
+
+func TestGetBatchTransferAPI(t *testing.T) {
+	user1, _, _ := randomUser(t)
+	user2, _, _ := randomUser(t)
+	account := randomAccount(user1.Username)
+	batch := db.TransferBatch{ID: 7, Username: user1.Username, FromAccountID: account.ID, Status: db.TransferBatchStatusPartial}
+	items := []db.TransferBatchItem{
+		{ID: 1, BatchID: batch.ID, RowNumber: 1, Status: db.TransferBatchItemStatusSucceeded},
+		{ID: 2, BatchID: batch.ID, RowNumber: 2, Status: db.TransferBatchItemStatusFailed, Error: db.ErrInsufficientFunds.Error()},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(batch, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(items, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.BatchTransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, batch, got.Batch)
+				require.Equal(t, items, got.Items)
+			},
+		},
+		{
+			name:     "NoAuthorized",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(batch, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(db.TransferBatch{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfers/batch/%d", batch.ID)
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func TestListCurrenciesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := createNewServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/currencies", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var currencies []utils.Currency
+	err = json.Unmarshal(recorder.Body.Bytes(), &currencies)
+	require.NoError(t, err)
+	require.Len(t, currencies, 3)
+	require.Equal(t, utils.CAD, currencies[0].Code)
+	require.Equal(t, 2, currencies[0].MinorUnits)
+	require.Equal(t, utils.EUR, currencies[1].Code)
+	require.Equal(t, utils.USD, currencies[2].Code)
+}
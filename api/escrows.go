@@ -0,0 +1,250 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/thanhphuocnguyen/go-simple-bank/auth"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+var (
+	errSelfEscrow     = errors.New("buyer and seller accounts must differ")
+	errEscrowTimeout  = errors.New("timeout_at must be in the future and within the maximum escrow timeout")
+	errNotEscrowParty = errors.New("escrow can only be settled by its counterparty or a banker")
+)
+
+// createEscrowRequest moves Amount from the buyer's account into escrow for
+// the seller. Unless it is released or refunded first, the escrow is
+// settled by TimeoutAction at TimeoutAt, which defaults to EscrowTimeout
+// from now and may not be later than that; TimeoutAction defaults to a
+// refund.
+type createEscrowRequest struct {
+	BuyerAccountID  int64     `json:"buyer_account_id" binding:"required,min=1"`
+	SellerAccountID int64     `json:"seller_account_id" binding:"required,min=1"`
+	Amount          int64     `json:"amount" binding:"required,gt=0"`
+	Currency        string    `json:"currency" binding:"required,currency"`
+	Description     string    `json:"description" binding:"max=140"`
+	TimeoutAt       time.Time `json:"timeout_at"`
+	TimeoutAction   string    `json:"timeout_action" binding:"omitempty,oneof=release refund"`
+}
+
+func (server *Server) createEscrow(ctx *gin.Context) {
+	var req createEscrowRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.BuyerAccountID == req.SellerAccountID {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errSelfEscrow))
+		return
+	}
+
+	now := time.Now()
+	timeoutAt := req.TimeoutAt
+	if timeoutAt.IsZero() {
+		timeoutAt = now.Add(server.config.EscrowTimeout)
+	}
+	if !timeoutAt.After(now) || timeoutAt.After(now.Add(server.config.EscrowTimeout)) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errEscrowTimeout))
+		return
+	}
+
+	timeoutAction := req.TimeoutAction
+	if timeoutAction == "" {
+		timeoutAction = db.EscrowActionRefund
+	}
+
+	buyer, valid := server.validAccount(ctx, req.BuyerAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, buyer, db.PermissionTransact) {
+		return
+	}
+
+	seller, valid := server.validAccount(ctx, req.SellerAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	// the buyer can release at once, so the seller is checked as a payee now
+	if !server.checkCoolingOff(ctx, seller, req.Amount) {
+		return
+	}
+
+	if !server.screenLaterTransfer(ctx, buyer, seller, req.Amount) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	result, err := server.store.CreateEscrowTx(ctx, db.CreateEscrowTxParams{
+		BuyerAccountID:  buyer.ID,
+		SellerAccountID: seller.ID,
+		Amount:          req.Amount,
+		Description:     req.Description,
+		CreatedBy:       authPayload.Username,
+		TimeoutAt:       pgtype.Timestamptz{Time: timeoutAt, Valid: true},
+		TimeoutAction:   timeoutAction,
+	})
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type listEscrowsRequest struct {
+	AccountID int64 `form:"account_id" binding:"required,min=1"`
+	Page      int32 `form:"page" binding:"required,min=1"`
+	PageSize  int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listEscrows lists the escrows an account is the buyer or seller in.
+func (server *Server) listEscrows(ctx *gin.Context) {
+	var req listEscrowsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.getAuthorizedAccount(ctx, req.AccountID, db.PermissionView); !valid {
+		return
+	}
+
+	escrows, err := server.store.ListEscrows(ctx, db.ListEscrowsParams{
+		AccountID: req.AccountID,
+		Limit:     req.PageSize,
+		Offset:    (req.Page - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, escrows)
+}
+
+type escrowParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getEscrow shows an escrow to anyone who can view either party's account,
+// and to bankers.
+func (server *Server) getEscrow(ctx *gin.Context) {
+	var params escrowParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	escrow, valid := server.validEscrow(ctx, params.ID)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeEscrowParty(ctx, db.PermissionView, escrow.BuyerAccountID, escrow.SellerAccountID) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, escrow)
+}
+
+// releaseEscrow pays a held escrow out to the seller. The buyer releases it
+// once delivery is confirmed; a banker may release it on their behalf.
+func (server *Server) releaseEscrow(ctx *gin.Context) {
+	server.settleEscrow(ctx, db.EscrowActionRelease)
+}
+
+// refundEscrow returns a held escrow to the buyer. The seller refunds it
+// when the order falls through; a banker may refund it on their behalf.
+func (server *Server) refundEscrow(ctx *gin.Context) {
+	server.settleEscrow(ctx, db.EscrowActionRefund)
+}
+
+// settleEscrow applies action on behalf of the party who gives the money
+// up: the buyer for a release and the seller for a refund.
+func (server *Server) settleEscrow(ctx *gin.Context, action string) {
+	var params escrowParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	escrow, valid := server.validEscrow(ctx, params.ID)
+	if !valid {
+		return
+	}
+
+	party := escrow.BuyerAccountID
+	if action == db.EscrowActionRefund {
+		party = escrow.SellerAccountID
+	}
+
+	if !server.authorizeEscrowParty(ctx, db.PermissionTransact, party) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	result, err := server.store.SettleEscrowTx(ctx, db.SettleEscrowTxParams{
+		EscrowID:  escrow.ID,
+		Action:    action,
+		SettledBy: authPayload.Username,
+	})
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) validEscrow(ctx *gin.Context, id int64) (db.Escrow, bool) {
+	escrow, err := server.store.GetEscrow(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return escrow, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return escrow, false
+	}
+
+	return escrow, true
+}
+
+// authorizeEscrowParty lets through users holding permission on any of
+// accountIDs, and bankers, who act as the arbiter of every escrow.
+func (server *Server) authorizeEscrowParty(ctx *gin.Context, permission string, accountIDs ...int64) bool {
+	for _, accountID := range accountIDs {
+		allowed, err := server.hasPermission(ctx, accountID, permission)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false
+		}
+		if allowed {
+			return true
+		}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if user.Role != db.UserRoleBanker {
+		ctx.JSON(http.StatusForbidden, errorResponse(errNotEscrowParty))
+		return false
+	}
+
+	return true
+}
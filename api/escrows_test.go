@@ -0,0 +1,357 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/fraud"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func TestCreateEscrowAPI(t *testing.T) {
+	buyer, _, _ := randomUser(t)
+	seller, _, _ := randomUser(t)
+	buyerAccount := randomAccount(buyer.Username)
+	buyerAccount.ID = 1
+	buyerAccount.Currency = utils.USD
+	sellerAccount := randomAccount(seller.Username)
+	sellerAccount.ID = 2
+	sellerAccount.Currency = utils.USD
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"buyer_account_id": buyerAccount.ID, "seller_account_id": sellerAccount.ID, "amount": 100, "currency": utils.USD, "description": "bike"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(sellerAccount, nil)
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateEscrowTxParams) (db.EscrowTxResult, error) {
+						require.Equal(t, buyerAccount.ID, arg.BuyerAccountID)
+						require.Equal(t, sellerAccount.ID, arg.SellerAccountID)
+						require.Equal(t, int64(100), arg.Amount)
+						require.Equal(t, "bike", arg.Description)
+						require.Equal(t, buyer.Username, arg.CreatedBy)
+						require.Equal(t, db.EscrowActionRefund, arg.TimeoutAction)
+						require.WithinDuration(t, time.Now().Add(30*24*time.Hour), arg.TimeoutAt.Time, time.Minute)
+						return db.EscrowTxResult{Escrow: db.Escrow{ID: 1, Status: db.EscrowStatusHeld}}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ReleaseOnTimeout",
+			body: gin.H{"buyer_account_id": buyerAccount.ID, "seller_account_id": sellerAccount.ID, "amount": 100, "currency": utils.USD, "timeout_at": time.Now().Add(time.Hour), "timeout_action": "release"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(sellerAccount, nil)
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateEscrowTxParams) (db.EscrowTxResult, error) {
+						require.Equal(t, db.EscrowActionRelease, arg.TimeoutAction)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.TimeoutAt.Time, time.Minute)
+						return db.EscrowTxResult{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidTimeoutAction",
+			body: gin.H{"buyer_account_id": buyerAccount.ID, "seller_account_id": sellerAccount.ID, "amount": 100, "currency": utils.USD, "timeout_action": "keep"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TimeoutTooFar",
+			body: gin.H{"buyer_account_id": buyerAccount.ID, "seller_account_id": sellerAccount.ID, "amount": 100, "currency": utils.USD, "timeout_at": time.Now().Add(60 * 24 * time.Hour)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "SameAccount",
+			body: gin.H{"buyer_account_id": buyerAccount.ID, "seller_account_id": buyerAccount.ID, "amount": 100, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "SellerCurrencyMismatch",
+			body: gin.H{"buyer_account_id": buyerAccount.ID, "seller_account_id": sellerAccount.ID, "amount": 100, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				eurAccount := sellerAccount
+				eurAccount.Currency = utils.EUR
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(eurAccount, nil)
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "SellerCoolingOff",
+			body: gin.H{"buyer_account_id": buyerAccount.ID, "seller_account_id": sellerAccount.ID, "amount": 1_000, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(sellerAccount, nil)
+				coolingOff := db.Payee{
+					Username:        buyer.Username,
+					AccountID:       sellerAccount.ID,
+					CoolingOffUntil: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+				}
+				store.EXPECT().GetPayeeByAccount(gomock.Any(), gomock.Eq(db.GetPayeeByAccountParams{
+					Username:  buyer.Username,
+					AccountID: sellerAccount.ID,
+				})).Times(1).Return(coolingOff, nil)
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "NoEscrowAccount",
+			body: gin.H{"buyer_account_id": buyerAccount.ID, "seller_account_id": sellerAccount.ID, "amount": 100, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(sellerAccount, nil)
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.EscrowTxResult{}, db.ErrNoEscrowAccount)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/escrows", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, buyer.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateEscrowFraudScreening(t *testing.T) {
+	buyer, _, _ := randomUser(t)
+	seller, _, _ := randomUser(t)
+	buyerAccount := randomAccount(buyer.Username)
+	buyerAccount.ID = 1
+	buyerAccount.Currency = utils.USD
+	sellerAccount := randomAccount(seller.Username)
+	sellerAccount.ID = 2
+	sellerAccount.Currency = utils.USD
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(sellerAccount, nil)
+	store.EXPECT().GetTransferVelocity(gomock.Any(), gomock.Any()).Times(1).Return(db.GetTransferVelocityRow{Count: 3}, nil)
+	arg := db.RecordFraudDecisionTxParams{
+		Decision: db.CreateFraudDecisionParams{
+			Username:      buyer.Username,
+			FromAccountID: buyerAccount.ID,
+			ToAccountID:   sellerAccount.ID,
+			Amount:        100,
+			Outcome:       db.FraudOutcomeBlock,
+			Rule:          "hourly",
+			Reason:        "4 transfers within 1h0m0s, limit 3",
+		},
+	}
+	store.EXPECT().RecordFraudDecisionTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+	store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := createNewServer(t, store)
+	var err error
+	server.fraud, err = fraud.NewEngine([]fraud.Rule{
+		{Name: "hourly", Kind: fraud.KindTransferCount, Window: "1h", Limit: 3, Action: db.FraudOutcomeBlock},
+	}, store)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"buyer_account_id": buyerAccount.ID, "seller_account_id": sellerAccount.ID, "amount": 100, "currency": utils.USD})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "/escrows", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthHeader(t, req, server.tokenGenerator, authorizationType, buyer.Username, time.Minute)
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestSettleEscrowAPI(t *testing.T) {
+	buyer, _, _ := randomUser(t)
+	seller, _, _ := randomUser(t)
+	banker, _, _ := randomUser(t)
+	banker.Role = db.UserRoleBanker
+	stranger, _, _ := randomUser(t)
+	stranger.Role = db.UserRoleDepositor
+	buyerAccount := randomAccount(buyer.Username)
+	buyerAccount.ID = 1
+	sellerAccount := randomAccount(seller.Username)
+	sellerAccount.ID = 2
+	escrow := db.Escrow{
+		ID:              7,
+		BuyerAccountID:  buyerAccount.ID,
+		SellerAccountID: sellerAccount.ID,
+		Amount:          100,
+		Status:          db.EscrowStatusHeld,
+		TimeoutAt:       pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		TimeoutAction:   db.EscrowActionRefund,
+	}
+
+	testCases := []struct {
+		name       string
+		action     string
+		username   string
+		buildStubs func(store *mockdb.MockStore)
+		wantStatus int
+	}{
+		{
+			name:     "BuyerReleases",
+			action:   "release",
+			username: buyer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+				store.EXPECT().SettleEscrowTx(gomock.Any(), gomock.Eq(db.SettleEscrowTxParams{
+					EscrowID:  escrow.ID,
+					Action:    db.EscrowActionRelease,
+					SettledBy: buyer.Username,
+				})).Times(1).Return(db.EscrowTxResult{Escrow: db.Escrow{ID: escrow.ID, Status: db.EscrowStatusReleased}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:     "SellerCannotRelease",
+			action:   "release",
+			username: seller.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(seller.Username)).Times(1).Return(seller, nil)
+				store.EXPECT().SettleEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:     "SellerRefunds",
+			action:   "refund",
+			username: seller.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(sellerAccount, nil)
+				store.EXPECT().SettleEscrowTx(gomock.Any(), gomock.Eq(db.SettleEscrowTxParams{
+					EscrowID:  escrow.ID,
+					Action:    db.EscrowActionRefund,
+					SettledBy: seller.Username,
+				})).Times(1).Return(db.EscrowTxResult{Escrow: db.Escrow{ID: escrow.ID, Status: db.EscrowStatusRefunded}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:     "BankerRefunds",
+			action:   "refund",
+			username: banker.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(sellerAccount, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+				store.EXPECT().SettleEscrowTx(gomock.Any(), gomock.Any()).Times(1).Return(db.EscrowTxResult{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:     "StrangerCannotRefund",
+			action:   "refund",
+			username: stranger.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sellerAccount.ID)).Times(1).Return(sellerAccount, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(stranger.Username)).Times(1).Return(stranger, nil)
+				store.EXPECT().SettleEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:     "AlreadySettled",
+			action:   "release",
+			username: buyer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(buyerAccount.ID)).Times(1).Return(buyerAccount, nil)
+				store.EXPECT().SettleEscrowTx(gomock.Any(), gomock.Any()).Times(1).Return(db.EscrowTxResult{}, db.ErrEscrowNotHeld)
+			},
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetEscrow(gomock.Any(), gomock.Eq(escrow.ID)).Times(1).Return(escrow, nil)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/escrows/%d/%s", escrow.ID, tc.action)
+			req, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			require.Equal(t, tc.wantStatus, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/fx"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+// exchange prices the conversion of amount from one currency to another
+// at the current rate less the configured spread.
+func (server *Server) exchange(ctx *gin.Context, amount int64, from, to string) (db.Exchange, bool) {
+	rate, err := server.rates.Rate(ctx, from, to)
+	if err != nil {
+		if errors.Is(err, fx.ErrRateNotFound) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return db.Exchange{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Exchange{}, false
+	}
+
+	fromCurrency, _ := utils.LookupCurrency(from)
+	toCurrency, _ := utils.LookupCurrency(to)
+	toAmount, err := fx.Convert(amount, rate, server.config.FXSpreadBps, fromCurrency, toCurrency)
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return db.Exchange{}, false
+	}
+
+	return db.Exchange{
+		Rate:      rate,
+		SpreadBps: server.config.FXSpreadBps,
+		ToAmount:  toAmount,
+	}, true
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+type quoteTransferRequest struct {
+	Amount   int64  `form:"amount" binding:"required,gt=0"`
+	Currency string `form:"currency" binding:"required,currency"`
+}
+
+// quoteTransferResponse previews what sending Amount costs. Total is what
+// leaves the sender's account.
+type quoteTransferResponse struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Fee      int64  `json:"fee"`
+	Total    int64  `json:"total"`
+}
+
+// quoteTransfer applies the same fee schedule as TransferTx, so a transfer
+// sent right after the quote is charged the quoted fee unless the schedule
+// changes in between.
+func (server *Server) quoteTransfer(ctx *gin.Context) {
+	var req quoteTransferRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var fee int64
+	schedule, err := server.store.GetFeeSchedule(ctx, db.GetFeeScheduleParams{
+		Currency: req.Currency,
+		Amount:   req.Amount,
+	})
+	switch {
+	case err == nil:
+		fee = schedule.FeeFor(req.Amount)
+	case !errors.Is(err, sql.ErrNoRows):
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quoteTransferResponse{
+		Amount:   req.Amount,
+		Currency: req.Currency,
+		Fee:      fee,
+		Total:    req.Amount + fee,
+	})
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhphuocnguyen/go-simple-bank/auth"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/fraud"
+)
+
+var (
+	errTransferBlocked = errors.New("transfer was blocked by a fraud check")
+	errFraudReview     = errors.New("transfer is held for fraud review and is decided by a banker")
+	errNotFraudReview  = errors.New("transfer approval is not a fraud review")
+
+	errFraudReviewRequired = errors.New("transfer needs a fraud review and can only be made as a single transfer")
+)
+
+// screenTransfer runs the fraud rules on a transfer before it executes and
+// records the decision. A blocked transfer is refused without naming the
+// rule, and a held one is parked for a banker to review with the idempotency
+// key stored alongside; in both cases the response is written and false
+// returned.
+func (server *Server) screenTransfer(ctx *gin.Context, req transferRequest, fromAccount, toAccount db.Account, idempotency *db.IdempotencyKeyParams) bool {
+	if server.fraud == nil {
+		return true
+	}
+
+	decision, err := server.fraud.Evaluate(ctx, fraud.Transfer{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        req.Amount,
+		Currency:      fromAccount.Currency,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	arg := db.RecordFraudDecisionTxParams{
+		Decision:    fraudDecisionParams(authPayload.Username, fromAccount, toAccount, req.Amount, decision),
+		Idempotency: idempotency,
+	}
+	if decision.Outcome == db.FraudOutcomeHold {
+		arg.Hold = &db.CreateTransferApprovalParams{
+			FromAccountID:     fromAccount.ID,
+			ToAccountID:       toAccount.ID,
+			Amount:            req.Amount,
+			Description:       req.Description,
+			ExternalReference: req.ExternalReference,
+			Metadata:          req.Metadata,
+			Initiator:         authPayload.Username,
+			Reason:            db.ApprovalReasonFraudReview,
+			PaymentRequestID:  req.paymentRequest(),
+		}
+	}
+
+	result, err := server.store.RecordFraudDecisionTx(ctx, arg)
+	if err != nil {
+		server.transferErrorResponse(ctx, idempotency, err)
+		return false
+	}
+
+	switch decision.Outcome {
+	case db.FraudOutcomeBlock:
+		ctx.JSON(http.StatusForbidden, errorResponse(errTransferBlocked))
+		return false
+	case db.FraudOutcomeHold:
+		ctx.Header("Location", fmt.Sprintf("/transfer-approvals/%d", result.Approval.ID))
+		ctx.JSON(http.StatusAccepted, result.Approval)
+		return false
+	}
+	return true
+}
+
+// screenLaterTransfer screens a transfer that cannot be parked for a review:
+// one set up now to pay out later, such as a scheduled transfer, a standing
+// order or an escrow, or a hold capture. A hold is refused like a block, but with a
+// message telling the user to make a single transfer instead.
+func (server *Server) screenLaterTransfer(ctx *gin.Context, fromAccount, toAccount db.Account, amount int64) bool {
+	outcome, err := server.checkFraud(ctx, fromAccount, toAccount, amount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	switch outcome {
+	case db.FraudOutcomeBlock:
+		ctx.JSON(http.StatusForbidden, errorResponse(errTransferBlocked))
+		return false
+	case db.FraudOutcomeHold:
+		ctx.JSON(http.StatusForbidden, errorResponse(errFraudReviewRequired))
+		return false
+	}
+	return true
+}
+
+// checkFraud runs the fraud rules on a transfer that cannot be parked for
+// review and records the decision, returning its outcome for the caller to
+// act on.
+func (server *Server) checkFraud(ctx *gin.Context, fromAccount, toAccount db.Account, amount int64) (string, error) {
+	if server.fraud == nil {
+		return db.FraudOutcomeAllow, nil
+	}
+
+	decision, err := server.fraud.Evaluate(ctx, fraud.Transfer{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		Currency:      fromAccount.Currency,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	_, err = server.store.RecordFraudDecisionTx(ctx, db.RecordFraudDecisionTxParams{
+		Decision: fraudDecisionParams(authPayload.Username, fromAccount, toAccount, amount, decision),
+	})
+	if err != nil {
+		return "", err
+	}
+	return decision.Outcome, nil
+}
+
+func fraudDecisionParams(username string, fromAccount, toAccount db.Account, amount int64, decision fraud.Decision) db.CreateFraudDecisionParams {
+	return db.CreateFraudDecisionParams{
+		Username:      username,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		Outcome:       decision.Outcome,
+		Rule:          decision.Rule,
+		Reason:        decision.Reason,
+	}
+}
+
+type listFraudDecisionsRequest struct {
+	AccountID int64 `form:"account_id" binding:"required,min=1"`
+	Page      int32 `form:"page" binding:"required,min=1"`
+	PageSize  int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listFraudDecisions shows bankers every screening decision on an account's
+// outgoing transfers, newest first.
+func (server *Server) listFraudDecisions(ctx *gin.Context) {
+	var req listFraudDecisionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	decisions, err := server.store.ListFraudDecisions(ctx, db.ListFraudDecisionsParams{
+		FromAccountID: req.AccountID,
+		Limit:         req.PageSize,
+		Offset:        (req.Page - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, decisions)
+}
+
+// approveFraudReview lets a banker release a transfer held by a fraud rule.
+func (server *Server) approveFraudReview(ctx *gin.Context) {
+	var params transferApprovalParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	approval, valid := server.validFraudReview(ctx, params.ID)
+	if !valid {
+		return
+	}
+
+	server.executeApproval(ctx, approval)
+}
+
+// rejectFraudReview lets a banker refuse a transfer held by a fraud rule.
+func (server *Server) rejectFraudReview(ctx *gin.Context) {
+	var params transferApprovalParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req rejectTransferRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	approval, valid := server.validFraudReview(ctx, params.ID)
+	if !valid {
+		return
+	}
+
+	server.declineApproval(ctx, approval, req.Note)
+}
+
+func (server *Server) validFraudReview(ctx *gin.Context, id int64) (db.TransferApproval, bool) {
+	approval, err := server.store.GetTransferApproval(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return approval, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return approval, false
+	}
+
+	if approval.Reason != db.ApprovalReasonFraudReview {
+		ctx.JSON(http.StatusNotFound, errorResponse(errNotFraudReview))
+		return approval, false
+	}
+
+	return approval, true
+}
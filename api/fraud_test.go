@@ -0,0 +1,372 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/fraud"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func TestCreateTransferFraudScreening(t *testing.T) {
+	owner, _, _ := randomUser(t)
+	recipient, _, _ := randomUser(t)
+	fromAccount := randomAccount(owner.Username)
+	fromAccount.ID = 1
+	fromAccount.Currency = utils.USD
+	toAccount := randomAccount(recipient.Username)
+	toAccount.ID = 2
+	toAccount.Currency = utils.USD
+
+	rules := []fraud.Rule{
+		{Name: "hourly", Kind: fraud.KindTransferCount, Window: "1h", Limit: 3, Action: db.FraudOutcomeBlock},
+		{Name: "first", Kind: fraud.KindNewRecipient, Limit: 500, Action: db.FraudOutcomeHold},
+	}
+
+	decision := func(outcome, rule, reason string) db.CreateFraudDecisionParams {
+		return db.CreateFraudDecisionParams{
+			Username:      owner.Username,
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        1_000,
+			Outcome:       outcome,
+			Rule:          rule,
+			Reason:        reason,
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Allow",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferVelocity(gomock.Any(), gomock.Any()).Times(1).Return(db.GetTransferVelocityRow{Count: 1}, nil)
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(1).Return(int64(2), nil)
+				arg := db.RecordFraudDecisionTxParams{Decision: decision(db.FraudOutcomeAllow, "", "")}
+				store.EXPECT().RecordFraudDecisionTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Block",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferVelocity(gomock.Any(), gomock.Any()).Times(1).Return(db.GetTransferVelocityRow{Count: 3}, nil)
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(0)
+				arg := db.RecordFraudDecisionTxParams{Decision: decision(db.FraudOutcomeBlock, "hourly", "4 transfers within 1h0m0s, limit 3")}
+				store.EXPECT().RecordFraudDecisionTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+
+				// the response does not tell the sender which rule fired
+				require.NotContains(t, recorder.Body.String(), "hourly")
+			},
+		},
+		{
+			name: "Hold",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferVelocity(gomock.Any(), gomock.Any()).Times(1).Return(db.GetTransferVelocityRow{}, nil)
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				arg := db.RecordFraudDecisionTxParams{
+					Decision: decision(db.FraudOutcomeHold, "first", "first transfer to this recipient is 1000, limit 500"),
+					Hold: &db.CreateTransferApprovalParams{
+						FromAccountID: fromAccount.ID,
+						ToAccountID:   toAccount.ID,
+						Amount:        1_000,
+						Initiator:     owner.Username,
+						Reason:        db.ApprovalReasonFraudReview,
+					},
+				}
+				store.EXPECT().RecordFraudDecisionTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.RecordFraudDecisionTxResult{Approval: db.TransferApproval{ID: 9, Reason: db.ApprovalReasonFraudReview}}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Equal(t, "/transfer-approvals/9", recorder.Header().Get("Location"))
+
+				var got db.TransferApproval
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, int64(9), got.ID)
+				require.Equal(t, db.ApprovalReasonFraudReview, got.Reason)
+			},
+		},
+		{
+			name: "EvaluateError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferVelocity(gomock.Any(), gomock.Any()).Times(1).Return(db.GetTransferVelocityRow{}, sql.ErrConnDone)
+				store.EXPECT().RecordFraudDecisionTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "RecordError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferVelocity(gomock.Any(), gomock.Any()).Times(1).Return(db.GetTransferVelocityRow{}, nil)
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().RecordFraudDecisionTx(gomock.Any(), gomock.Any()).Times(1).Return(db.RecordFraudDecisionTxResult{}, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			expectNoPayee(store)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			var err error
+			server.fraud, err = fraud.NewEngine(rules, store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          1_000,
+				"currency":        utils.USD,
+			})
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, owner.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestFraudReviewAPI(t *testing.T) {
+	banker, _, _ := randomUser(t)
+	banker.Role = db.UserRoleBanker
+	depositor, _, _ := randomUser(t)
+	depositor.Role = db.UserRoleDepositor
+	fromAccount := randomAccount(depositor.Username)
+	fromAccount.ID = 1
+	fromAccount.Currency = utils.USD
+	toAccount := randomAccount(depositor.Username)
+	toAccount.ID = 2
+	toAccount.Currency = utils.USD
+
+	review := db.TransferApproval{
+		ID:            5,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        1_000,
+		Initiator:     depositor.Username,
+		Status:        db.TransferApprovalStatusPending,
+		Reason:        db.ApprovalReasonFraudReview,
+	}
+	threshold := review
+	threshold.Reason = db.ApprovalReasonThreshold
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		action        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Approve",
+			user:   banker,
+			action: "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+				store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(review.ID)).Times(1).Return(review, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				arg := db.ApproveTransferTxParams{ApprovalID: review.ID, Approver: banker.Username}
+				store.EXPECT().ApproveTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "ApproveAboveThreshold",
+			user:   banker,
+			action: "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+				store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(review.ID)).Times(1).Return(review, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				pending := threshold
+				pending.ID = 6
+				store.EXPECT().ApproveTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ApproveTransferTxResult{Approval: review, Pending: &pending}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Equal(t, "/transfer-approvals/6", recorder.Header().Get("Location"))
+
+				var got db.ApproveTransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.NotNil(t, got.Pending)
+				require.Equal(t, db.ApprovalReasonThreshold, got.Pending.Reason)
+			},
+		},
+		{
+			name:   "Reject",
+			user:   banker,
+			action: "reject",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+				store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(review.ID)).Times(1).Return(review, nil)
+				arg := db.RejectTransferApprovalParams{ID: review.ID, DecidedBy: banker.Username}
+				store.EXPECT().RejectTransferApproval(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "NotBanker",
+			user:   depositor,
+			action: "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(depositor.Username)).Times(1).Return(depositor, nil)
+				store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFraudReview",
+			user:   banker,
+			action: "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+				store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(review.ID)).Times(1).Return(threshold, nil)
+				store.EXPECT().ApproveTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			user:   banker,
+			action: "reject",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+				store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(review.ID)).Times(1).Return(db.TransferApproval{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/fraud-reviews/%d/%s", review.ID, tc.action)
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader([]byte(`{}`)))
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestApproveTransferHeldForFraudReview(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	owner, _, _ := randomUser(t)
+	clerk, _, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	approval := db.TransferApproval{ID: 3, FromAccountID: account.ID, Initiator: clerk.Username, Reason: db.ApprovalReasonFraudReview}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(approval.ID)).Times(1).Return(approval, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().ApproveTransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := createNewServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/transfer-approvals/%d/approve", approval.ID), nil)
+	require.NoError(t, err)
+
+	addAuthHeader(t, req, server.tokenGenerator, authorizationType, owner.Username, time.Minute)
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestListFraudDecisionsAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	banker, _, _ := randomUser(t)
+	banker.Role = db.UserRoleBanker
+	decisions := []db.FraudDecision{
+		{ID: 2, FromAccountID: 4, Outcome: db.FraudOutcomeHold, Rule: "first"},
+		{ID: 1, FromAccountID: 4, Outcome: db.FraudOutcomeAllow},
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(banker.Username)).Times(1).Return(banker, nil)
+	arg := db.ListFraudDecisionsParams{FromAccountID: 4, Limit: 5, Offset: 5}
+	store.EXPECT().ListFraudDecisions(gomock.Any(), gomock.Eq(arg)).Times(1).Return(decisions, nil)
+
+	server := createNewServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	req, err := http.NewRequest(http.MethodGet, "/fraud-decisions?account_id=4&page=2&page_size=5", nil)
+	require.NoError(t, err)
+
+	addAuthHeader(t, req, server.tokenGenerator, authorizationType, banker.Username, time.Minute)
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []db.FraudDecision
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	require.Equal(t, decisions, got)
+}
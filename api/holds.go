@@ -0,0 +1,160 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+type placeHoldRequest struct {
+	AccountID int64     `json:"account_id" binding:"required,min=1"`
+	Amount    int64     `json:"amount" binding:"required,gt=0"`
+	Currency  string    `json:"currency" binding:"required,currency"`
+	Reference string    `json:"reference" binding:"required,max=255"`
+	ExpiresAt time.Time `json:"expires_at" binding:"required"`
+}
+
+func (server *Server) placeHold(ctx *gin.Context) {
+	var req placeHoldRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.ExpiresAt.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("expires_at must be in the future")))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, req.AccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, db.PermissionTransact) {
+		return
+	}
+
+	hold, err := server.store.PlaceHoldTx(ctx, db.PlaceHoldTxParams{
+		AccountID: req.AccountID,
+		Amount:    req.Amount,
+		Reference: req.Reference,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+type holdParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type captureHoldRequest struct {
+	ToAccountID     int64  `json:"to_account_id" binding:"required_without=ToAccountNumber,omitempty,min=1"`
+	ToAccountNumber string `json:"to_account_number" binding:"omitempty,account_number"`
+	Amount          int64  `json:"amount" binding:"min=0"`
+}
+
+func (server *Server) captureHold(ctx *gin.Context) {
+	var params holdParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req captureHoldRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, account, valid := server.validHold(ctx, params.ID)
+	if !valid {
+		return
+	}
+
+	toAccount, valid := server.validAccountRef(ctx, req.ToAccountID, req.ToAccountNumber, account.Currency)
+	if !valid {
+		return
+	}
+
+	amount := req.Amount
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if !server.screenLaterTransfer(ctx, account, toAccount, amount) {
+		return
+	}
+
+	result, err := server.store.CaptureHoldTx(ctx, db.CaptureHoldTxParams{
+		HoldID:      params.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      req.Amount,
+	})
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) releaseHold(ctx *gin.Context) {
+	var params holdParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, _, valid := server.validHold(ctx, params.ID)
+	if !valid {
+		return
+	}
+
+	hold, err := server.store.ReleaseHold(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(db.ErrHoldNotActive))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+// validHold loads a hold and its account and checks that the
+// authenticated user may transact on that account.
+func (server *Server) validHold(ctx *gin.Context, holdID int64) (db.Hold, db.Account, bool) {
+	var account db.Account
+	hold, err := server.store.GetHold(ctx, holdID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return hold, account, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return hold, account, false
+	}
+
+	account, err = server.store.GetAccount(ctx, hold.AccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return hold, account, false
+	}
+
+	if !server.authorizeAccount(ctx, account, db.PermissionTransact) {
+		return hold, account, false
+	}
+
+	return hold, account, true
+}
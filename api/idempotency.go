@@ -0,0 +1,89 @@
+package api
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhphuocnguyen/go-simple-bank/auth"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+var errIdempotencyKeyMismatch = errors.New("idempotency key was used with a different request")
+
+// idempotencyParams reads the Idempotency-Key header and ties it to the
+// authenticated user and the request body. It returns nil when the client
+// sent no key.
+func idempotencyParams(ctx *gin.Context, req any) (*db.IdempotencyKeyParams, bool) {
+	key := ctx.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		return nil, true
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("idempotency key is too long")))
+		return nil, false
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+	hash := sha256.Sum256(body)
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	return &db.IdempotencyKeyParams{
+		Username:    authPayload.Username,
+		Key:         key,
+		RequestHash: hex.EncodeToString(hash[:]),
+	}, true
+}
+
+// replayIdempotentResponse writes the stored response for a key that has
+// already been used, with its original status. An accepted async transfer
+// points at the transfer again so the client can poll it, and a transfer
+// parked for approval points at its approval. It reports whether a
+// response was written.
+func (server *Server) replayIdempotentResponse(ctx *gin.Context, arg db.IdempotencyKeyParams) bool {
+	stored, err := server.store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username: arg.Username,
+		Key:      arg.Key,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return true
+	}
+
+	if stored.RequestHash != arg.RequestHash {
+		ctx.JSON(http.StatusConflict, errorResponse(errIdempotencyKeyMismatch))
+		return true
+	}
+
+	if stored.StatusCode == http.StatusAccepted {
+		switch {
+		case stored.TransferID.Valid:
+			ctx.Header("Location", fmt.Sprintf("/transfers/%d", stored.TransferID.Int64))
+		case stored.ApprovalID.Valid:
+			ctx.Header("Location", fmt.Sprintf("/transfer-approvals/%d", stored.ApprovalID.Int64))
+		}
+	}
+
+	ctx.Header(idempotentReplayedHeader, "true")
+	ctx.Data(int(stored.StatusCode), "application/json; charset=utf-8", stored.Response)
+	return true
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func TestCreateTransferIdempotency(t *testing.T) {
+	amount := int64(100)
+	user1, _, _ := randomUser(t)
+	user2, _, _ := randomUser(t)
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account1.Currency = utils.USD
+	account2.Currency = utils.USD
+
+	key := utils.RandomString(16)
+	body := gin.H{
+		"from_account_id": account1.ID,
+		"to_account_id":   account2.ID,
+		"amount":          amount,
+		"currency":        utils.USD,
+	}
+	idempotency := &db.IdempotencyKeyParams{
+		Username: user1.Username,
+		Key:      key,
+		RequestHash: requestHash(t, transferRequest{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			Currency:      utils.USD,
+		}),
+	}
+	getArg := db.GetIdempotencyKeyParams{Username: user1.Username, Key: key}
+	stored := db.IdempotencyKey{
+		Username:    user1.Username,
+		Key:         key,
+		RequestHash: idempotency.RequestHash,
+		Response:    []byte(`{"transfer":{"id":42}}`),
+		TransferID:  pgtype.Int8{Int64: 42, Valid: true},
+		StatusCode:  http.StatusOK,
+	}
+
+	testCases := []struct {
+		name          string
+		key           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "FirstRequest",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(getArg)).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+					Idempotency:   idempotency,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get(idempotentReplayedHeader))
+			},
+		},
+		{
+			name: "ParkedForApproval",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				limited := account1
+				limited.ApprovalThreshold = amount - 1
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(getArg)).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(limited, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				arg := db.CreateTransferApprovalTxParams{
+					Approval: db.CreateTransferApprovalParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        amount,
+						Initiator:     user1.Username,
+						Reason:        db.ApprovalReasonThreshold,
+					},
+					Idempotency: idempotency,
+				}
+				store.EXPECT().CreateTransferApprovalTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.TransferApproval{ID: 7, Status: db.TransferApprovalStatusPending}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Equal(t, "/transfer-approvals/7", recorder.Header().Get("Location"))
+			},
+		},
+		{
+			name: "Replay",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(getArg)).Times(1).Return(stored, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+				require.Equal(t, string(stored.Response), recorder.Body.String())
+			},
+		},
+		{
+			name: "ReplayAsync",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				accepted := stored
+				accepted.Response = []byte(`{"id":42,"status":"pending"}`)
+				accepted.StatusCode = http.StatusAccepted
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(getArg)).Times(1).Return(accepted, nil)
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Equal(t, "/transfers/42", recorder.Header().Get("Location"))
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+				require.Equal(t, `{"id":42,"status":"pending"}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "ReplayApproval",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				parked := stored
+				parked.Response = []byte(`{"id":7,"status":"pending"}`)
+				parked.StatusCode = http.StatusAccepted
+				parked.TransferID = pgtype.Int8{}
+				parked.ApprovalID = pgtype.Int8{Int64: 7, Valid: true}
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(getArg)).Times(1).Return(parked, nil)
+				store.EXPECT().CreateTransferApprovalTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Equal(t, "/transfer-approvals/7", recorder.Header().Get("Location"))
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+				require.Equal(t, `{"id":7,"status":"pending"}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "DifferentBody",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				other := stored
+				other.RequestHash = "other"
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(getArg)).Times(1).Return(other, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "ConcurrentRetry",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(getArg)).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows),
+					store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(getArg)).Times(1).Return(stored, nil),
+				)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrDuplicateIdempotencyKey)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, string(stored.Response), recorder.Body.String())
+			},
+		},
+		{
+			name: "GetIdempotencyKeyError",
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "KeyTooLong",
+			key:  strings.Repeat("k", maxIdempotencyKeyLength+1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			req, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, user1.Username, time.Minute)
+			req.Header.Set(idempotencyKeyHeader, tc.key)
+
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func requestHash(t *testing.T, req any) string {
+	data, err := json.Marshal(req)
+	require.NoError(t, err)
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
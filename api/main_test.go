@@ -13,7 +13,15 @@ import (
 
 func createNewServer(t *testing.T, store db.Store) *Server {
 	config := utils.Config{
-		AccessTokenDuration: time.Minute,
+		AccessTokenDuration:  time.Minute,
+		MaxCheckingAccounts:  3,
+		MaxSavingsAccounts:   5,
+		EnabledCurrencies:    []string{utils.USD, utils.EUR, utils.CAD},
+		FXSpreadBps:          50,
+		PayeeCoolingOff:      24 * time.Hour,
+		PayeeCoolingOffLimit: 500,
+		PaymentRequestExpiry: 7 * 24 * time.Hour,
+		EscrowTimeout:        30 * 24 * time.Hour,
 	}
 	server, err := NewServer(config, store)
 	require.NoError(t, err)
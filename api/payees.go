@@ -0,0 +1,195 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/thanhphuocnguyen/go-simple-bank/auth"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+var (
+	errPayeeNotOwned = errors.New("payee doesn't belong to the authenticated user")
+	errPayeeExists   = errors.New("account is already a payee")
+
+	errPayeeCoolingOff = errors.New("transfers to a new payee are limited")
+)
+
+type createPayeeRequest struct {
+	Nickname      string `json:"nickname" binding:"required,max=64"`
+	AccountID     int64  `json:"account_id" binding:"required_without=AccountNumber,omitempty,min=1"`
+	AccountNumber string `json:"account_number" binding:"omitempty,account_number"`
+	Currency      string `json:"currency" binding:"required,currency"`
+}
+
+// createPayee adds an account to the authenticated user's payee book. The
+// payee starts in its cooling-off period, during which transfers to it are
+// capped at PayeeCoolingOffLimit, so that whoever takes over a login cannot
+// add their own account and empty the user's accounts into it at once.
+func (server *Server) createPayee(ctx *gin.Context) {
+	var req createPayeeRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccountRef(ctx, req.AccountID, req.AccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	payee, err := server.store.CreatePayee(ctx, db.CreatePayeeParams{
+		Username:        authPayload.Username,
+		Nickname:        req.Nickname,
+		AccountID:       account.ID,
+		Currency:        account.Currency,
+		CoolingOffUntil: pgtype.Timestamptz{Time: time.Now().Add(server.config.PayeeCoolingOff), Valid: true},
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+			ctx.JSON(http.StatusConflict, errorResponse(errPayeeExists))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, payee)
+}
+
+type listPayeesRequest struct {
+	Page     int32 `form:"page" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listPayees(ctx *gin.Context) {
+	var req listPayeesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	payees, err := server.store.ListPayees(ctx, db.ListPayeesParams{
+		Username: authPayload.Username,
+		Limit:    req.PageSize,
+		Offset:   (req.Page - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, payees)
+}
+
+type payeeParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) deletePayee(ctx *gin.Context) {
+	var params payeeParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	payee, err := server.store.DeletePayee(ctx, db.DeletePayeeParams{
+		ID:       params.ID,
+		Username: authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, payee)
+}
+
+// validPayee loads one of the authenticated user's payees. A payee can only
+// be paid by its ID once its cooling-off period is over; until then its
+// account has to be named directly, and checkCoolingOff caps the amount.
+func (server *Server) validPayee(ctx *gin.Context, payeeID int64) (db.Payee, bool) {
+	payee, err := server.store.GetPayee(ctx, payeeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return payee, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return payee, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	if payee.Username != authPayload.Username {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errPayeeNotOwned))
+		return payee, false
+	}
+
+	if time.Now().Before(payee.CoolingOffUntil.Time) {
+		err := fmt.Errorf("payee is cooling off until %s and cannot be paid by its ID yet",
+			payee.CoolingOffUntil.Time.Format(time.RFC3339))
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return payee, false
+	}
+
+	return payee, true
+}
+
+// checkCoolingOff rejects amounts above PayeeCoolingOffLimit to an account
+// that is one of the authenticated user's payees still cooling off, so the
+// limit cannot be dodged by naming the account instead of the payee.
+func (server *Server) checkCoolingOff(ctx *gin.Context, account db.Account, amount int64) bool {
+	err := server.coolingOff(ctx, account, amount)
+	switch {
+	case errors.Is(err, errPayeeCoolingOff):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return false
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// coolingOff is checkCoolingOff for callers that report the problem
+// themselves. It returns an error wrapping errPayeeCoolingOff when amount
+// is over the limit, and store failures as they are.
+func (server *Server) coolingOff(ctx *gin.Context, account db.Account, amount int64) error {
+	if amount <= server.config.PayeeCoolingOffLimit {
+		return nil
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	payee, err := server.store.GetPayeeByAccount(ctx, db.GetPayeeByAccountParams{
+		Username:  authPayload.Username,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if time.Now().Before(payee.CoolingOffUntil.Time) {
+		return fmt.Errorf("%w to %d until %s", errPayeeCoolingOff,
+			server.config.PayeeCoolingOffLimit, payee.CoolingOffUntil.Time.Format(time.RFC3339))
+	}
+
+	return nil
+}
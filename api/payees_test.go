@@ -0,0 +1,395 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func randomPayee(username string, account db.Account, coolingOffUntil time.Time) db.Payee {
+	return db.Payee{
+		ID:              utils.RandomInt(1, 1000),
+		Username:        username,
+		Nickname:        utils.RandomOwner(),
+		AccountID:       account.ID,
+		Currency:        account.Currency,
+		CoolingOffUntil: pgtype.Timestamptz{Time: coolingOffUntil, Valid: true},
+	}
+}
+
+// expectNoPayee stubs the payee book lookup made for transfers above
+// PayeeCoolingOffLimit to find no payee for the destination account.
+func expectNoPayee(store *mockdb.MockStore) {
+	store.EXPECT().GetPayeeByAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Payee{}, sql.ErrNoRows)
+}
+
+func TestCreatePayeeAPI(t *testing.T) {
+	user, _, _ := randomUser(t)
+	other, _, _ := randomUser(t)
+	account := randomAccount(other.Username)
+	account.Currency = utils.USD
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"nickname": "landlord", "account_number": account.AccountNumber, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(account.AccountNumber)).Times(1).Return(account, nil)
+				store.EXPECT().CreatePayee(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreatePayeeParams) (db.Payee, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, "landlord", arg.Nickname)
+						require.Equal(t, account.ID, arg.AccountID)
+						require.Equal(t, utils.USD, arg.Currency)
+						require.WithinDuration(t, time.Now().Add(24*time.Hour), arg.CoolingOffUntil.Time, time.Minute)
+						return db.Payee{ID: 1, Username: arg.Username, AccountID: arg.AccountID}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyPayee",
+			body: gin.H{"nickname": "landlord", "account_id": account.ID, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreatePayee(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.Payee{}, &pgconn.PgError{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			body: gin.H{"nickname": "landlord", "account_id": account.ID, "currency": utils.EUR},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreatePayee(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MissingNickname",
+			body: gin.H{"account_id": account.ID, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePayee(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/payees", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListAndDeletePayeesAPI(t *testing.T) {
+	user, _, _ := randomUser(t)
+	payee := randomPayee(user.Username, randomAccount(utils.RandomOwner()), time.Now())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListPayees(gomock.Any(), gomock.Eq(db.ListPayeesParams{
+		Username: user.Username,
+		Limit:    5,
+		Offset:   5,
+	})).Times(1).Return([]db.Payee{payee}, nil)
+	arg := db.DeletePayeeParams{ID: payee.ID, Username: user.Username}
+	gomock.InOrder(
+		store.EXPECT().DeletePayee(gomock.Any(), gomock.Eq(arg)).Times(1).Return(payee, nil),
+		store.EXPECT().DeletePayee(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Payee{}, sql.ErrNoRows),
+	)
+
+	server := createNewServer(t, store)
+	serve := func(method, url string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest(method, url, nil)
+		require.NoError(t, err)
+		addAuthHeader(t, req, server.tokenGenerator, authorizationType, user.Username, time.Minute)
+		server.router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := serve(http.MethodGet, "/payees?page=2&page_size=5")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var got []db.Payee
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	require.Len(t, got, 1)
+	require.Equal(t, payee.ID, got[0].ID)
+
+	url := fmt.Sprintf("/payees/%d", payee.ID)
+	require.Equal(t, http.StatusOK, serve(http.MethodDelete, url).Code)
+	require.Equal(t, http.StatusNotFound, serve(http.MethodDelete, url).Code)
+}
+
+func TestCreateTransferToPayee(t *testing.T) {
+	user, _, _ := randomUser(t)
+	other, _, _ := randomUser(t)
+	fromAccount := randomAccount(user.Username)
+	fromAccount.Currency = utils.USD
+	toAccount := randomAccount(other.Username)
+	toAccount.Currency = utils.USD
+
+	coolingOff := randomPayee(user.Username, toAccount, time.Now().Add(time.Hour))
+	established := randomPayee(user.Username, toAccount, time.Now().Add(-time.Hour))
+	stranger := randomPayee(other.Username, toAccount, time.Now().Add(-time.Hour))
+
+	testCases := []struct {
+		name          string
+		payee         db.Payee
+		amount        int64
+		buildStubs    func(store *mockdb.MockStore, payee db.Payee, amount int64)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			// a payee can only be paid by its ID once it has cooled off;
+			// until then the destination account must be named directly
+			name:   "CoolingOffWithinLimit",
+			payee:  coolingOff,
+			amount: 500,
+			buildStubs: func(store *mockdb.MockStore, payee db.Payee, amount int64) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetPayee(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:   "CoolingOffAboveLimit",
+			payee:  coolingOff,
+			amount: 501,
+			buildStubs: func(store *mockdb.MockStore, payee db.Payee, amount int64) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetPayee(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:   "Established",
+			payee:  established,
+			amount: 10_000,
+			buildStubs: func(store *mockdb.MockStore, payee db.Payee, amount int64) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetPayee(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "SomeoneElsesPayee",
+			payee:  stranger,
+			amount: 10,
+			buildStubs: func(store *mockdb.MockStore, payee db.Payee, amount int64) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetPayee(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, tc.payee, tc.amount)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": fromAccount.ID,
+				"payee_id":        tc.payee.ID,
+				"amount":          tc.amount,
+				"currency":        utils.USD,
+			})
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateTransferToCoolingOffAccount(t *testing.T) {
+	user, _, _ := randomUser(t)
+	other, _, _ := randomUser(t)
+	fromAccount := randomAccount(user.Username)
+	fromAccount.Currency = utils.USD
+	toAccount := randomAccount(other.Username)
+	toAccount.Currency = utils.USD
+
+	coolingOff := randomPayee(user.Username, toAccount, time.Now().Add(time.Hour))
+	established := randomPayee(user.Username, toAccount, time.Now().Add(-time.Hour))
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ByIDAboveLimit",
+			body: gin.H{"to_account_id": toAccount.ID, "amount": 501},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetPayeeByAccount(gomock.Any(), gomock.Eq(db.GetPayeeByAccountParams{
+					Username:  user.Username,
+					AccountID: toAccount.ID,
+				})).Times(1).Return(coolingOff, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "ByNumberAboveLimit",
+			body: gin.H{"to_account_number": toAccount.AccountNumber, "amount": 501},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), gomock.Eq(toAccount.AccountNumber)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetPayeeByAccount(gomock.Any(), gomock.Any()).Times(1).Return(coolingOff, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "WithinLimit",
+			body: gin.H{"to_account_id": toAccount.ID, "amount": 500},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetPayeeByAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Established",
+			body: gin.H{"to_account_id": toAccount.ID, "amount": 10_000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetPayeeByAccount(gomock.Any(), gomock.Any()).Times(1).Return(established, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotAPayee",
+			body: gin.H{"to_account_id": toAccount.ID, "amount": 10_000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				expectNoPayee(store)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "LookupError",
+			body: gin.H{"to_account_id": toAccount.ID, "amount": 10_000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetPayeeByAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Payee{}, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			tc.body["from_account_id"] = fromAccount.ID
+			tc.body["currency"] = utils.USD
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/thanhphuocnguyen/go-simple-bank/auth"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+var (
+	errSelfPaymentRequest     = errors.New("cannot request money from yourself")
+	errPaymentRequestExpiry   = errors.New("expires_at must be in the future and within the maximum expiry")
+	errPaymentRequestNotParty = errors.New("payment request doesn't involve the authenticated user")
+	errNotPaymentRequester    = errors.New("only the requester can cancel a payment request")
+)
+
+// createPaymentRequestRequest asks Payer for Amount, to be paid into the
+// requester's ToAccountID. ExpiresAt defaults to PaymentRequestExpiry from
+// now, which is also the longest a request may stay open.
+type createPaymentRequestRequest struct {
+	Payer       string    `json:"payer" binding:"required,alphanum"`
+	ToAccountID int64     `json:"to_account_id" binding:"required,min=1"`
+	Amount      int64     `json:"amount" binding:"required,gt=0"`
+	Currency    string    `json:"currency" binding:"required,currency"`
+	Description string    `json:"description" binding:"max=140"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func (server *Server) createPaymentRequest(ctx *gin.Context) {
+	var req createPaymentRequestRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	if req.Payer == authPayload.Username {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errSelfPaymentRequest))
+		return
+	}
+
+	now := time.Now()
+	expiresAt := req.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(server.config.PaymentRequestExpiry)
+	}
+	if !expiresAt.After(now) || expiresAt.After(now.Add(server.config.PaymentRequestExpiry)) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errPaymentRequestExpiry))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, db.PermissionTransact) {
+		return
+	}
+
+	payer, err := server.store.GetUser(ctx, req.Payer)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	request, err := server.store.CreatePaymentRequest(ctx, db.CreatePaymentRequestParams{
+		Requester:   authPayload.Username,
+		Payer:       payer.Username,
+		ToAccountID: account.ID,
+		Amount:      req.Amount,
+		Currency:    account.Currency,
+		Description: req.Description,
+		ExpiresAt:   pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, request)
+}
+
+type listPaymentRequestsRequest struct {
+	Page     int32 `form:"page" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listIncomingPaymentRequests lists the requests the authenticated user has
+// been asked to pay.
+func (server *Server) listIncomingPaymentRequests(ctx *gin.Context) {
+	var req listPaymentRequestsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	requests, err := server.store.ListIncomingPaymentRequests(ctx, db.ListIncomingPaymentRequestsParams{
+		Payer:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.Page - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, requests)
+}
+
+// listOutgoingPaymentRequests lists the requests the authenticated user has
+// sent.
+func (server *Server) listOutgoingPaymentRequests(ctx *gin.Context) {
+	var req listPaymentRequestsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	requests, err := server.store.ListOutgoingPaymentRequests(ctx, db.ListOutgoingPaymentRequestsParams{
+		Requester: authPayload.Username,
+		Limit:     req.PageSize,
+		Offset:    (req.Page - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, requests)
+}
+
+type paymentRequestParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getPaymentRequest(ctx *gin.Context) {
+	var params paymentRequestParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	request, valid := server.validPaymentRequest(ctx, params.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, request)
+}
+
+type acceptPaymentRequestRequest struct {
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+}
+
+// acceptPaymentRequest pays a request from one of the payer's accounts in
+// the request currency. Only the payer can accept, and only once. Like
+// createTransfer, the payment is screened for fraud and an amount above the
+// account's approval threshold is parked for a second approver, who pays
+// the request by approving it.
+func (server *Server) acceptPaymentRequest(ctx *gin.Context) {
+	var params paymentRequestParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req acceptPaymentRequestRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	request, valid := server.validPaymentRequest(ctx, params.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	if request.Payer != authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrNotPaymentRequestPayer))
+		return
+	}
+
+	// checked again under lock when paying, but a decided or expired
+	// request must not be screened or parked for approval
+	switch {
+	case request.Status != db.PaymentRequestStatusPending:
+		storeErrorResponse(ctx, db.ErrPaymentRequestNotPending)
+		return
+	case !time.Now().Before(request.ExpiresAt.Time):
+		storeErrorResponse(ctx, db.ErrPaymentRequestExpired)
+		return
+	}
+
+	account, valid := server.validAccount(ctx, req.FromAccountID, request.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, db.PermissionTransact) {
+		return
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, request.ToAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// paying a request is screened and approved like any other transfer
+	payment := transferRequest{
+		FromAccountID:    account.ID,
+		ToAccountID:      toAccount.ID,
+		Amount:           request.Amount,
+		Currency:         request.Currency,
+		Description:      request.Description,
+		paymentRequestID: request.ID,
+	}
+	if !server.screenTransfer(ctx, payment, account, toAccount, nil) {
+		return
+	}
+
+	if account.RequiresApproval(request.Amount) {
+		server.requestTransferApproval(ctx, payment, account, toAccount, nil)
+		return
+	}
+
+	result, err := server.store.PayPaymentRequestTx(ctx, db.PayPaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            authPayload.Username,
+		FromAccountID:    account.ID,
+	})
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// declinePaymentRequest lets the payer turn a pending request down.
+func (server *Server) declinePaymentRequest(ctx *gin.Context) {
+	var params paymentRequestParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	request, valid := server.validPaymentRequest(ctx, params.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	if request.Payer != authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrNotPaymentRequestPayer))
+		return
+	}
+
+	request, err := server.store.DeclinePaymentRequest(ctx, request.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(db.ErrPaymentRequestNotPending))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, request)
+}
+
+// cancelPaymentRequest lets the requester withdraw a pending request.
+func (server *Server) cancelPaymentRequest(ctx *gin.Context) {
+	var params paymentRequestParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	request, valid := server.validPaymentRequest(ctx, params.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	if request.Requester != authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errNotPaymentRequester))
+		return
+	}
+
+	request, err := server.store.CancelPaymentRequest(ctx, request.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(db.ErrPaymentRequestNotPending))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, request)
+}
+
+// validPaymentRequest loads a payment request that the authenticated user
+// either sent or was asked to pay.
+func (server *Server) validPaymentRequest(ctx *gin.Context, id int64) (db.PaymentRequest, bool) {
+	request, err := server.store.GetPaymentRequest(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return request, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return request, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	if request.Requester != authPayload.Username && request.Payer != authPayload.Username {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errPaymentRequestNotParty))
+		return request, false
+	}
+
+	return request, true
+}
@@ -0,0 +1,549 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/fraud"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func randomPaymentRequest(requester, payer string, account db.Account) db.PaymentRequest {
+	return db.PaymentRequest{
+		ID:          utils.RandomInt(1, 1000),
+		Requester:   requester,
+		Payer:       payer,
+		ToAccountID: account.ID,
+		Amount:      utils.RandomInt(1, 100),
+		Currency:    account.Currency,
+		Description: "dinner",
+		Status:      db.PaymentRequestStatusPending,
+		ExpiresAt:   pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+}
+
+func TestCreatePaymentRequestAPI(t *testing.T) {
+	requester, _, _ := randomUser(t)
+	payer, _, _ := randomUser(t)
+	account := randomAccount(requester.Username)
+	account.Currency = utils.USD
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"payer": payer.Username, "to_account_id": account.ID, "amount": 25, "currency": utils.USD, "description": "dinner"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(payer, nil)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreatePaymentRequestParams) (db.PaymentRequest, error) {
+						require.Equal(t, requester.Username, arg.Requester)
+						require.Equal(t, payer.Username, arg.Payer)
+						require.Equal(t, account.ID, arg.ToAccountID)
+						require.Equal(t, int64(25), arg.Amount)
+						require.Equal(t, utils.USD, arg.Currency)
+						require.Equal(t, "dinner", arg.Description)
+						require.WithinDuration(t, time.Now().Add(7*24*time.Hour), arg.ExpiresAt.Time, time.Minute)
+						return db.PaymentRequest{ID: 1, Status: db.PaymentRequestStatusPending}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "SelfRequest",
+			body: gin.H{"payer": requester.Username, "to_account_id": account.ID, "amount": 25, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiryTooFar",
+			body: gin.H{"payer": payer.Username, "to_account_id": account.ID, "amount": 25, "currency": utils.USD, "expires_at": time.Now().Add(30 * 24 * time.Hour)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiryInPast",
+			body: gin.H{"payer": payer.Username, "to_account_id": account.ID, "amount": 25, "currency": utils.USD, "expires_at": time.Now().Add(-time.Hour)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PayerNotFound",
+			body: gin.H{"payer": payer.Username, "to_account_id": account.ID, "amount": 25, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NotAccountMember",
+			body: gin.H{"payer": payer.Username, "to_account_id": account.ID, "amount": 25, "currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				other := account
+				other.Owner = utils.RandomOwner()
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(other, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/payment-requests", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, requester.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListPaymentRequestsAPI(t *testing.T) {
+	user, _, _ := randomUser(t)
+	incoming := randomPaymentRequest(utils.RandomOwner(), user.Username, randomAccount(utils.RandomOwner()))
+	outgoing := randomPaymentRequest(user.Username, utils.RandomOwner(), randomAccount(user.Username))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListIncomingPaymentRequests(gomock.Any(), gomock.Eq(db.ListIncomingPaymentRequestsParams{
+		Payer:  user.Username,
+		Limit:  5,
+		Offset: 0,
+	})).Times(1).Return([]db.PaymentRequest{incoming}, nil)
+	store.EXPECT().ListOutgoingPaymentRequests(gomock.Any(), gomock.Eq(db.ListOutgoingPaymentRequestsParams{
+		Requester: user.Username,
+		Limit:     5,
+		Offset:    5,
+	})).Times(1).Return([]db.PaymentRequest{outgoing}, nil)
+
+	server := createNewServer(t, store)
+	for _, tc := range []struct {
+		url  string
+		want db.PaymentRequest
+	}{
+		{"/payment-requests/incoming?page=1&page_size=5", incoming},
+		{"/payment-requests/outgoing?page=2&page_size=5", outgoing},
+	} {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+		require.NoError(t, err)
+
+		addAuthHeader(t, req, server.tokenGenerator, authorizationType, user.Username, time.Minute)
+		server.router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var got []db.PaymentRequest
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+		require.Len(t, got, 1)
+		require.Equal(t, tc.want.ID, got[0].ID)
+	}
+}
+
+func TestAcceptPaymentRequestAPI(t *testing.T) {
+	requester, _, _ := randomUser(t)
+	payer, _, _ := randomUser(t)
+	toAccount := randomAccount(requester.Username)
+	toAccount.Currency = utils.USD
+	fromAccount := randomAccount(payer.Username)
+	fromAccount.Currency = utils.USD
+	request := randomPaymentRequest(requester.Username, payer.Username, toAccount)
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				arg := db.PayPaymentRequestTxParams{
+					PaymentRequestID: request.ID,
+					Payer:            payer.Username,
+					FromAccountID:    fromAccount.ID,
+				}
+				paid := request
+				paid.Status = db.PaymentRequestStatusPaid
+				paid.TransferID = pgtype.Int8{Int64: 9, Valid: true}
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.PayPaymentRequestTxResult{PaymentRequest: paid}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.PayPaymentRequestTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, db.PaymentRequestStatusPaid, got.PaymentRequest.Status)
+				require.Equal(t, int64(9), got.PaymentRequest.TransferID.Int64)
+			},
+		},
+		{
+			name:     "RequesterCannotAccept",
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "Stranger",
+			username: utils.RandomOwner(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "CurrencyMismatch",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				eurAccount := fromAccount
+				eurAccount.Currency = utils.EUR
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(eurAccount, nil)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "AboveApprovalThreshold",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				limited := fromAccount
+				limited.ApprovalThreshold = request.Amount - 1
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(limited, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				arg := db.CreateTransferApprovalTxParams{
+					Approval: db.CreateTransferApprovalParams{
+						FromAccountID:    fromAccount.ID,
+						ToAccountID:      toAccount.ID,
+						Amount:           request.Amount,
+						Description:      request.Description,
+						Initiator:        payer.Username,
+						Reason:           db.ApprovalReasonThreshold,
+						PaymentRequestID: pgtype.Int8{Int64: request.ID, Valid: true},
+					},
+				}
+				store.EXPECT().CreateTransferApprovalTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.TransferApproval{ID: 7, Status: db.TransferApprovalStatusPending}, nil)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Equal(t, "/transfer-approvals/7", recorder.Header().Get("Location"))
+			},
+		},
+		{
+			name:     "AwaitingApproval",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				limited := fromAccount
+				limited.ApprovalThreshold = request.Amount - 1
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(limited, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().CreateTransferApprovalTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferApproval{}, db.ErrPaymentRequestAwaitingApproval)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "Declined",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				declined := request
+				declined.Status = db.PaymentRequestStatusDeclined
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(declined, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateTransferApprovalTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "ExpiredBeforeAccepting",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				expired := request
+				expired.ExpiresAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(expired, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateTransferApprovalTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "AlreadyPaid",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.PayPaymentRequestTxResult{}, db.ErrPaymentRequestNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "Expired",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.PayPaymentRequestTxResult{}, db.ErrPaymentRequestExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"from_account_id": fromAccount.ID})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/payment-requests/%d/accept", request.ID)
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeclineAndCancelPaymentRequestAPI(t *testing.T) {
+	requester, _, _ := randomUser(t)
+	payer, _, _ := randomUser(t)
+	request := randomPaymentRequest(requester.Username, payer.Username, randomAccount(requester.Username))
+
+	testCases := []struct {
+		name       string
+		action     string
+		username   string
+		buildStubs func(store *mockdb.MockStore)
+		wantStatus int
+	}{
+		{
+			name:     "PayerDeclines",
+			action:   "decline",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeclinePaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).
+					Return(db.PaymentRequest{ID: request.ID, Status: db.PaymentRequestStatusDeclined}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:     "RequesterCannotDecline",
+			action:   "decline",
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeclinePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:     "DeclineDecided",
+			action:   "decline",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeclinePaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).
+					Return(db.PaymentRequest{}, sql.ErrNoRows)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:     "RequesterCancels",
+			action:   "cancel",
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CancelPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).
+					Return(db.PaymentRequest{ID: request.ID, Status: db.PaymentRequestStatusCancelled}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:     "PayerCannotCancel",
+			action:   "cancel",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CancelPaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/payment-requests/%d/%s", request.ID, tc.action)
+			req, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			require.Equal(t, tc.wantStatus, recorder.Code)
+		})
+	}
+}
+
+func TestAcceptPaymentRequestFraudScreening(t *testing.T) {
+	requester, _, _ := randomUser(t)
+	payer, _, _ := randomUser(t)
+	toAccount := randomAccount(requester.Username)
+	toAccount.Currency = utils.USD
+	fromAccount := randomAccount(payer.Username)
+	fromAccount.Currency = utils.USD
+	request := randomPaymentRequest(requester.Username, payer.Username, toAccount)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(request.ID)).Times(1).Return(request, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+	arg := db.RecordFraudDecisionTxParams{
+		Decision: db.CreateFraudDecisionParams{
+			Username:      payer.Username,
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        request.Amount,
+			Outcome:       db.FraudOutcomeHold,
+			Rule:          "first",
+			Reason:        fmt.Sprintf("first transfer to this recipient is %d, limit 0", request.Amount),
+		},
+		Hold: &db.CreateTransferApprovalParams{
+			FromAccountID:    fromAccount.ID,
+			ToAccountID:      toAccount.ID,
+			Amount:           request.Amount,
+			Description:      request.Description,
+			Initiator:        payer.Username,
+			Reason:           db.ApprovalReasonFraudReview,
+			PaymentRequestID: pgtype.Int8{Int64: request.ID, Valid: true},
+		},
+	}
+	store.EXPECT().RecordFraudDecisionTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+		Return(db.RecordFraudDecisionTxResult{Approval: db.TransferApproval{ID: 9, Reason: db.ApprovalReasonFraudReview}}, nil)
+	store.EXPECT().PayPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := createNewServer(t, store)
+	var err error
+	server.fraud, err = fraud.NewEngine([]fraud.Rule{
+		{Name: "first", Kind: fraud.KindNewRecipient, Action: db.FraudOutcomeHold},
+	}, store)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"from_account_id": fromAccount.ID})
+	require.NoError(t, err)
+
+	url := fmt.Sprintf("/payment-requests/%d/accept", request.ID)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthHeader(t, req, server.tokenGenerator, authorizationType, payer.Username, time.Minute)
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+	require.Equal(t, "/transfer-approvals/9", recorder.Header().Get("Location"))
+}
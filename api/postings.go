@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+type postingLegRequest struct {
+	AccountID   int64  `json:"account_id" binding:"required,min=1"`
+	Amount      int64  `json:"amount" binding:"required"`
+	Description string `json:"description" binding:"max=140"`
+}
+
+// createPostingRequest lists the legs of a posting. Negative amounts debit
+// the account and positive ones credit it.
+type createPostingRequest struct {
+	Description string              `json:"description" binding:"max=140"`
+	Legs        []postingLegRequest `json:"legs" binding:"required,min=2,max=50,dive"`
+}
+
+// createPosting lets bankers move money between any number of accounts at
+// once, e.g. to split a payment or sweep internal accounts.
+func (server *Server) createPosting(ctx *gin.Context) {
+	var req createPostingRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.PostingTxParams{
+		Description: req.Description,
+		Legs:        make([]db.PostingLeg, len(req.Legs)),
+	}
+	for i, leg := range req.Legs {
+		arg.Legs[i] = db.PostingLeg{
+			AccountID:   leg.AccountID,
+			Amount:      leg.Amount,
+			Description: leg.Description,
+		}
+	}
+
+	result, err := server.store.PostingTx(ctx, arg)
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type reconcileLedgerRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=json text"`
+}
+
+// reconcileLedger scans the ledger for drifted balances, orphaned entries,
+// unbalanced transfers and currencies that do not sum to zero. The report
+// is JSON unless format=text asks for the human-readable version. Either
+// way the request succeeds; whether the ledger is clean is in the report.
+func (server *Server) reconcileLedger(ctx *gin.Context) {
+	var req reconcileLedgerRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	report, err := server.store.ReconcileTx(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if req.Format != "text" {
+		ctx.JSON(http.StatusOK, report)
+		return
+	}
+
+	ctx.Header("Content-Type", "text/plain; charset=utf-8")
+	ctx.Status(http.StatusOK)
+	if err := report.WriteText(ctx.Writer); err != nil {
+		ctx.Error(err)
+	}
+}
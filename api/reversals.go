@@ -0,0 +1,77 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+type transferParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// reverseTransfer lets a banker send back whatever is left of a transfer.
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var params transferParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, params.ID)
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type refundTransferRequest struct {
+	// Amount is in the recipient's currency; omit it to refund everything
+	// not refunded yet.
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// refundTransfer lets the recipient of a transfer return part or all of it.
+func (server *Server) refundTransfer(ctx *gin.Context) {
+	var params transferParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req refundTransferRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.getAuthorizedAccount(ctx, transfer.ToAccountID, db.PermissionTransact); !valid {
+		return
+	}
+
+	result, err := server.store.RefundTransferTx(ctx, db.RefundTransferTxParams{
+		TransferID: transfer.ID,
+		Amount:     req.Amount,
+	})
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/thanhphuocnguyen/go-simple-bank/auth"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+var errScheduledTransferNotPending = errors.New("scheduled transfer is no longer pending")
+
+// scheduleTransferRequest is a transfer to run at ExecuteAt. Both accounts
+// must use the request currency.
+type scheduleTransferRequest struct {
+	transferRequest
+	ExecuteAt time.Time `json:"execute_at" binding:"required"`
+}
+
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req scheduleTransferRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.ExecuteAt.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("execute_at must be in the future")))
+		return
+	}
+
+	fromAccount, valid := server.validAccountRef(ctx, req.FromAccountID, req.FromAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, fromAccount, db.PermissionTransact) {
+		return
+	}
+
+	toAccount, valid := server.getRecipientRef(ctx, req.transferRequest, true)
+	if !valid {
+		return
+	}
+
+	if !withinApprovalThreshold(ctx, fromAccount, req.Amount) {
+		return
+	}
+
+	if !server.screenLaterTransfer(ctx, fromAccount, toAccount, req.Amount) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	scheduled, err := server.store.CreateScheduledTransfer(ctx, db.CreateScheduledTransferParams{
+		Username:          authPayload.Username,
+		FromAccountID:     fromAccount.ID,
+		ToAccountID:       toAccount.ID,
+		Amount:            req.Amount,
+		ExecuteAt:         pgtype.Timestamptz{Time: req.ExecuteAt, Valid: true},
+		Description:       req.Description,
+		ExternalReference: req.ExternalReference,
+		Metadata:          req.Metadata,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduled)
+}
+
+type listScheduledTransfersRequest struct {
+	Page     int32 `form:"page" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	scheduled, err := server.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		Username: authPayload.Username,
+		Limit:    req.PageSize,
+		Offset:   (req.Page - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduled)
+}
+
+type scheduledTransferParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// cancelScheduledTransfer lets the user who scheduled a transfer, or a
+// manager of its source account, cancel it before it runs.
+func (server *Server) cancelScheduledTransfer(ctx *gin.Context) {
+	var params scheduledTransferParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, err := server.store.GetScheduledTransfer(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	if scheduled.Username != authPayload.Username {
+		if _, valid := server.getAuthorizedAccount(ctx, scheduled.FromAccountID, db.PermissionManage); !valid {
+			return
+		}
+	}
+
+	scheduled, err = server.store.CancelScheduledTransfer(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(errScheduledTransferNotPending))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduled)
+}
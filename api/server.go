@@ -1,11 +1,19 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"github.com/thanhphuocnguyen/go-simple-bank/auth"
 	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/fraud"
+	"github.com/thanhphuocnguyen/go-simple-bank/fx"
 	"github.com/thanhphuocnguyen/go-simple-bank/utils"
 )
 
@@ -14,6 +22,11 @@ type Server struct {
 	store          db.Store
 	router         *gin.Engine
 	tokenGenerator auth.TokenGenerator
+	currencies     *utils.CurrencyRegistry
+	rates          fx.Provider
+	// fraud is nil when no rules file is configured, and transfers are
+	// not screened.
+	fraud *fraud.Engine
 }
 
 func NewServer(config utils.Config, store db.Store) (*Server, error) {
@@ -22,12 +35,51 @@ func NewServer(config utils.Config, store db.Store) (*Server, error) {
 		return nil, err
 	}
 
-	server := &Server{store: store, tokenGenerator: tokenGenerator, config: config}
+	currencies, err := utils.NewCurrencyRegistry(config.EnabledCurrencies)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := newRateProvider(config, store)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &Server{
+		store:          store,
+		tokenGenerator: tokenGenerator,
+		config:         config,
+		currencies:     currencies,
+		rates:          rates,
+	}
+
+	if config.FraudRulesFile != "" {
+		rules, err := fraud.LoadRules(config.FraudRulesFile)
+		if err != nil {
+			return nil, err
+		}
+		server.fraud, err = fraud.NewEngine(rules, store)
+		if err != nil {
+			return nil, err
+		}
+	}
 	server.setupRouter()
 
 	return server, nil
 }
 
+// newRateProvider reads rates from FX_RATES_FILE when FX_RATE_PROVIDER is
+// "file" and from the fx_rates table otherwise.
+func newRateProvider(config utils.Config, store db.Store) (fx.Provider, error) {
+	switch config.FXRateProvider {
+	case "file":
+		return fx.NewFileProvider(config.FXRatesFile)
+	case "", "db":
+		return fx.NewStoreProvider(store), nil
+	}
+	return nil, fmt.Errorf("unknown fx rate provider %q", config.FXRateProvider)
+}
+
 func (server *Server) Start(address string) error {
 	return server.router.Run(address)
 }
@@ -36,25 +88,139 @@ func errorResponse(err error) gin.H {
 	return gin.H{"error": err.Error()}
 }
 
+// storeErrorStatus maps an error returned by the store to a status code by
+// its domain error kind. Anything else is an internal error.
+func storeErrorStatus(err error) int {
+	if errors.Is(err, sql.ErrNoRows) {
+		return http.StatusNotFound
+	}
+
+	switch db.ErrorKindOf(err) {
+	case db.ErrorKindNotFound:
+		return http.StatusNotFound
+	case db.ErrorKindInvalid:
+		return http.StatusBadRequest
+	case db.ErrorKindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case db.ErrorKindConflict:
+		return http.StatusConflict
+	case db.ErrorKindForbidden:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// storeErrorResponse writes err with the status storeErrorStatus picks.
+func storeErrorResponse(ctx *gin.Context, err error) {
+	ctx.JSON(storeErrorStatus(err), errorResponse(err))
+}
+
 func (server *Server) setupRouter() {
 	router := gin.Default()
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("currency", server.validCurrency)
+		v.RegisterValidation("account_number", validAccountNumber)
+		v.RegisterValidation("alias", validAlias)
+		v.RegisterValidation("schedule", validSchedule)
+		v.RegisterValidation("metadata", validMetadata)
 	}
 
 	// add routes for user
 	router.POST("/users", server.createUser)
 	router.POST("/users/login", server.loginUser)
 
+	// add routes for currencies
+	router.GET("/currencies", server.listCurrencies)
+
 	// add routes for auth
 	authRoutes := router.Group("/").Use(authMiddleware(server.tokenGenerator))
 	// add routes for accounts
 	authRoutes.POST("/accounts", server.createAccount)
 	authRoutes.GET("/accounts/:id", server.getAccount)
 	authRoutes.GET("/accounts", server.getAccounts)
+	authRoutes.GET("/accounts/:id/members", server.getAccountMembers)
+	authRoutes.POST("/accounts/:id/members", server.addAccountMember)
+	authRoutes.DELETE("/accounts/:id/members/:username", server.removeAccountMember)
+	authRoutes.GET("/accounts/:id/entries", server.listEntries)
+	authRoutes.PUT("/accounts/:id/approval-threshold", server.setApprovalThreshold)
 
 	// add routes for transfers
 	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.GET("/transfers", server.listTransfers)
+	authRoutes.GET("/transfers/quote", server.quoteTransfer)
+	authRoutes.GET("/transfers/:id", server.getTransfer)
+	authRoutes.POST("/transfers/batch", server.createBatchTransfer)
+	authRoutes.GET("/transfers/batch/:id", server.getBatchTransfer)
+	authRoutes.POST("/transfers/scheduled", server.createScheduledTransfer)
+	authRoutes.GET("/transfers/scheduled", server.listScheduledTransfers)
+	authRoutes.POST("/transfers/scheduled/:id/cancel", server.cancelScheduledTransfer)
+	authRoutes.POST("/transfers/:id/reverse", server.requireRole(db.UserRoleBanker), server.reverseTransfer)
+	authRoutes.POST("/transfers/:id/refund", server.refundTransfer)
+
+	// add routes for postings
+	authRoutes.POST("/postings", server.requireRole(db.UserRoleBanker), server.createPosting)
+
+	// add routes for transfer approvals
+	authRoutes.GET("/transfer-approvals", server.listTransferApprovals)
+	authRoutes.GET("/transfer-approvals/:id", server.getTransferApproval)
+	authRoutes.POST("/transfer-approvals/:id/approve", server.approveTransfer)
+	authRoutes.POST("/transfer-approvals/:id/reject", server.rejectTransfer)
+
+	// add routes for approval threshold changes
+	authRoutes.GET("/approval-threshold-changes", server.listApprovalThresholdChanges)
+	authRoutes.POST("/approval-threshold-changes/:id/approve", server.approveThresholdChange)
+	authRoutes.POST("/approval-threshold-changes/:id/reject", server.rejectThresholdChange)
+
+	// add routes for ledger administration
+	authRoutes.GET("/admin/reconciliation", server.requireRole(db.UserRoleBanker), server.reconcileLedger)
+
+	// add routes for fraud reviews
+	authRoutes.GET("/fraud-decisions", server.requireRole(db.UserRoleBanker), server.listFraudDecisions)
+	authRoutes.POST("/fraud-reviews/:id/approve", server.requireRole(db.UserRoleBanker), server.approveFraudReview)
+	authRoutes.POST("/fraud-reviews/:id/reject", server.requireRole(db.UserRoleBanker), server.rejectFraudReview)
+
+	// add routes for standing orders
+	authRoutes.POST("/standing-orders", server.createStandingOrder)
+	authRoutes.GET("/standing-orders", server.listStandingOrders)
+	authRoutes.GET("/standing-orders/:id/runs", server.listStandingOrderRuns)
+	authRoutes.POST("/standing-orders/:id/pause", server.pauseStandingOrder)
+	authRoutes.POST("/standing-orders/:id/resume", server.resumeStandingOrder)
+
+	// add routes for payees
+	authRoutes.POST("/payees", server.createPayee)
+	authRoutes.GET("/payees", server.listPayees)
+	authRoutes.DELETE("/payees/:id", server.deletePayee)
+
+	// add routes for payment requests
+	authRoutes.POST("/payment-requests", server.createPaymentRequest)
+	authRoutes.GET("/payment-requests/incoming", server.listIncomingPaymentRequests)
+	authRoutes.GET("/payment-requests/outgoing", server.listOutgoingPaymentRequests)
+	authRoutes.GET("/payment-requests/:id", server.getPaymentRequest)
+	authRoutes.POST("/payment-requests/:id/accept", server.acceptPaymentRequest)
+	authRoutes.POST("/payment-requests/:id/decline", server.declinePaymentRequest)
+	authRoutes.POST("/payment-requests/:id/cancel", server.cancelPaymentRequest)
+
+	// add routes for escrows
+	authRoutes.POST("/escrows", server.createEscrow)
+	authRoutes.GET("/escrows", server.listEscrows)
+	authRoutes.GET("/escrows/:id", server.getEscrow)
+	authRoutes.POST("/escrows/:id/release", server.releaseEscrow)
+	authRoutes.POST("/escrows/:id/refund", server.refundEscrow)
+
+	// add routes for aliases
+	authRoutes.POST("/aliases", server.claimAlias)
+	authRoutes.GET("/aliases", server.listAliases)
+	authRoutes.GET("/aliases/lookup", server.lookupAlias)
+	authRoutes.DELETE("/aliases/:alias", server.releaseAlias)
+	authRoutes.POST("/aliases/:alias/verify", server.requireRole(db.UserRoleBanker), server.verifyAlias)
+
+	// add routes for holds
+	authRoutes.POST("/holds", server.placeHold)
+	authRoutes.POST("/holds/:id/capture", server.captureHold)
+	authRoutes.POST("/holds/:id/release", server.releaseHold)
+
+	// add routes for monitoring
+	authRoutes.GET("/debug/vars", server.requireRole(db.UserRoleBanker), gin.WrapH(expvar.Handler()))
 	server.router = router
 }
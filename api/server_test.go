@@ -0,0 +1,40 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+func TestStoreErrorStatus(t *testing.T) {
+	testCases := []struct {
+		err    error
+		status int
+	}{
+		{sql.ErrNoRows, http.StatusNotFound},
+		{db.ErrAccountNotFound, http.StatusNotFound},
+		{db.ErrCurrencyMismatch, http.StatusBadRequest},
+		{db.ErrHoldAmountExceeded, http.StatusBadRequest},
+		{db.ErrInsufficientFunds, http.StatusUnprocessableEntity},
+		{db.ErrRefundAmountExceeded, http.StatusUnprocessableEntity},
+		{db.ErrHoldNotActive, http.StatusConflict},
+		{db.ErrApprovalNotPending, http.StatusConflict},
+		{db.ErrDuplicateIdempotencyKey, http.StatusConflict},
+		{db.ErrSelfApproval, http.StatusForbidden},
+		{db.ErrAccountLimitReached, http.StatusForbidden},
+		{fmt.Errorf("row 3: %w", db.ErrInsufficientFunds), http.StatusUnprocessableEntity},
+		{db.ErrFeeAccountCurrency, http.StatusUnprocessableEntity},
+		{errors.New("connection reset"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			require.Equal(t, tc.status, storeErrorStatus(tc.err))
+		})
+	}
+}
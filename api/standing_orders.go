@@ -0,0 +1,246 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/thanhphuocnguyen/go-simple-bank/auth"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+var (
+	errStandingOrderNotActive = errors.New("standing order is not active")
+	errStandingOrderNotPaused = errors.New("standing order is not paused")
+)
+
+// createStandingOrderRequest repeats a transfer on Schedule until EndAt or
+// MaxRuns runs, whichever comes first. Both accounts must use the request
+// currency.
+type createStandingOrderRequest struct {
+	transferRequest
+	Schedule string     `json:"schedule" binding:"required,schedule"`
+	EndAt    *time.Time `json:"end_at"`
+	MaxRuns  int32      `json:"max_runs" binding:"omitempty,min=1"`
+}
+
+func (server *Server) createStandingOrder(ctx *gin.Context) {
+	var req createStandingOrderRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	schedule, err := utils.ParseSchedule(req.Schedule)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	firstRun := schedule.Next(time.Now())
+	if req.EndAt != nil && req.EndAt.Before(firstRun) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("end_at is before the first run")))
+		return
+	}
+
+	fromAccount, valid := server.validAccountRef(ctx, req.FromAccountID, req.FromAccountNumber, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, fromAccount, db.PermissionTransact) {
+		return
+	}
+
+	toAccount, valid := server.getRecipientRef(ctx, req.transferRequest, true)
+	if !valid {
+		return
+	}
+
+	if !withinApprovalThreshold(ctx, fromAccount, req.Amount) {
+		return
+	}
+
+	if !server.screenLaterTransfer(ctx, fromAccount, toAccount, req.Amount) {
+		return
+	}
+
+	arg := db.CreateStandingOrderParams{
+		Username:          ctx.MustGet(authorizationPayload).(*auth.Payload).Username,
+		FromAccountID:     fromAccount.ID,
+		ToAccountID:       toAccount.ID,
+		Amount:            req.Amount,
+		Schedule:          schedule.String(),
+		NextRunAt:         pgtype.Timestamptz{Time: firstRun, Valid: true},
+		Description:       req.Description,
+		ExternalReference: req.ExternalReference,
+		Metadata:          req.Metadata,
+	}
+	if req.EndAt != nil {
+		arg.EndAt = pgtype.Timestamptz{Time: *req.EndAt, Valid: true}
+	}
+	if req.MaxRuns > 0 {
+		arg.MaxRuns = pgtype.Int4{Int32: req.MaxRuns, Valid: true}
+	}
+
+	order, err := server.store.CreateStandingOrder(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+type listStandingOrdersRequest struct {
+	Page     int32 `form:"page" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listStandingOrders(ctx *gin.Context) {
+	var req listStandingOrdersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	orders, err := server.store.ListStandingOrders(ctx, db.ListStandingOrdersParams{
+		Username: authPayload.Username,
+		Limit:    req.PageSize,
+		Offset:   (req.Page - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, orders)
+}
+
+type standingOrderParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// listStandingOrderRuns returns the scheduled transfers an order has
+// created, newest first.
+func (server *Server) listStandingOrderRuns(ctx *gin.Context) {
+	var params standingOrderParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listStandingOrdersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	order, valid := server.validStandingOrder(ctx, params.ID, db.PermissionView)
+	if !valid {
+		return
+	}
+
+	runs, err := server.store.ListStandingOrderRuns(ctx, db.ListStandingOrderRunsParams{
+		StandingOrderID: pgtype.Int8{Int64: order.ID, Valid: true},
+		Limit:           req.PageSize,
+		Offset:          (req.Page - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+func (server *Server) pauseStandingOrder(ctx *gin.Context) {
+	var params standingOrderParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.validStandingOrder(ctx, params.ID, db.PermissionManage); !valid {
+		return
+	}
+
+	order, err := server.store.PauseStandingOrder(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(errStandingOrderNotActive))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+// resumeStandingOrder reactivates a paused order. Runs missed while it was
+// paused are skipped.
+func (server *Server) resumeStandingOrder(ctx *gin.Context) {
+	var params standingOrderParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	order, valid := server.validStandingOrder(ctx, params.ID, db.PermissionManage)
+	if !valid {
+		return
+	}
+
+	nextRunAt := order.NextRunAt.Time
+	if now := time.Now(); !nextRunAt.After(now) {
+		schedule, err := utils.ParseSchedule(order.Schedule)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		nextRunAt = schedule.Next(now)
+	}
+
+	order, err := server.store.ResumeStandingOrder(ctx, db.ResumeStandingOrderParams{
+		ID:        order.ID,
+		NextRunAt: pgtype.Timestamptz{Time: nextRunAt, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(errStandingOrderNotPaused))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+// validStandingOrder loads a standing order for the user who created it or
+// for anyone holding permission on its source account.
+func (server *Server) validStandingOrder(ctx *gin.Context, orderID int64, permission string) (db.StandingOrder, bool) {
+	order, err := server.store.GetStandingOrder(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return order, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return order, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	if order.Username == authPayload.Username {
+		return order, true
+	}
+
+	_, valid := server.getAuthorizedAccount(ctx, order.FromAccountID, permission)
+	return order, valid
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thanhphuocnguyen/go-simple-bank/auth"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+// requestTransferApproval parks a transfer above the source account's
+// approval threshold until an approver other than the initiator decides on
+// it. The idempotency key is stored with the approval, so a retried request
+// replays it instead of parking the transfer twice.
+func (server *Server) requestTransferApproval(ctx *gin.Context, req transferRequest, fromAccount, toAccount db.Account, idempotency *db.IdempotencyKeyParams) {
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	approval, err := server.store.CreateTransferApprovalTx(ctx, db.CreateTransferApprovalTxParams{
+		Approval: db.CreateTransferApprovalParams{
+			FromAccountID:     fromAccount.ID,
+			ToAccountID:       toAccount.ID,
+			Amount:            req.Amount,
+			Description:       req.Description,
+			ExternalReference: req.ExternalReference,
+			Metadata:          req.Metadata,
+			Initiator:         authPayload.Username,
+			Reason:            db.ApprovalReasonThreshold,
+			PaymentRequestID:  req.paymentRequest(),
+		},
+		Idempotency: idempotency,
+	})
+	if err != nil {
+		server.transferErrorResponse(ctx, idempotency, err)
+		return
+	}
+
+	ctx.Header("Location", fmt.Sprintf("/transfer-approvals/%d", approval.ID))
+	ctx.JSON(http.StatusAccepted, approval)
+}
+
+// withinApprovalThreshold refuses, on paths that cannot park a transfer for
+// a second approver, an amount above the source account's threshold.
+func withinApprovalThreshold(ctx *gin.Context, account db.Account, amount int64) bool {
+	if account.RequiresApproval(amount) {
+		storeErrorResponse(ctx, db.ErrApprovalRequired)
+		return false
+	}
+	return true
+}
+
+type listTransferApprovalsRequest struct {
+	AccountID int64 `form:"account_id" binding:"required,min=1"`
+	Page      int32 `form:"page" binding:"required,min=1"`
+	PageSize  int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listTransferApprovals(ctx *gin.Context) {
+	var req listTransferApprovalsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.getAuthorizedAccount(ctx, req.AccountID, db.PermissionView); !valid {
+		return
+	}
+
+	approvals, err := server.store.ListTransferApprovals(ctx, db.ListTransferApprovalsParams{
+		FromAccountID: req.AccountID,
+		Limit:         req.PageSize,
+		Offset:        (req.Page - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, approvals)
+}
+
+type transferApprovalParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getTransferApproval(ctx *gin.Context) {
+	var params transferApprovalParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	approval, valid := server.validTransferApproval(ctx, params.ID, db.PermissionView)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, approval)
+}
+
+// approveTransfer executes a pending transfer. The approver must manage the
+// source account and must not be the one who initiated the transfer.
+// Transfers held for fraud review are decided by bankers instead.
+func (server *Server) approveTransfer(ctx *gin.Context) {
+	var params transferApprovalParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	approval, valid := server.validTransferApproval(ctx, params.ID, db.PermissionManage)
+	if !valid {
+		return
+	}
+
+	if approval.Reason == db.ApprovalReasonFraudReview {
+		ctx.JSON(http.StatusForbidden, errorResponse(errFraudReview))
+		return
+	}
+
+	server.executeApproval(ctx, approval)
+}
+
+// executeApproval runs an approved transfer on behalf of the authenticated
+// user, converting the amount at today's rate. It answers 202 when the
+// transfer was parked for the approval threshold instead.
+func (server *Server) executeApproval(ctx *gin.Context, approval db.TransferApproval) {
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	if approval.Initiator == authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrSelfApproval))
+		return
+	}
+
+	fromAccount, err := server.store.GetAccount(ctx, approval.FromAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, approval.ToAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.ApproveTransferTxParams{
+		ApprovalID: approval.ID,
+		Approver:   authPayload.Username,
+	}
+
+	if toAccount.Currency != fromAccount.Currency {
+		exchange, valid := server.exchange(ctx, approval.Amount, fromAccount.Currency, toAccount.Currency)
+		if !valid {
+			return
+		}
+		arg.Exchange = &exchange
+	}
+
+	result, err := server.store.ApproveTransferTx(ctx, arg)
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	// a cleared fraud review above the threshold now waits on a second
+	// approver
+	if result.Pending != nil {
+		ctx.Header("Location", fmt.Sprintf("/transfer-approvals/%d", result.Pending.ID))
+		ctx.JSON(http.StatusAccepted, result)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type rejectTransferRequest struct {
+	Note string `json:"note" binding:"max=140"`
+}
+
+// rejectTransfer declines a pending transfer, under the same rules as
+// approveTransfer. No money moves.
+func (server *Server) rejectTransfer(ctx *gin.Context) {
+	var params transferApprovalParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req rejectTransferRequest
+	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	approval, valid := server.validTransferApproval(ctx, params.ID, db.PermissionManage)
+	if !valid {
+		return
+	}
+
+	if approval.Reason == db.ApprovalReasonFraudReview {
+		ctx.JSON(http.StatusForbidden, errorResponse(errFraudReview))
+		return
+	}
+
+	server.declineApproval(ctx, approval, req.Note)
+}
+
+// declineApproval rejects an approval on behalf of the authenticated user.
+func (server *Server) declineApproval(ctx *gin.Context, approval db.TransferApproval, note string) {
+	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
+	if approval.Initiator == authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrSelfApproval))
+		return
+	}
+
+	approval, err := server.store.RejectTransferApproval(ctx, db.RejectTransferApprovalParams{
+		ID:        approval.ID,
+		DecidedBy: authPayload.Username,
+		Note:      note,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(db.ErrApprovalNotPending))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, approval)
+}
+
+// validTransferApproval loads an approval and checks the authenticated
+// user's permission on its source account.
+func (server *Server) validTransferApproval(ctx *gin.Context, id int64, permission string) (db.TransferApproval, bool) {
+	approval, err := server.store.GetTransferApproval(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return approval, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return approval, false
+	}
+
+	if _, valid := server.getAuthorizedAccount(ctx, approval.FromAccountID, permission); !valid {
+		return approval, false
+	}
+
+	return approval, true
+}
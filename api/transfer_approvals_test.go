@@ -0,0 +1,430 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func TestCreateTransferAboveApprovalThreshold(t *testing.T) {
+	owner, _, _ := randomUser(t)
+	recipient, _, _ := randomUser(t)
+	fromAccount := randomAccount(owner.Username)
+	fromAccount.ID = 1
+	fromAccount.Currency = utils.USD
+	fromAccount.ApprovalThreshold = 1_000
+	toAccount := randomAccount(recipient.Username)
+	toAccount.ID = 2
+	toAccount.Currency = utils.USD
+
+	testCases := []struct {
+		name          string
+		amount        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "AboveThreshold",
+			amount: 1_001,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				expectNoPayee(store)
+				arg := db.CreateTransferApprovalTxParams{
+					Approval: db.CreateTransferApprovalParams{
+						FromAccountID: fromAccount.ID,
+						ToAccountID:   toAccount.ID,
+						Amount:        1_001,
+						Description:   "new laptops",
+						Initiator:     owner.Username,
+						Reason:        db.ApprovalReasonThreshold,
+					},
+				}
+				store.EXPECT().CreateTransferApprovalTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.TransferApproval{ID: 7, Status: db.TransferApprovalStatusPending}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Equal(t, "/transfer-approvals/7", recorder.Header().Get("Location"))
+
+				var got db.TransferApproval
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, int64(7), got.ID)
+				require.Equal(t, db.TransferApprovalStatusPending, got.Status)
+			},
+		},
+		{
+			name:   "AtThreshold",
+			amount: 1_000,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				expectNoPayee(store)
+				store.EXPECT().CreateTransferApprovalTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          tc.amount,
+				"currency":        utils.USD,
+				"description":     "new laptops",
+			})
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, owner.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestApproveTransferAPI(t *testing.T) {
+	owner, _, _ := randomUser(t)
+	clerk, _, _ := randomUser(t)
+	recipient, _, _ := randomUser(t)
+	fromAccount := randomAccount(owner.Username)
+	fromAccount.ID = 1
+	fromAccount.Currency = utils.USD
+	toAccount := randomAccount(recipient.Username)
+	toAccount.ID = 2
+	toAccount.Currency = utils.USD
+
+	approval := db.TransferApproval{
+		ID:            utils.RandomInt(1, 1000),
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        5_000,
+		Initiator:     clerk.Username,
+		Status:        db.TransferApprovalStatusPending,
+	}
+	ownedByOwner := approval
+	ownedByOwner.Initiator = owner.Username
+
+	clerkMember := db.AccountMember{AccountID: fromAccount.ID, Username: clerk.Username, Permission: db.PermissionTransact}
+
+	expectApproval := func(store *mockdb.MockStore, approval db.TransferApproval) {
+		store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(approval.ID)).Times(1).Return(approval, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	}
+	expectAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		approval      db.TransferApproval
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: owner.Username,
+			approval: approval,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectApproval(store, approval)
+				expectAccounts(store)
+				arg := db.ApproveTransferTxParams{ApprovalID: approval.ID, Approver: owner.Username}
+				store.EXPECT().ApproveTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.ApproveTransferTxResult{Approval: db.TransferApproval{Status: db.TransferApprovalStatusApproved}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "SelfApproval",
+			username: owner.Username,
+			approval: ownedByOwner,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectApproval(store, ownedByOwner)
+				store.EXPECT().ApproveTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "ApproverCannotManage",
+			username: clerk.Username,
+			approval: ownedByOwner,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectApproval(store, ownedByOwner)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(clerkMember, nil)
+				store.EXPECT().ApproveTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "AlreadyDecided",
+			username: owner.Username,
+			approval: approval,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectApproval(store, approval)
+				expectAccounts(store)
+				store.EXPECT().ApproveTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ApproveTransferTxResult{}, db.ErrApprovalNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "InsufficientFunds",
+			username: owner.Username,
+			approval: approval,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectApproval(store, approval)
+				expectAccounts(store)
+				store.EXPECT().ApproveTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ApproveTransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: owner.Username,
+			approval: approval,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(approval.ID)).Times(1).
+					Return(db.TransferApproval{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfer-approvals/%d/approve", tc.approval.ID)
+			req, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRejectTransferAPI(t *testing.T) {
+	owner, _, _ := randomUser(t)
+	clerk, _, _ := randomUser(t)
+	fromAccount := randomAccount(owner.Username)
+
+	approval := db.TransferApproval{
+		ID:            utils.RandomInt(1, 1000),
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   fromAccount.ID + 1,
+		Amount:        5_000,
+		Initiator:     clerk.Username,
+		Status:        db.TransferApprovalStatusPending,
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: owner.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(approval.ID)).Times(1).Return(approval, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				arg := db.RejectTransferApprovalParams{ID: approval.ID, DecidedBy: owner.Username, Note: "wrong supplier"}
+				store.EXPECT().RejectTransferApproval(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.TransferApproval{ID: approval.ID, Status: db.TransferApprovalStatusRejected, DecidedBy: owner.Username}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.TransferApproval
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, db.TransferApprovalStatusRejected, got.Status)
+				require.Equal(t, owner.Username, got.DecidedBy)
+			},
+		},
+		{
+			name:     "AlreadyDecided",
+			username: owner.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(approval.ID)).Times(1).Return(approval, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().RejectTransferApproval(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferApproval{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "Initiator",
+			username: clerk.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(approval.ID)).Times(1).Return(approval, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.AccountMember{Permission: db.PermissionManage}, nil)
+				store.EXPECT().RejectTransferApproval(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := createNewServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"note": "wrong supplier"})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/transfer-approvals/%d/reject", approval.ID)
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthHeader(t, req, server.tokenGenerator, authorizationType, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSetApprovalThresholdAPI(t *testing.T) {
+	owner, _, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	arg := db.SetApprovalThresholdParams{ID: account.ID, ApprovalThreshold: 10_000}
+	updated := account
+	updated.ApprovalThreshold = 10_000
+	store.EXPECT().SetApprovalThreshold(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+
+	server := createNewServer(t, store)
+	url := fmt.Sprintf("/accounts/%d/approval-threshold", account.ID)
+	for _, tc := range []struct {
+		threshold int64
+		code      int
+	}{
+		{10_000, http.StatusOK},
+		{-1, http.StatusBadRequest},
+	} {
+		data, err := json.Marshal(gin.H{"approval_threshold": tc.threshold})
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+		require.NoError(t, err)
+
+		addAuthHeader(t, req, server.tokenGenerator, authorizationType, owner.Username, time.Minute)
+		server.router.ServeHTTP(recorder, req)
+		require.Equal(t, tc.code, recorder.Code)
+	}
+}
+
+// TestSetApprovalThresholdNeedsSecondApprover checks that once an account
+// has a threshold, a change to it is only requested.
+func TestSetApprovalThresholdNeedsSecondApprover(t *testing.T) {
+	owner, _, _ := randomUser(t)
+	account := randomAccount(owner.Username)
+	account.ApprovalThreshold = 1_000
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	arg := db.CreateApprovalThresholdChangeParams{
+		AccountID:         account.ID,
+		ApprovalThreshold: 0,
+		RequestedBy:       owner.Username,
+	}
+	change := db.ApprovalThresholdChange{
+		ID:          utils.RandomInt(1, 1000),
+		AccountID:   account.ID,
+		RequestedBy: owner.Username,
+		Status:      db.ThresholdChangeStatusPending,
+	}
+	store.EXPECT().CreateApprovalThresholdChange(gomock.Any(), gomock.Eq(arg)).Times(1).Return(change, nil)
+	store.EXPECT().SetApprovalThreshold(gomock.Any(), gomock.Any()).Times(0)
+
+	server := createNewServer(t, store)
+	data, err := json.Marshal(gin.H{"approval_threshold": 0})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/accounts/%d/approval-threshold", account.ID)
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthHeader(t, req, server.tokenGenerator, authorizationType, owner.Username, time.Minute)
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	var got db.ApprovalThresholdChange
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	require.Equal(t, change.ID, got.ID)
+}
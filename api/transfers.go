@@ -2,62 +2,303 @@ package api
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/thanhphuocnguyen/go-simple-bank/auth"
+	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
 )
 
+// transferRequest identifies each account either by ID or by account number.
+// The destination may instead be one of the user's payees, or a recipient
+// alias, which resolves to the recipient's default account in Currency.
+// Amount and Currency are what leaves the source account; the destination
+// may hold another currency, in which case the amount is converted.
 type transferRequest struct {
-	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,currency"`
+	FromAccountID     int64  `json:"from_account_id" binding:"required_without=FromAccountNumber,omitempty,min=1"`
+	FromAccountNumber string `json:"from_account_number" binding:"omitempty,account_number"`
+	ToAccountID       int64  `json:"to_account_id" binding:"required_without_all=ToAccountNumber ToAlias PayeeID,omitempty,min=1"`
+	ToAccountNumber   string `json:"to_account_number" binding:"omitempty,account_number"`
+	ToAlias           string `json:"to_alias" binding:"omitempty,alias"`
+	PayeeID           int64  `json:"payee_id" binding:"omitempty,min=1"`
+	Amount            int64  `json:"amount" binding:"required,gt=0"`
+	Currency          string `json:"currency" binding:"required,currency"`
+	// Description is shown on both accounts' statements. ExternalReference
+	// is the caller's own identifier, e.g. an invoice number, and can be
+	// searched on. Metadata must be a JSON object.
+	Description       string          `json:"description" binding:"max=140"`
+	ExternalReference string          `json:"external_reference" binding:"max=64"`
+	Metadata          json.RawMessage `json:"metadata" binding:"omitempty,metadata"`
+	// paymentRequestID is set when the transfer pays a payment request, so
+	// that an approval parking it pays the request once approved.
+	paymentRequestID int64
 }
 
+// paymentRequest is the payment request an approval of req is linked to.
+func (req transferRequest) paymentRequest() pgtype.Int8 {
+	return pgtype.Int8{Int64: req.paymentRequestID, Valid: req.paymentRequestID > 0}
+}
+
+const preferHeader = "Prefer"
+
 func (server *Server) createTransfer(ctx *gin.Context) {
 	var req transferRequest
 	if err := ctx.ShouldBindBodyWithJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+
+	idempotency, valid := idempotencyParams(ctx, req)
+	if !valid {
+		return
+	}
+
+	if idempotency != nil && server.replayIdempotentResponse(ctx, *idempotency) {
+		return
+	}
+
+	fromAccount, valid := server.validAccountRef(ctx, req.FromAccountID, req.FromAccountNumber, req.Currency)
 	if !valid {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayload).(*auth.Payload)
-	if fromAccount.Owner != authPayload.Username {
-		err := errors.New("from account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if !server.authorizeAccount(ctx, fromAccount, db.PermissionTransact) {
 		return
 	}
 
-	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+	toAccount, valid := server.getRecipientRef(ctx, req, false)
 	if !valid {
 		return
 	}
 
+	if !server.screenTransfer(ctx, req, fromAccount, toAccount, idempotency) {
+		return
+	}
+
+	if fromAccount.RequiresApproval(req.Amount) {
+		server.requestTransferApproval(ctx, req, fromAccount, toAccount, idempotency)
+		return
+	}
+
 	arg := db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+		FromAccountID:     fromAccount.ID,
+		ToAccountID:       toAccount.ID,
+		Amount:            req.Amount,
+		Description:       req.Description,
+		ExternalReference: req.ExternalReference,
+		Metadata:          req.Metadata,
+		Idempotency:       idempotency,
+	}
+
+	if toAccount.Currency != fromAccount.Currency {
+		exchange, valid := server.exchange(ctx, req.Amount, fromAccount.Currency, toAccount.Currency)
+		if !valid {
+			return
+		}
+		arg.Exchange = &exchange
+	}
+
+	if respondAsync(ctx) {
+		recorded, err := server.store.CreatePendingTransferTx(ctx, arg)
+		if err != nil {
+			server.transferErrorResponse(ctx, idempotency, err)
+			return
+		}
+
+		ctx.Header("Location", fmt.Sprintf("/transfers/%d", recorded.ID))
+		ctx.JSON(http.StatusAccepted, recorded)
+		return
 	}
 
 	result, err := server.store.TransferTx(ctx, arg)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		server.transferErrorResponse(ctx, idempotency, err)
+		return
 	}
 
 	ctx.JSON(http.StatusOK, result)
 }
 
+// respondAsync reports whether the client sent "Prefer: respond-async"
+// (RFC 7240). Such a transfer is only recorded as pending and executed by
+// the transfer workers; the client polls GET /transfers/:id for its status.
+func respondAsync(ctx *gin.Context) bool {
+	for _, header := range ctx.Request.Header.Values(preferHeader) {
+		for _, preference := range strings.Split(header, ",") {
+			if strings.EqualFold(strings.TrimSpace(preference), "respond-async") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (server *Server) transferErrorResponse(ctx *gin.Context, idempotency *db.IdempotencyKeyParams, err error) {
+	// a concurrent retry with the same key committed first
+	if idempotency != nil && errors.Is(err, db.ErrDuplicateIdempotencyKey) && server.replayIdempotentResponse(ctx, *idempotency) {
+		return
+	}
+	storeErrorResponse(ctx, err)
+}
+
+type getTransferParams struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getTransfer shows a transfer to anyone who can view either account.
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var params getTransferParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, params.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		allowed, err := server.hasPermission(ctx, accountID, db.PermissionView)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if allowed {
+			ctx.JSON(http.StatusOK, transfer)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusUnauthorized, errorResponse(errNotAccountMember))
+}
+
+// listTransfersRequest lists an account's transfers in both directions,
+// optionally only those carrying an external reference.
+type listTransfersRequest struct {
+	AccountID int64  `form:"account_id" binding:"required,min=1"`
+	Reference string `form:"reference" binding:"max=64"`
+	Page      int32  `form:"page" binding:"required,min=1"`
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listTransfers(ctx *gin.Context) {
+	var req listTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.getAuthorizedAccount(ctx, req.AccountID, db.PermissionView)
+	if !valid {
+		return
+	}
+
+	var transfers []db.Transfer
+	var err error
+	if req.Reference == "" {
+		transfers, err = server.store.ListTransfers(ctx, db.ListTransfersParams{
+			FromAccountID: account.ID,
+			ToAccountID:   account.ID,
+			Limit:         req.PageSize,
+			Offset:        (req.Page - 1) * req.PageSize,
+		})
+	} else {
+		transfers, err = server.store.ListTransfersByReference(ctx, db.ListTransfersByReferenceParams{
+			AccountID:         account.ID,
+			ExternalReference: req.Reference,
+			Limit:             req.PageSize,
+			Offset:            (req.Page - 1) * req.PageSize,
+		})
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfers)
+}
+
 func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
 	account, err := server.store.GetAccount(ctx, accountID)
+	return checkAccount(ctx, account, err, currency)
+}
+
+// validAccountRef looks the account up by number when one is given and by
+// ID otherwise.
+func (server *Server) validAccountRef(ctx *gin.Context, accountID int64, accountNumber string, currency string) (db.Account, bool) {
+	if accountNumber == "" {
+		return server.validAccount(ctx, accountID, currency)
+	}
+
+	account, err := server.store.GetAccountByNumber(ctx, utils.NormalizeAccountNumber(accountNumber))
+	return checkAccount(ctx, account, err, currency)
+}
+
+// getRecipientRef resolves the destination of a transfer request. With
+// sameCurrency set, the destination must hold the request currency. However
+// the destination is named, an account that is one of the user's payees
+// still cooling off only receives up to PayeeCoolingOffLimit.
+func (server *Server) getRecipientRef(ctx *gin.Context, req transferRequest, sameCurrency bool) (db.Account, bool) {
+	accountID, accountNumber := req.ToAccountID, req.ToAccountNumber
+	switch {
+	case req.ToAlias != "":
+		_, account, valid := server.resolveAlias(ctx, req.ToAlias, req.Currency)
+		if !valid {
+			return account, false
+		}
+		return account, server.checkCoolingOff(ctx, account, req.Amount)
+	case req.PayeeID != 0:
+		payee, valid := server.validPayee(ctx, req.PayeeID)
+		if !valid {
+			return db.Account{}, false
+		}
+		accountID, accountNumber = payee.AccountID, ""
+	}
+
+	var account db.Account
+	var valid bool
+	if sameCurrency {
+		account, valid = server.validAccountRef(ctx, accountID, accountNumber, req.Currency)
+	} else {
+		account, valid = server.getAccountRef(ctx, accountID, accountNumber)
+	}
+	if !valid {
+		return account, false
+	}
+
+	// validPayee only accepts payees that have cooled off
+	if req.PayeeID != 0 {
+		return account, true
+	}
+	return account, server.checkCoolingOff(ctx, account, req.Amount)
+}
+
+// getAccountRef resolves an account like validAccountRef but accepts any
+// currency.
+func (server *Server) getAccountRef(ctx *gin.Context, accountID int64, accountNumber string) (db.Account, bool) {
+	var account db.Account
+	var err error
+	if accountNumber == "" {
+		account, err = server.store.GetAccount(ctx, accountID)
+	} else {
+		account, err = server.store.GetAccountByNumber(ctx, utils.NormalizeAccountNumber(accountNumber))
+	}
+	return checkAccount(ctx, account, err, account.Currency)
+}
 
+func checkAccount(ctx *gin.Context, account db.Account, err error, currency string) (db.Account, bool) {
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
@@ -69,7 +310,7 @@ func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency s
 	}
 
 	if account.Currency != currency {
-		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("account currency mismatch")))
+		ctx.JSON(http.StatusBadRequest, errorResponse(db.ErrCurrencyMismatch))
 		return account, false
 	}
 
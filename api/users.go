@@ -55,7 +55,7 @@ func (server *Server) createUser(ctx *gin.Context) {
 		FullName:       req.FullName,
 	}
 
-	user, err := server.store.CreateUser(ctx, arg)
+	user, err := server.store.CreateUserTx(ctx, arg)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
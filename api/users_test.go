@@ -74,7 +74,7 @@ func TestCreateUser(t *testing.T) {
 					FullName: user.FullName,
 				}
 				store.EXPECT().
-					CreateUser(gomock.Any(), UserParamsEq(arg, password)).
+					CreateUserTx(gomock.Any(), UserParamsEq(arg, password)).
 					Times(1).
 					Return(user, nil)
 			},
@@ -92,7 +92,7 @@ func TestCreateUser(t *testing.T) {
 				"full_name": user.FullName,
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrConnDone)
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrConnDone)
 			},
 			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -107,7 +107,7 @@ func TestCreateUser(t *testing.T) {
 				"full_name": user.FullName,
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, &pgconn.PgError{
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, &pgconn.PgError{
 					Code: "23505",
 				})
 			},
@@ -124,7 +124,7 @@ func TestCreateUser(t *testing.T) {
 				"full_name": user.FullName,
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -139,7 +139,7 @@ func TestCreateUser(t *testing.T) {
 				"full_name": user.FullName,
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -154,7 +154,7 @@ func TestCreateUser(t *testing.T) {
 				"full_name": user.FullName,
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -169,7 +169,7 @@ func TestCreateUser(t *testing.T) {
 				"full_name": user.FullName,
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			check: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -1,13 +1,50 @@
 package api
 
 import (
+	"encoding/json"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/thanhphuocnguyen/go-simple-bank/utils"
 )
 
-var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
-	if currency, err := fieldLevel.Field().Interface().(string); err {
-		return utils.IsSupportedCurrency(currency)
+func (server *Server) validCurrency(fieldLevel validator.FieldLevel) bool {
+	if currency, ok := fieldLevel.Field().Interface().(string); ok {
+		return server.currencies.IsEnabled(currency)
+	}
+	return false
+}
+
+var validAccountNumber validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if number, ok := fieldLevel.Field().Interface().(string); ok {
+		return utils.IsValidAccountNumber(number)
+	}
+	return false
+}
+
+var validAlias validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if alias, ok := fieldLevel.Field().Interface().(string); ok {
+		_, _, err := utils.NormalizeAlias(alias)
+		return err == nil
 	}
 	return false
 }
+
+var validSchedule validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if schedule, ok := fieldLevel.Field().Interface().(string); ok {
+		return utils.IsValidSchedule(schedule)
+	}
+	return false
+}
+
+const maxMetadataSize = 4096
+
+// validMetadata accepts a JSON object of at most maxMetadataSize bytes.
+var validMetadata validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	metadata, ok := fieldLevel.Field().Interface().(json.RawMessage)
+	if !ok || len(metadata) > maxMetadataSize {
+		return false
+	}
+
+	var object map[string]any
+	return json.Unmarshal(metadata, &object) == nil && object != nil
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "accounts_owner_type_idx";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "nickname";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "type";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_owner_currency_idx" UNIQUE ("owner", "currency");
@@ -0,0 +1,9 @@
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_owner_currency_idx";
+
+ALTER TABLE "accounts" ADD COLUMN "type" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "accounts" ADD COLUMN "nickname" varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON "accounts" ("owner", "type");
+
+COMMENT ON COLUMN "accounts"."type" IS 'checking or savings';
@@ -0,0 +1,14 @@
+ALTER TABLE "accounts" ADD COLUMN "account_number" varchar;
+
+-- give existing accounts a random number with mod 97 check digits ('SB' reads as 2811)
+UPDATE "accounts" AS a
+SET "account_number" = 'SB' || lpad((98 - ((n.bban || '281100')::numeric % 97))::text, 2, '0') || n.bban
+FROM (
+  SELECT "id", lpad(floor(random() * 1000000000000)::bigint::text, 12, '0') AS bban
+  FROM "accounts"
+) AS n
+WHERE a."id" = n."id";
+
+ALTER TABLE "accounts" ALTER COLUMN "account_number" SET NOT NULL;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_account_number_key" UNIQUE ("account_number");
@@ -0,0 +1,27 @@
+CREATE TABLE "fx_rates" (
+  "id" bigserial PRIMARY KEY,
+  "base_currency" varchar NOT NULL,
+  "quote_currency" varchar NOT NULL,
+  "rate" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "fx_rates" ("base_currency", "quote_currency", "created_at");
+
+COMMENT ON COLUMN "fx_rates"."rate" IS 'quote units per base unit, scaled by 1e8';
+
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+UPDATE "transfers" SET "to_amount" = "amount";
+
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ADD COLUMN "fx_rate" bigint NOT NULL DEFAULT 100000000;
+
+ALTER TABLE "transfers" ADD COLUMN "spread_bps" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited in the destination currency';
+
+COMMENT ON COLUMN "transfers"."fx_rate" IS 'scaled by 1e8, 1e8 for same-currency transfers';
+
+COMMENT ON COLUMN "transfers"."spread_bps" IS 'spread applied to the rate in basis points';
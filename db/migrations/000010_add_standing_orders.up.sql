@@ -0,0 +1,36 @@
+CREATE TABLE "standing_orders" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "schedule" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "next_run_at" timestamptz NOT NULL,
+  "end_at" timestamptz,
+  "max_runs" integer,
+  "runs" integer NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "standing_orders" ("username");
+
+CREATE INDEX ON "standing_orders" ("status", "next_run_at");
+
+COMMENT ON COLUMN "standing_orders"."amount" IS 'it must be pos num';
+
+COMMENT ON COLUMN "standing_orders"."schedule" IS 'e.g. weekly:mon, monthly:15 or monthly:last-business-day';
+
+COMMENT ON COLUMN "standing_orders"."status" IS 'active, paused or completed';
+
+ALTER TABLE "standing_orders" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "standing_orders" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "standing_orders" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD COLUMN "standing_order_id" bigint;
+
+CREATE INDEX ON "scheduled_transfers" ("standing_order_id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("standing_order_id") REFERENCES "standing_orders" ("id");
@@ -0,0 +1,5 @@
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "original_transfer_id";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "kind";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "role";
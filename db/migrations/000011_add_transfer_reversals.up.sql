@@ -0,0 +1,15 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'depositor';
+
+COMMENT ON COLUMN "users"."role" IS 'depositor or banker';
+
+ALTER TABLE "transfers" ADD COLUMN "kind" varchar NOT NULL DEFAULT 'transfer';
+
+ALTER TABLE "transfers" ADD COLUMN "original_transfer_id" bigint;
+
+CREATE INDEX ON "transfers" ("original_transfer_id");
+
+COMMENT ON COLUMN "transfers"."kind" IS 'transfer, reversal or refund';
+
+COMMENT ON COLUMN "transfers"."original_transfer_id" IS 'the transfer a reversal or refund compensates';
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("original_transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,41 @@
+CREATE TABLE "transfer_batches" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "allow_partial" boolean NOT NULL DEFAULT false,
+  "status" varchar NOT NULL DEFAULT 'processing',
+  "succeeded" integer NOT NULL DEFAULT 0,
+  "failed" integer NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_batch_items" (
+  "id" bigserial PRIMARY KEY,
+  "batch_id" bigint NOT NULL,
+  "row_number" integer NOT NULL,
+  "to_account" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "memo" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL,
+  "error" varchar NOT NULL DEFAULT '',
+  "transfer_id" bigint
+);
+
+CREATE INDEX ON "transfer_batches" ("username");
+
+CREATE UNIQUE INDEX ON "transfer_batch_items" ("batch_id", "row_number");
+
+COMMENT ON COLUMN "transfer_batches"."status" IS 'processing, completed, partial or failed';
+
+COMMENT ON COLUMN "transfer_batch_items"."to_account" IS 'account number as submitted';
+
+COMMENT ON COLUMN "transfer_batch_items"."status" IS 'succeeded or failed';
+
+ALTER TABLE "transfer_batches" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_batches" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("batch_id") REFERENCES "transfer_batches" ("id");
+
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,19 @@
+ALTER TABLE "standing_orders" DROP COLUMN IF EXISTS "metadata";
+
+ALTER TABLE "standing_orders" DROP COLUMN IF EXISTS "external_reference";
+
+ALTER TABLE "standing_orders" DROP COLUMN IF EXISTS "description";
+
+ALTER TABLE "scheduled_transfers" DROP COLUMN IF EXISTS "metadata";
+
+ALTER TABLE "scheduled_transfers" DROP COLUMN IF EXISTS "external_reference";
+
+ALTER TABLE "scheduled_transfers" DROP COLUMN IF EXISTS "description";
+
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "description";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "metadata";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "external_reference";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "description";
@@ -0,0 +1,25 @@
+ALTER TABLE "transfers" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "external_reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "metadata" jsonb;
+
+ALTER TABLE "entries" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "scheduled_transfers" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "scheduled_transfers" ADD COLUMN "external_reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "scheduled_transfers" ADD COLUMN "metadata" jsonb;
+
+ALTER TABLE "standing_orders" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "standing_orders" ADD COLUMN "external_reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "standing_orders" ADD COLUMN "metadata" jsonb;
+
+CREATE INDEX ON "transfers" ("external_reference");
+
+COMMENT ON COLUMN "transfers"."external_reference" IS 'caller supplied reference, e.g. an invoice number';
+
+COMMENT ON COLUMN "transfers"."metadata" IS 'caller supplied JSON object';
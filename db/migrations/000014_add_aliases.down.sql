@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "aliases";
+
+DROP INDEX IF EXISTS "accounts_owner_currency_idx";
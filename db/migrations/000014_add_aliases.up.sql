@@ -0,0 +1,19 @@
+CREATE TABLE "aliases" (
+  "alias" varchar PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "username" varchar NOT NULL,
+  "verified_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "aliases" ("username");
+
+CREATE INDEX ON "accounts" ("owner", "currency");
+
+COMMENT ON COLUMN "aliases"."alias" IS 'username, lower-cased email or E.164 phone number';
+
+COMMENT ON COLUMN "aliases"."kind" IS 'username, email or phone';
+
+COMMENT ON COLUMN "aliases"."verified_at" IS 'unverified aliases do not resolve';
+
+ALTER TABLE "aliases" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
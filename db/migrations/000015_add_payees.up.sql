@@ -0,0 +1,17 @@
+CREATE TABLE "payees" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "nickname" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "cooling_off_until" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "payees" ("username", "account_id");
+
+COMMENT ON COLUMN "payees"."cooling_off_until" IS 'transfers to the payee are capped at a lower limit until then';
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
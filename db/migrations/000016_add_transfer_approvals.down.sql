@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "transfer_approvals";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "approval_threshold";
@@ -0,0 +1,36 @@
+ALTER TABLE "accounts" ADD COLUMN "approval_threshold" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "accounts"."approval_threshold" IS 'transfers above it need a second approver, 0 disables approvals';
+
+CREATE TABLE "transfer_approvals" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "external_reference" varchar NOT NULL DEFAULT '',
+  "metadata" jsonb,
+  "initiator" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "decided_by" varchar NOT NULL DEFAULT '',
+  "decided_at" timestamptz,
+  "note" varchar NOT NULL DEFAULT '',
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "transfer_approvals" ("from_account_id", "status");
+
+COMMENT ON COLUMN "transfer_approvals"."amount" IS 'it must be pos num';
+
+COMMENT ON COLUMN "transfer_approvals"."status" IS 'pending, approved or rejected';
+
+COMMENT ON COLUMN "transfer_approvals"."decided_by" IS 'the approver who approved or rejected the transfer';
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("initiator") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,19 @@
+CREATE TABLE "fee_schedules" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "min_amount" bigint NOT NULL DEFAULT 0,
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "percentage_bps" bigint NOT NULL DEFAULT 0,
+  "fee_account_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "fee_schedules" ("currency", "min_amount");
+
+COMMENT ON COLUMN "fee_schedules"."min_amount" IS 'the tier covers amounts from here up to the next tier';
+
+COMMENT ON COLUMN "fee_schedules"."percentage_bps" IS 'charged on top of the flat fee, in basis points of the amount';
+
+COMMENT ON COLUMN "fee_schedules"."fee_account_id" IS 'system account credited with the fee, in the same currency';
+
+ALTER TABLE "fee_schedules" ADD FOREIGN KEY ("fee_account_id") REFERENCES "accounts" ("id");
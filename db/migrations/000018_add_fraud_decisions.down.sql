@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "fraud_decisions";
+
+DROP INDEX IF EXISTS "transfers_from_account_id_created_at_idx";
+
+ALTER TABLE "transfer_approvals" DROP COLUMN IF EXISTS "reason";
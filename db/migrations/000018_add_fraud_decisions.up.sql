@@ -0,0 +1,34 @@
+ALTER TABLE "transfer_approvals" ADD COLUMN "reason" varchar NOT NULL DEFAULT 'threshold';
+
+COMMENT ON COLUMN "transfer_approvals"."reason" IS 'threshold, or fraud_review when a fraud rule held the transfer';
+
+CREATE TABLE "fraud_decisions" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "outcome" varchar NOT NULL,
+  "rule" varchar NOT NULL DEFAULT '',
+  "reason" varchar NOT NULL DEFAULT '',
+  "approval_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "fraud_decisions" ("from_account_id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+COMMENT ON COLUMN "fraud_decisions"."outcome" IS 'allow, block or hold';
+
+COMMENT ON COLUMN "fraud_decisions"."rule" IS 'the rule that fired, empty when the transfer was allowed';
+
+COMMENT ON COLUMN "fraud_decisions"."approval_id" IS 'the approval a held transfer waits on';
+
+ALTER TABLE "fraud_decisions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "fraud_decisions" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "fraud_decisions" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "fraud_decisions" ADD FOREIGN KEY ("approval_id") REFERENCES "transfer_approvals" ("id");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "escrows";
+
+DROP TABLE IF EXISTS "escrow_accounts";
@@ -0,0 +1,56 @@
+CREATE TABLE "escrow_accounts" (
+  "currency" varchar PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "escrows" (
+  "id" bigserial PRIMARY KEY,
+  "buyer_account_id" bigint NOT NULL,
+  "seller_account_id" bigint NOT NULL,
+  "escrow_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "created_by" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'held',
+  "timeout_at" timestamptz NOT NULL,
+  "timeout_action" varchar NOT NULL,
+  "fund_transfer_id" bigint,
+  "settle_transfer_id" bigint,
+  "settled_by" varchar NOT NULL DEFAULT '',
+  "settled_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "escrows" ("buyer_account_id");
+
+CREATE INDEX ON "escrows" ("seller_account_id");
+
+CREATE INDEX ON "escrows" ("status", "timeout_at");
+
+COMMENT ON COLUMN "escrow_accounts"."account_id" IS 'system account that holds escrowed funds in the currency';
+
+COMMENT ON COLUMN "escrows"."status" IS 'held, released or refunded';
+
+COMMENT ON COLUMN "escrows"."timeout_action" IS 'release or refund, applied when the escrow is still held at timeout_at';
+
+COMMENT ON COLUMN "escrows"."fund_transfer_id" IS 'the transfer from the buyer into the escrow account';
+
+COMMENT ON COLUMN "escrows"."settle_transfer_id" IS 'the transfer out of the escrow account to the seller or back to the buyer';
+
+COMMENT ON COLUMN "escrows"."settled_by" IS 'who released or refunded the escrow, empty when it timed out';
+
+ALTER TABLE "escrow_accounts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("buyer_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("seller_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("escrow_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("fund_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("settle_transfer_id") REFERENCES "transfers" ("id");
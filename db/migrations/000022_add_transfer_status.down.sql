@@ -0,0 +1,11 @@
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "failure_reason";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "reversed_at";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "failed_at";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "completed_at";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "processing_at";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "status";
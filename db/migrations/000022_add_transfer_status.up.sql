@@ -0,0 +1,28 @@
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'completed';
+
+ALTER TABLE "transfers" ADD COLUMN "processing_at" timestamptz;
+
+ALTER TABLE "transfers" ADD COLUMN "completed_at" timestamptz;
+
+ALTER TABLE "transfers" ADD COLUMN "failed_at" timestamptz;
+
+ALTER TABLE "transfers" ADD COLUMN "reversed_at" timestamptz;
+
+ALTER TABLE "transfers" ADD COLUMN "failure_reason" varchar NOT NULL DEFAULT '';
+
+UPDATE "transfers" SET "completed_at" = "created_at";
+
+UPDATE "transfers" SET "status" = 'reversed', "reversed_at" = c."reversed_at"
+FROM (
+  SELECT "original_transfer_id", SUM("amount") AS "compensated", MAX("created_at") AS "reversed_at"
+  FROM "transfers"
+  WHERE "original_transfer_id" IS NOT NULL
+  GROUP BY "original_transfer_id"
+) c
+WHERE c."original_transfer_id" = "transfers"."id" AND c."compensated" >= "transfers"."to_amount";
+
+CREATE INDEX ON "transfers" ("status", "id");
+
+COMMENT ON COLUMN "transfers"."status" IS 'pending, processing, completed, failed or reversed';
+
+COMMENT ON COLUMN "transfers"."failure_reason" IS 'why an asynchronous transfer failed';
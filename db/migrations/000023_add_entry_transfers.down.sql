@@ -0,0 +1,3 @@
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "transfer_id";
+
+COMMENT ON COLUMN "entries"."journal_id" IS 'the posting the entry belongs to, empty for transfer entries';
@@ -0,0 +1,36 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'the transfer the entry is a leg of, empty for posting and fee entries';
+
+COMMENT ON COLUMN "entries"."journal_id" IS 'the posting or transfer fee the entry belongs to, empty for transfer entries';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+-- A transfer and its entries were written in one transaction, so they share
+-- its created_at. Each existing leg is linked to the only transfer it
+-- matches; a leg matching several is left for reconciliation to report.
+UPDATE "entries" SET "transfer_id" = m."transfer_id"
+FROM (
+  SELECT e."id" AS "entry_id", MIN(t."id") AS "transfer_id"
+  FROM "entries" e
+  JOIN "transfers" t ON t."created_at" = e."created_at"
+    AND ((e."account_id" = t."from_account_id" AND e."amount" = -t."amount")
+      OR (e."account_id" = t."to_account_id" AND e."amount" = t."to_amount"))
+  WHERE e."journal_id" IS NULL AND e."description" NOT LIKE 'fee for transfer %'
+  GROUP BY e."id"
+  HAVING COUNT(*) = 1
+) m
+WHERE "entries"."id" = m."entry_id";
+
+-- Fees are now posted as a journal of their own.
+INSERT INTO "journals" ("description", "created_at")
+SELECT DISTINCT "description", "created_at" FROM "entries"
+WHERE "journal_id" IS NULL AND "transfer_id" IS NULL AND "description" LIKE 'fee for transfer %';
+
+UPDATE "entries" SET "journal_id" = j."id"
+FROM "journals" j
+WHERE "entries"."journal_id" IS NULL AND "entries"."transfer_id" IS NULL
+  AND "entries"."description" LIKE 'fee for transfer %'
+  AND j."description" = "entries"."description" AND j."created_at" = "entries"."created_at";
@@ -0,0 +1,23 @@
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "version" integer NOT NULL,
+  "payload" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "outbox_relays" (
+  "name" varchar PRIMARY KEY,
+  "position" bigint NOT NULL DEFAULT 0,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "outbox"."id" IS 'publish order, writers hold an advisory lock so ids commit in order';
+
+COMMENT ON COLUMN "outbox"."aggregate_type" IS 'user, account or transfer';
+
+COMMENT ON COLUMN "outbox"."version" IS 'version of the payload schema of event_type';
+
+COMMENT ON COLUMN "outbox_relays"."position" IS 'id of the last event the relay published';
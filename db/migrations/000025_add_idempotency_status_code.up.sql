@@ -0,0 +1,3 @@
+ALTER TABLE "idempotency_keys" ADD COLUMN "status_code" integer NOT NULL DEFAULT 200;
+
+COMMENT ON COLUMN "idempotency_keys"."status_code" IS 'HTTP status of the stored response, 202 for async transfers';
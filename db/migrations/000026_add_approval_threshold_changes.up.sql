@@ -0,0 +1,20 @@
+CREATE TABLE "approval_threshold_changes" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "approval_threshold" bigint NOT NULL,
+  "requested_by" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "decided_by" varchar NOT NULL DEFAULT '',
+  "decided_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "approval_threshold_changes" ("account_id", "status");
+
+COMMENT ON COLUMN "approval_threshold_changes"."approval_threshold" IS 'the threshold the account gets once the change is approved';
+
+COMMENT ON COLUMN "approval_threshold_changes"."status" IS 'pending, approved or rejected';
+
+ALTER TABLE "approval_threshold_changes" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "approval_threshold_changes" ADD FOREIGN KEY ("requested_by") REFERENCES "users" ("username");
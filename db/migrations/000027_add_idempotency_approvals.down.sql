@@ -0,0 +1,3 @@
+DELETE FROM "idempotency_keys" WHERE "transfer_id" IS NULL;
+ALTER TABLE "idempotency_keys" DROP COLUMN IF EXISTS "approval_id";
+ALTER TABLE "idempotency_keys" ALTER COLUMN "transfer_id" SET NOT NULL;
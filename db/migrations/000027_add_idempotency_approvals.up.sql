@@ -0,0 +1,6 @@
+ALTER TABLE "idempotency_keys" ALTER COLUMN "transfer_id" DROP NOT NULL;
+ALTER TABLE "idempotency_keys" ADD COLUMN "approval_id" bigint;
+
+COMMENT ON COLUMN "idempotency_keys"."approval_id" IS 'set instead of transfer_id when the transfer was parked for approval';
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("approval_id") REFERENCES "transfer_approvals" ("id");
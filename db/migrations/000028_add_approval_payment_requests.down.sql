@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "transfer_approvals_payment_request_id_idx";
+
+ALTER TABLE "transfer_approvals" DROP COLUMN IF EXISTS "payment_request_id";
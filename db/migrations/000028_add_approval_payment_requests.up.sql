@@ -0,0 +1,7 @@
+ALTER TABLE "transfer_approvals" ADD COLUMN "payment_request_id" bigint;
+
+CREATE UNIQUE INDEX ON "transfer_approvals" ("payment_request_id") WHERE "status" = 'pending';
+
+COMMENT ON COLUMN "transfer_approvals"."payment_request_id" IS 'the payment request the transfer pays, marked paid when it is approved';
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("payment_request_id") REFERENCES "payment_requests" ("id");
@@ -0,0 +1,3 @@
+COMMENT ON COLUMN "aliases"."alias" IS 'username, lower-cased email or E.164 phone number';
+
+COMMENT ON COLUMN "aliases"."kind" IS 'username, email or phone';
@@ -0,0 +1,5 @@
+DELETE FROM "aliases" WHERE "kind" = 'username';
+
+COMMENT ON COLUMN "aliases"."alias" IS 'lower-cased email or E.164 phone number';
+
+COMMENT ON COLUMN "aliases"."kind" IS 'email or phone';
@@ -1,32 +1,36 @@
 -- name: CreateAccount :one
 INSERT INTO accounts  (
-    owner, balance, currency
+    owner, balance, currency, type, nickname, account_number
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: GetAccount :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
 
+-- name: GetAccountByNumber :one
+SELECT * FROM accounts
+WHERE account_number = $1 LIMIT 1;
+
 -- name: GetAccountForUpdate :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
+-- name: CountAccountsByType :one
+SELECT COUNT(*) FROM accounts
+WHERE owner = $1 AND type = $2;
+
 -- name: ListAccounts :many
 SELECT * FROM accounts
-WHERE owner = $1
+WHERE owner = $1 OR id IN (
+    SELECT account_id FROM account_members WHERE username = $1
+)
 ORDER BY id
 LIMIT $2
 OFFSET $3;
 
--- name: UpdateAccount :one
-UPDATE accounts
-  set balance = $2
-WHERE id = $1
-RETURNING *;
-
 -- name: AddAccountBalance :one
 UPDATE accounts
   set balance = balance + sqlc.arg(amount)
@@ -35,4 +39,15 @@ RETURNING *;
 
 -- name: DeleteAccount :exec
 DELETE FROM accounts
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+-- name: GetDefaultAccount :one
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2
+ORDER BY type = 'checking' DESC, id
+LIMIT 1;
+
+-- name: SetApprovalThreshold :one
+UPDATE accounts
+  set approval_threshold = $2
+WHERE id = $1
+RETURNING *;
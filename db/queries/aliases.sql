@@ -0,0 +1,29 @@
+-- name: CreateAlias :one
+INSERT INTO aliases (
+    alias, kind, username, verified_at
+) VALUES (
+    $1, $2, $3, $4
+) ON CONFLICT (alias) DO UPDATE
+  set kind = EXCLUDED.kind, username = EXCLUDED.username, verified_at = EXCLUDED.verified_at, created_at = now()
+WHERE aliases.verified_at IS NULL AND EXCLUDED.verified_at IS NOT NULL
+RETURNING *;
+
+-- name: GetAlias :one
+SELECT * FROM aliases
+WHERE alias = $1 LIMIT 1;
+
+-- name: ListAliases :many
+SELECT * FROM aliases
+WHERE username = $1
+ORDER BY created_at;
+
+-- name: VerifyAlias :one
+UPDATE aliases
+  set verified_at = now()
+WHERE alias = $1 AND verified_at IS NULL
+RETURNING *;
+
+-- name: DeleteAlias :one
+DELETE FROM aliases
+WHERE alias = $1 AND username = $2
+RETURNING *;
@@ -0,0 +1,28 @@
+-- name: CreateApprovalThresholdChange :one
+INSERT INTO approval_threshold_changes (
+    account_id, approval_threshold, requested_by
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetApprovalThresholdChange :one
+SELECT * FROM approval_threshold_changes
+WHERE id = $1 LIMIT 1;
+
+-- name: GetApprovalThresholdChangeForUpdate :one
+SELECT * FROM approval_threshold_changes
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListApprovalThresholdChanges :many
+SELECT * FROM approval_threshold_changes
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: DecideApprovalThresholdChange :one
+UPDATE approval_threshold_changes
+  set status = $2, decided_by = $3, decided_at = now()
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
@@ -1,8 +1,22 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
-    amount, account_id
+    amount, account_id, description
 ) VALUES (
-    $1, $2
+    $1, $2, $3
+) RETURNING *;
+
+-- name: CreateJournalEntry :one
+INSERT INTO entries (
+    amount, account_id, description, journal_id
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: CreateTransferEntry :one
+INSERT INTO entries (
+    amount, account_id, description, transfer_id
+) VALUES (
+    $1, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -13,4 +27,9 @@ SELECT * FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
-OFFSET $3;
\ No newline at end of file
+OFFSET $3;
+
+-- name: ListJournalEntries :many
+SELECT * FROM entries
+WHERE journal_id = $1
+ORDER BY id;
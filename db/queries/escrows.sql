@@ -0,0 +1,53 @@
+-- name: CreateEscrowAccount :one
+INSERT INTO escrow_accounts (
+    currency, account_id
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: GetEscrowAccount :one
+SELECT * FROM escrow_accounts
+WHERE currency = $1 LIMIT 1;
+
+-- name: CreateEscrow :one
+INSERT INTO escrows (
+    buyer_account_id, seller_account_id, escrow_account_id, amount, currency,
+    description, created_by, timeout_at, timeout_action
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetEscrow :one
+SELECT * FROM escrows
+WHERE id = $1 LIMIT 1;
+
+-- name: GetEscrowForUpdate :one
+SELECT * FROM escrows
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListEscrows :many
+SELECT * FROM escrows
+WHERE buyer_account_id = @account_id OR seller_account_id = @account_id
+ORDER BY id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ClaimTimedOutEscrow :one
+SELECT * FROM escrows
+WHERE status = 'held' AND timeout_at <= now()
+ORDER BY timeout_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: SetEscrowFundTransfer :one
+UPDATE escrows
+  set fund_transfer_id = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: SettleEscrow :one
+UPDATE escrows
+  set status = $2, settle_transfer_id = $3, settled_by = $4, settled_at = now()
+WHERE id = $1 AND status = 'held'
+RETURNING *;
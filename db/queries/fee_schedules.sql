@@ -0,0 +1,12 @@
+-- name: CreateFeeSchedule :one
+INSERT INTO fee_schedules (
+    currency, min_amount, flat_fee, percentage_bps, fee_account_id
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetFeeSchedule :one
+SELECT * FROM fee_schedules
+WHERE currency = @currency AND min_amount <= @amount
+ORDER BY min_amount DESC
+LIMIT 1;
@@ -0,0 +1,13 @@
+-- name: CreateFraudDecision :one
+INSERT INTO fraud_decisions (
+    username, from_account_id, to_account_id, amount, outcome, rule, reason, approval_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: ListFraudDecisions :many
+SELECT * FROM fraud_decisions
+WHERE from_account_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
@@ -0,0 +1,12 @@
+-- name: CreateFxRate :one
+INSERT INTO fx_rates (
+    base_currency, quote_currency, rate
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetLatestFxRate :one
+SELECT * FROM fx_rates
+WHERE base_currency = $1 AND quote_currency = $2
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
@@ -0,0 +1,31 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+    account_id, amount, reference, expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetActiveHoldsAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM holds
+WHERE account_id = $1 AND status = 'active' AND expires_at > now();
+
+-- name: CaptureHold :one
+UPDATE holds
+  set status = 'captured', transfer_id = $2
+WHERE id = $1 AND status = 'active'
+RETURNING *;
+
+-- name: ReleaseHold :one
+UPDATE holds
+  set status = 'released'
+WHERE id = $1 AND status = 'active'
+RETURNING *;
@@ -0,0 +1,10 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+    username, key, request_hash, response, transfer_id, status_code, approval_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE username = $1 AND key = $2 LIMIT 1;
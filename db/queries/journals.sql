@@ -0,0 +1,10 @@
+-- name: CreateJournal :one
+INSERT INTO journals (
+    description
+) VALUES (
+    $1
+) RETURNING *;
+
+-- name: GetJournal :one
+SELECT * FROM journals
+WHERE id = $1 LIMIT 1;
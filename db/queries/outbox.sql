@@ -0,0 +1,27 @@
+-- name: LockOutbox :exec
+SELECT pg_advisory_xact_lock(hashtext('outbox'));
+
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+    aggregate_type, aggregate_id, event_type, version, payload
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListOutboxEvents :many
+SELECT * FROM outbox
+WHERE id > @after_id
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: GetOutboxPosition :one
+SELECT position FROM outbox_relays
+WHERE name = $1;
+
+-- name: SaveOutboxPosition :exec
+INSERT INTO outbox_relays (
+    name, position
+) VALUES (
+    $1, $2
+) ON CONFLICT (name) DO UPDATE
+  set position = GREATEST(outbox_relays.position, EXCLUDED.position), updated_at = now();
@@ -0,0 +1,26 @@
+-- name: CreatePayee :one
+INSERT INTO payees (
+    username, nickname, account_id, currency, cooling_off_until
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetPayee :one
+SELECT * FROM payees
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPayeeByAccount :one
+SELECT * FROM payees
+WHERE username = $1 AND account_id = $2 LIMIT 1;
+
+-- name: ListPayees :many
+SELECT * FROM payees
+WHERE username = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: DeletePayee :one
+DELETE FROM payees
+WHERE id = $1 AND username = $2
+RETURNING *;
@@ -0,0 +1,47 @@
+-- name: CreatePaymentRequest :one
+INSERT INTO payment_requests (
+    requester, payer, to_account_id, amount, currency, description, expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetPaymentRequest :one
+SELECT * FROM payment_requests
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPaymentRequestForUpdate :one
+SELECT * FROM payment_requests
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListIncomingPaymentRequests :many
+SELECT * FROM payment_requests
+WHERE payer = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListOutgoingPaymentRequests :many
+SELECT * FROM payment_requests
+WHERE requester = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: PayPaymentRequest :one
+UPDATE payment_requests
+  set status = 'paid', transfer_id = $2, decided_at = now()
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: DeclinePaymentRequest :one
+UPDATE payment_requests
+  set status = 'declined', decided_at = now()
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: CancelPaymentRequest :one
+UPDATE payment_requests
+  set status = 'cancelled', decided_at = now()
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
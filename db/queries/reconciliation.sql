@@ -0,0 +1,40 @@
+-- name: ListBalanceDrifts :many
+SELECT a.id, a.currency, a.balance, COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: ListOrphanedEntries :many
+SELECT * FROM entries
+WHERE transfer_id IS NULL AND journal_id IS NULL
+ORDER BY id;
+
+-- name: ListUnbalancedTransfers :many
+SELECT t.id, t.status, t.from_account_id, t.to_account_id, t.amount, t.to_amount,
+    COUNT(e.id) AS entry_count,
+    COUNT(e.id) FILTER (WHERE e.account_id = t.from_account_id AND e.amount = -t.amount) AS debit_count,
+    COUNT(e.id) FILTER (WHERE e.account_id = t.to_account_id AND e.amount = t.to_amount) AS credit_count
+FROM transfers t
+LEFT JOIN entries e ON e.transfer_id = t.id
+GROUP BY t.id
+HAVING CASE WHEN t.status IN ('completed', 'reversed')
+    THEN COUNT(e.id) <> 2
+        OR COUNT(e.id) FILTER (WHERE e.account_id = t.from_account_id AND e.amount = -t.amount) <> 1
+        OR COUNT(e.id) FILTER (WHERE e.account_id = t.to_account_id AND e.amount = t.to_amount) <> 1
+    ELSE COUNT(e.id) <> 0
+END
+ORDER BY t.id;
+
+-- name: ListCurrencyImbalances :many
+SELECT a.currency, SUM(e.amount)::bigint AS total
+FROM entries e
+JOIN accounts a ON a.id = e.account_id
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts fa ON fa.id = t.from_account_id
+LEFT JOIN accounts ta ON ta.id = t.to_account_id
+WHERE t.id IS NULL OR fa.currency = ta.currency
+GROUP BY a.currency
+HAVING SUM(e.amount) <> 0
+ORDER BY a.currency;
@@ -0,0 +1,50 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+    username, from_account_id, to_account_id, amount, execute_at, next_attempt_at, standing_order_id,
+    description, external_reference, metadata
+) VALUES (
+    $1, $2, $3, $4, $5, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE username = $1
+ORDER BY execute_at, id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListStandingOrderRuns :many
+SELECT * FROM scheduled_transfers
+WHERE standing_order_id = $1
+ORDER BY execute_at DESC, id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ClaimDueScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE status = 'pending' AND next_attempt_at <= now()
+ORDER BY next_attempt_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: CompleteScheduledTransfer :one
+UPDATE scheduled_transfers
+  set status = 'completed', attempts = attempts + 1, failure_reason = '', transfer_id = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: FailScheduledTransfer :one
+UPDATE scheduled_transfers
+  set status = $2, attempts = attempts + 1, failure_reason = $3, next_attempt_at = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: CancelScheduledTransfer :one
+UPDATE scheduled_transfers
+  set status = 'cancelled'
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
@@ -0,0 +1,49 @@
+-- name: CreateStandingOrder :one
+INSERT INTO standing_orders (
+    username, from_account_id, to_account_id, amount, schedule, next_run_at, end_at, max_runs,
+    description, external_reference, metadata
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING *;
+
+-- name: GetStandingOrder :one
+SELECT * FROM standing_orders
+WHERE id = $1 LIMIT 1;
+
+-- name: ListStandingOrders :many
+SELECT * FROM standing_orders
+WHERE username = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ClaimDueStandingOrder :one
+SELECT * FROM standing_orders
+WHERE status = 'active' AND next_run_at <= now()
+ORDER BY next_run_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: AdvanceStandingOrder :one
+UPDATE standing_orders
+  set runs = runs + 1, next_run_at = $2, status = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: CompleteStandingOrder :one
+UPDATE standing_orders
+  set status = 'completed'
+WHERE id = $1
+RETURNING *;
+
+-- name: PauseStandingOrder :one
+UPDATE standing_orders
+  set status = 'paused'
+WHERE id = $1 AND status = 'active'
+RETURNING *;
+
+-- name: ResumeStandingOrder :one
+UPDATE standing_orders
+  set status = 'active', next_run_at = $2
+WHERE id = $1 AND status = 'paused'
+RETURNING *;
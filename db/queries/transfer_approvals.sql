@@ -0,0 +1,34 @@
+-- name: CreateTransferApproval :one
+INSERT INTO transfer_approvals (
+    from_account_id, to_account_id, amount, description, external_reference, metadata, initiator, reason, payment_request_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetTransferApproval :one
+SELECT * FROM transfer_approvals
+WHERE id = $1 LIMIT 1;
+
+-- name: GetTransferApprovalForUpdate :one
+SELECT * FROM transfer_approvals
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListTransferApprovals :many
+SELECT * FROM transfer_approvals
+WHERE from_account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ApproveTransferApproval :one
+UPDATE transfer_approvals
+  set status = 'approved', decided_by = $2, decided_at = now(), transfer_id = $3
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: RejectTransferApproval :one
+UPDATE transfer_approvals
+  set status = 'rejected', decided_by = $2, decided_at = now(), note = $3
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
@@ -0,0 +1,28 @@
+-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches (
+    username, from_account_id, allow_partial
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetTransferBatch :one
+SELECT * FROM transfer_batches
+WHERE id = $1 LIMIT 1;
+
+-- name: FinishTransferBatch :one
+UPDATE transfer_batches
+  set status = $2, succeeded = $3, failed = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateTransferBatchItem :one
+INSERT INTO transfer_batch_items (
+    batch_id, row_number, to_account, amount, currency, memo, status, error, transfer_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: ListTransferBatchItems :many
+SELECT * FROM transfer_batch_items
+WHERE batch_id = $1
+ORDER BY row_number;
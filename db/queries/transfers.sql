@@ -1,16 +1,92 @@
 -- name: CreateTransfer :one
 INSERT INTO transfers (
-    amount, from_account_id, to_account_id
+    amount, from_account_id, to_account_id, to_amount, fx_rate, spread_bps,
+    description, external_reference, metadata, status, completed_at
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, 'completed', now()
+) RETURNING *;
+
+-- name: CreatePendingTransfer :one
+INSERT INTO transfers (
+    amount, from_account_id, to_account_id, to_amount, fx_rate, spread_bps,
+    description, external_reference, metadata, status
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, 'pending'
+) RETURNING *;
+
+-- name: CreateCompensatingTransfer :one
+INSERT INTO transfers (
+    amount, from_account_id, to_account_id, to_amount, fx_rate, spread_bps, kind, original_transfer_id,
+    description, external_reference, status, completed_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 'completed', now()
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers WHERE id = $1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers WHERE id = $1
+FOR NO KEY UPDATE;
+
+-- name: GetCompensatedAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS compensated FROM transfers
+WHERE original_transfer_id = $1;
+
+-- name: ClaimPendingTransfer :one
+UPDATE transfers
+  set status = 'processing', processing_at = now()
+WHERE id = (
+    SELECT id FROM transfers
+    WHERE status = 'pending' OR (status = 'processing' AND processing_at < @stale_before)
+    ORDER BY id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteTransfer :one
+UPDATE transfers
+  set status = 'completed', completed_at = now()
+WHERE id = $1 AND status = 'processing'
+RETURNING *;
+
+-- name: FailTransfer :one
+UPDATE transfers
+  set status = 'failed', failed_at = now(), failure_reason = $2
+WHERE id = $1 AND status = 'processing'
+RETURNING *;
+
+-- name: MarkTransferReversed :one
+UPDATE transfers
+  set status = 'reversed', reversed_at = now()
+WHERE id = $1 AND status = 'completed'
+RETURNING *;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers WHERE 
     from_account_id = $1 OR to_account_id = $2
 ORDER BY id
 LIMIT $3
-OFFSET $4;
\ No newline at end of file
+OFFSET $4;
+
+-- name: ListTransfersByReference :many
+SELECT * FROM transfers WHERE
+    (from_account_id = @account_id OR to_account_id = @account_id)
+    AND external_reference = @external_reference
+ORDER BY id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: GetTransferVelocity :one
+SELECT COUNT(*)::bigint AS count, COALESCE(SUM(amount), 0)::bigint AS total FROM transfers
+WHERE from_account_id = @from_account_id AND created_at >= @since AND status <> 'failed';
+
+-- name: CountRoundTransfers :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = @from_account_id AND created_at >= @since AND status <> 'failed'
+    AND amount % sqlc.arg('round_to')::bigint = 0;
+
+-- name: CountTransfersBetween :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = $1 AND to_account_id = $2 AND status <> 'failed';
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAccountMember(t *testing.T, account Account, permission string) AccountMember {
+	user := createRandomUser(t)
+	arg := CreateAccountMemberParams{
+		AccountID:  account.ID,
+		Username:   user.Username,
+		Permission: permission,
+	}
+
+	member, err := testQueries.CreateAccountMember(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.AccountID, member.AccountID)
+	require.Equal(t, arg.Username, member.Username)
+	require.Equal(t, arg.Permission, member.Permission)
+	require.NotZero(t, member.CreatedAt)
+	return member
+}
+
+func TestCreateAccountMember(t *testing.T) {
+	account := createRandomAccount(t)
+	createRandomAccountMember(t, account, PermissionView)
+}
+
+func TestGetAccountMember(t *testing.T) {
+	account := createRandomAccount(t)
+	member1 := createRandomAccountMember(t, account, PermissionTransact)
+
+	member2, err := testQueries.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  member1.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, member1.Permission, member2.Permission)
+}
+
+func TestListAccountMembers(t *testing.T) {
+	account := createRandomAccount(t)
+	for i := 0; i < 3; i++ {
+		createRandomAccountMember(t, account, PermissionView)
+	}
+
+	members, err := testQueries.ListAccountMembers(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 3)
+}
+
+func TestListAccountsIncludesSharedAccounts(t *testing.T) {
+	account := createRandomAccount(t)
+	member := createRandomAccountMember(t, account, PermissionView)
+
+	accounts, err := testQueries.ListAccounts(context.Background(), ListAccountsParams{
+		Owner:  member.Username,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, account.ID, accounts[0].ID)
+}
+
+func TestDeleteAccountMember(t *testing.T) {
+	account := createRandomAccount(t)
+	member := createRandomAccountMember(t, account, PermissionManage)
+	arg := DeleteAccountMemberParams{
+		AccountID: account.ID,
+		Username:  member.Username,
+	}
+
+	_, err := testQueries.DeleteAccountMember(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = testQueries.DeleteAccountMember(context.Background(), arg)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+const (
+	AccountTypeChecking = "checking"
+	AccountTypeSavings  = "savings"
+)
+
+// maxAccountNumberAttempts bounds how many generated account numbers
+// CreateAccountTx tries before giving up.
+const maxAccountNumberAttempts = 5
+
+var ErrAccountLimitReached = newError(ErrorKindForbidden, "account limit reached for this account type")
+
+// errNoFreeAccountNumber is not a domain error: running out of attempts
+// is the bank's failure, not the caller's.
+var errNoFreeAccountNumber = errors.New("no free account number found")
+
+// newAccountNumber is replaced in tests to force collisions.
+var newAccountNumber = utils.NewAccountNumber
+
+type CreateAccountTxParams struct {
+	CreateAccountParams
+	// MaxAccounts caps how many accounts of this type the owner may hold.
+	// Zero means no limit.
+	MaxAccounts int64 `json:"max_accounts"`
+}
+
+// CreateAccountTx creates an account while enforcing the owner's per-type
+// account limit. The owner's user row is locked so concurrent requests
+// cannot both slip under the limit. An account number is generated unless
+// the caller supplies one; a generated number that is already taken is
+// replaced, up to maxAccountNumberAttempts times. An account.created event
+// is recorded with it.
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error) {
+	var account Account
+	err := store.execTx(ctx, func(q *Queries) error {
+		_, err := q.GetUserForUpdate(ctx, arg.Owner)
+		if err != nil {
+			return err
+		}
+
+		if arg.MaxAccounts > 0 {
+			count, err := q.CountAccountsByType(ctx, CountAccountsByTypeParams{
+				Owner: arg.Owner,
+				Type:  arg.Type,
+			})
+			if err != nil {
+				return err
+			}
+
+			if count >= arg.MaxAccounts {
+				return ErrAccountLimitReached
+			}
+		}
+
+		account, err = createNumberedAccount(ctx, q, arg.CreateAccountParams)
+		if err != nil {
+			return err
+		}
+
+		return recordAccountCreated(ctx, q, account)
+	})
+
+	return account, err
+}
+
+// createNumberedAccount creates the account, generating its number when
+// arg has none and trying another one when it collides. Each attempt runs
+// in a savepoint so that a collision does not abort the transaction.
+func createNumberedAccount(ctx context.Context, q *Queries, arg CreateAccountParams) (Account, error) {
+	if arg.AccountNumber != "" {
+		return q.CreateAccount(ctx, arg)
+	}
+
+	var account Account
+	for attempt := 0; attempt < maxAccountNumberAttempts; attempt++ {
+		number, err := newAccountNumber()
+		if err != nil {
+			return account, err
+		}
+		arg.AccountNumber = number
+
+		err = withSavepoint(ctx, q, func() error {
+			account, err = q.CreateAccount(ctx, arg)
+			return err
+		})
+
+		// account_number is the only unique column of accounts
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+			return account, err
+		}
+	}
+
+	return account, fmt.Errorf("%w after %d attempts", errNoFreeAccountNumber, maxAccountNumberAttempts)
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func TestCreateAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	// several accounts in the same currency are allowed up to the limit
+	for i := 0; i < 2; i++ {
+		account, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+			CreateAccountParams: CreateAccountParams{
+				Owner:    user.Username,
+				Currency: utils.USD,
+				Type:     AccountTypeSavings,
+				Nickname: utils.RandomString(6),
+			},
+			MaxAccounts: 2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, AccountTypeSavings, account.Type)
+		require.True(t, utils.IsValidAccountNumber(account.AccountNumber))
+	}
+
+	_, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.Username,
+			Currency: utils.USD,
+			Type:     AccountTypeSavings,
+		},
+		MaxAccounts: 2,
+	})
+	require.ErrorIs(t, err, ErrAccountLimitReached)
+
+	// the limit is tracked per account type
+	account, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.Username,
+			Currency: utils.USD,
+			Type:     AccountTypeChecking,
+		},
+		MaxAccounts: 2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountTypeChecking, account.Type)
+
+	count, err := testQueries.CountAccountsByType(context.Background(), CountAccountsByTypeParams{
+		Owner: user.Username,
+		Type:  AccountTypeSavings,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+}
+
+func TestCreateAccountTxNumberCollision(t *testing.T) {
+	store := NewStore(testDB)
+	taken := createRandomAccount(t)
+	user := createRandomUser(t)
+	fresh := newTestAccountNumber(t)
+
+	// the first generated number is taken, the next one is free
+	numbers := []string{taken.AccountNumber, fresh}
+	newAccountNumber = func() (string, error) {
+		number := numbers[0]
+		numbers = numbers[1:]
+		return number, nil
+	}
+	t.Cleanup(func() { newAccountNumber = utils.NewAccountNumber })
+
+	account, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.Username,
+			Currency: utils.USD,
+			Type:     AccountTypeChecking,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, fresh, account.AccountNumber)
+
+	// every number is taken
+	newAccountNumber = func() (string, error) {
+		return taken.AccountNumber, nil
+	}
+	_, err = store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.Username,
+			Currency: utils.USD,
+			Type:     AccountTypeChecking,
+		},
+	})
+	require.ErrorIs(t, err, errNoFreeAccountNumber)
+	require.Zero(t, ErrorKindOf(err))
+}
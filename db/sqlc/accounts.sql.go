@@ -13,7 +13,7 @@ const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
   set balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, type, nickname, account_number, approval_threshold
 `
 
 type AddAccountBalanceParams struct {
@@ -30,26 +30,57 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.ApprovalThreshold,
 	)
 	return i, err
 }
 
+const countAccountsByType = `-- name: CountAccountsByType :one
+SELECT COUNT(*) FROM accounts
+WHERE owner = $1 AND type = $2
+`
+
+type CountAccountsByTypeParams struct {
+	Owner string `json:"owner"`
+	Type  string `json:"type"`
+}
+
+func (q *Queries) CountAccountsByType(ctx context.Context, arg CountAccountsByTypeParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countAccountsByType, arg.Owner, arg.Type)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts  (
-    owner, balance, currency
+    owner, balance, currency, type, nickname, account_number
 ) VALUES (
-    $1, $2, $3
-) RETURNING id, owner, balance, currency, created_at
+    $1, $2, $3, $4, $5, $6
+) RETURNING id, owner, balance, currency, created_at, type, nickname, account_number, approval_threshold
 `
 
 type CreateAccountParams struct {
-	Owner    string `json:"owner"`
-	Balance  int64  `json:"balance"`
-	Currency string `json:"currency"`
+	Owner         string `json:"owner"`
+	Balance       int64  `json:"balance"`
+	Currency      string `json:"currency"`
+	Type          string `json:"type"`
+	Nickname      string `json:"nickname"`
+	AccountNumber string `json:"account_number"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRow(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.Type,
+		arg.Nickname,
+		arg.AccountNumber,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -57,6 +88,10 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.ApprovalThreshold,
 	)
 	return i, err
 }
@@ -72,7 +107,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, type, nickname, account_number, approval_threshold FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -85,12 +120,38 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.ApprovalThreshold,
+	)
+	return i, err
+}
+
+const getAccountByNumber = `-- name: GetAccountByNumber :one
+SELECT id, owner, balance, currency, created_at, type, nickname, account_number, approval_threshold FROM accounts
+WHERE account_number = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountByNumber(ctx context.Context, accountNumber string) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountByNumber, accountNumber)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.ApprovalThreshold,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, type, nickname, account_number, approval_threshold FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -104,13 +165,19 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.ApprovalThreshold,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
-WHERE owner = $1
+SELECT id, owner, balance, currency, created_at, type, nickname, account_number, approval_threshold FROM accounts
+WHERE owner = $1 OR id IN (
+    SELECT account_id FROM account_members WHERE username = $1
+)
 ORDER BY id
 LIMIT $2
 OFFSET $3
@@ -137,6 +204,10 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.Type,
+			&i.Nickname,
+			&i.AccountNumber,
+			&i.ApprovalThreshold,
 		); err != nil {
 			return nil, err
 		}
@@ -148,20 +219,49 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
-const updateAccount = `-- name: UpdateAccount :one
+const getDefaultAccount = `-- name: GetDefaultAccount :one
+SELECT id, owner, balance, currency, created_at, type, nickname, account_number, approval_threshold FROM accounts
+WHERE owner = $1 AND currency = $2
+ORDER BY type = 'checking' DESC, id
+LIMIT 1
+`
+
+type GetDefaultAccountParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetDefaultAccount(ctx context.Context, arg GetDefaultAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getDefaultAccount, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.ApprovalThreshold,
+	)
+	return i, err
+}
+
+const setApprovalThreshold = `-- name: SetApprovalThreshold :one
 UPDATE accounts
-  set balance = $2
+  set approval_threshold = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, type, nickname, account_number, approval_threshold
 `
 
-type UpdateAccountParams struct {
-	ID      int64 `json:"id"`
-	Balance int64 `json:"balance"`
+type SetApprovalThresholdParams struct {
+	ID                int64 `json:"id"`
+	ApprovalThreshold int64 `json:"approval_threshold"`
 }
 
-func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, updateAccount, arg.ID, arg.Balance)
+func (q *Queries) SetApprovalThreshold(ctx context.Context, arg SetApprovalThresholdParams) (Account, error) {
+	row := q.db.QueryRow(ctx, setApprovalThreshold, arg.ID, arg.ApprovalThreshold)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -169,6 +269,10 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.ApprovalThreshold,
 	)
 	return i, err
 }
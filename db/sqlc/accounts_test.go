@@ -3,7 +3,6 @@ package db
 import (
 	"context"
 	"testing"
-	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
@@ -11,12 +10,17 @@ import (
 )
 
 func createRandomAccount(t *testing.T) Account {
+	var err error
 	user := createRandomUser(t)
 	arg := CreateAccountParams{
 		Owner:    user.Username,
 		Balance:  utils.RandomMoney(),
 		Currency: utils.RandomCurrency(),
+		Type:     AccountTypeChecking,
+		Nickname: utils.RandomString(6),
 	}
+	arg.AccountNumber, err = utils.NewAccountNumber()
+	require.NoError(t, err)
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
 	require.NoError(t, err)
@@ -26,6 +30,9 @@ func createRandomAccount(t *testing.T) Account {
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, arg.Type, account.Type)
+	require.Equal(t, arg.Nickname, account.Nickname)
+	require.Equal(t, arg.AccountNumber, account.AccountNumber)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
@@ -35,31 +42,23 @@ func TestCreateAccount(t *testing.T) {
 	createRandomAccount(t)
 }
 
-func TestUpdateAccount(t *testing.T) {
+func TestGetAccount(t *testing.T) {
 	account1 := createRandomAccount(t)
-	arg := UpdateAccountParams{
-		ID:      account1.ID,
-		Balance: utils.RandomMoney(),
-	}
-	account2, err := testQueries.UpdateAccount(context.Background(), arg)
+	account2, err := testQueries.GetAccount(context.Background(), account1.ID)
 	require.NoError(t, err)
 	require.NotEmpty(t, account2)
 	require.Equal(t, account1.ID, account2.ID)
-	require.Equal(t, arg.Balance, account2.Balance)
 	require.Equal(t, account1.Owner, account2.Owner)
+	require.Equal(t, account1.Balance, account2.Balance)
 	require.Equal(t, account1.Currency, account2.Currency)
-	require.WithinDuration(t, account1.CreatedAt.Time, account2.CreatedAt.Time, time.Second)
 }
 
-func TestGetAccount(t *testing.T) {
+func TestGetAccountByNumber(t *testing.T) {
 	account1 := createRandomAccount(t)
-	account2, err := testQueries.GetAccount(context.Background(), account1.ID)
+	account2, err := testQueries.GetAccountByNumber(context.Background(), account1.AccountNumber)
 	require.NoError(t, err)
-	require.NotEmpty(t, account2)
 	require.Equal(t, account1.ID, account2.ID)
-	require.Equal(t, account1.Owner, account2.Owner)
-	require.Equal(t, account1.Balance, account2.Balance)
-	require.Equal(t, account1.Currency, account2.Currency)
+	require.Equal(t, account1.AccountNumber, account2.AccountNumber)
 }
 
 func TestDeleteAccount(t *testing.T) {
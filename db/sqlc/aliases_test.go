@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func TestAliasLifecycle(t *testing.T) {
+	user := createRandomUser(t)
+	phone := "+1415555" + utils.RandomString(4)
+
+	alias, err := testQueries.CreateAlias(context.Background(), CreateAliasParams{
+		Alias:    phone,
+		Kind:     utils.AliasPhone,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, phone, alias.Alias)
+	require.False(t, alias.VerifiedAt.Valid)
+
+	// the alias is unique across users
+	other := createRandomUser(t)
+	_, err = testQueries.CreateAlias(context.Background(), CreateAliasParams{
+		Alias:    phone,
+		Kind:     utils.AliasPhone,
+		Username: other.Username,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	verified, err := testQueries.VerifyAlias(context.Background(), phone)
+	require.NoError(t, err)
+	require.True(t, verified.VerifiedAt.Valid)
+
+	_, err = testQueries.VerifyAlias(context.Background(), phone)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	aliases, err := testQueries.ListAliases(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, aliases, 1)
+
+	// only the owner can release it
+	_, err = testQueries.DeleteAlias(context.Background(), DeleteAliasParams{Alias: phone, Username: other.Username})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.DeleteAlias(context.Background(), DeleteAliasParams{Alias: phone, Username: user.Username})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetAlias(context.Background(), phone)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCreateAliasSquatted(t *testing.T) {
+	owner := createRandomUser(t)
+	squatter := createRandomUser(t)
+
+	// anyone may claim an email, but it stays unverified
+	squatted, err := testQueries.CreateAlias(context.Background(), CreateAliasParams{
+		Alias:    owner.Email,
+		Kind:     utils.AliasEmail,
+		Username: squatter.Username,
+	})
+	require.NoError(t, err)
+	require.False(t, squatted.VerifiedAt.Valid)
+
+	// the owner's verified claim takes it over
+	claimed, err := testQueries.CreateAlias(context.Background(), CreateAliasParams{
+		Alias:      owner.Email,
+		Kind:       utils.AliasEmail,
+		Username:   owner.Username,
+		VerifiedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, owner.Username, claimed.Username)
+	require.True(t, claimed.VerifiedAt.Valid)
+
+	// and can no longer be taken back
+	for _, verifiedAt := range []pgtype.Timestamptz{{}, {Time: time.Now(), Valid: true}} {
+		_, err = testQueries.CreateAlias(context.Background(), CreateAliasParams{
+			Alias:      owner.Email,
+			Kind:       utils.AliasEmail,
+			Username:   squatter.Username,
+			VerifiedAt: verifiedAt,
+		})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	}
+
+	alias, err := testQueries.GetAlias(context.Background(), owner.Email)
+	require.NoError(t, err)
+	require.Equal(t, owner.Username, alias.Username)
+}
+
+func TestGetDefaultAccount(t *testing.T) {
+	owner := createRandomUser(t)
+	savings, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:         owner.Username,
+		Currency:      utils.EUR,
+		Type:          AccountTypeSavings,
+		AccountNumber: newTestAccountNumber(t),
+	})
+	require.NoError(t, err)
+
+	// a lone savings account is the default
+	account, err := testQueries.GetDefaultAccount(context.Background(), GetDefaultAccountParams{
+		Owner:    owner.Username,
+		Currency: utils.EUR,
+	})
+	require.NoError(t, err)
+	require.Equal(t, savings.ID, account.ID)
+
+	// until the owner opens a checking account in the currency
+	checking, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:         owner.Username,
+		Currency:      utils.EUR,
+		Type:          AccountTypeChecking,
+		AccountNumber: newTestAccountNumber(t),
+	})
+	require.NoError(t, err)
+
+	account, err = testQueries.GetDefaultAccount(context.Background(), GetDefaultAccountParams{
+		Owner:    owner.Username,
+		Currency: utils.EUR,
+	})
+	require.NoError(t, err)
+	require.Equal(t, checking.ID, account.ID)
+
+	_, err = testQueries.GetDefaultAccount(context.Background(), GetDefaultAccountParams{
+		Owner:    owner.Username,
+		Currency: "JPY",
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func newTestAccountNumber(t *testing.T) string {
+	number, err := utils.NewAccountNumber()
+	require.NoError(t, err)
+	return number
+}
@@ -0,0 +1,63 @@
+package db
+
+import "context"
+
+const (
+	ThresholdChangeStatusPending  = "pending"
+	ThresholdChangeStatusApproved = "approved"
+	ThresholdChangeStatusRejected = "rejected"
+)
+
+var (
+	ErrThresholdChangeNotPending = newError(ErrorKindConflict, "approval threshold change has already been decided")
+	ErrSelfThresholdApproval     = newError(ErrorKindForbidden, "a threshold change cannot be approved by the member who requested it")
+)
+
+type ApproveThresholdChangeTxParams struct {
+	ChangeID int64  `json:"change_id"`
+	Approver string `json:"approver"`
+}
+
+type ApproveThresholdChangeTxResult struct {
+	Change  ApprovalThresholdChange `json:"change"`
+	Account Account                 `json:"account"`
+}
+
+// ApproveThresholdChangeTx applies a pending change to an account's
+// approval threshold and records who approved it. The member who requested
+// the change cannot approve it, and the change row stays locked until
+// commit, so it is applied at most once.
+func (store *SQLStore) ApproveThresholdChangeTx(ctx context.Context, arg ApproveThresholdChangeTxParams) (ApproveThresholdChangeTxResult, error) {
+	var result ApproveThresholdChangeTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		change, err := q.GetApprovalThresholdChangeForUpdate(ctx, arg.ChangeID)
+		if err != nil {
+			return err
+		}
+
+		if change.Status != ThresholdChangeStatusPending {
+			return ErrThresholdChangeNotPending
+		}
+
+		if change.RequestedBy == arg.Approver {
+			return ErrSelfThresholdApproval
+		}
+
+		result.Change, err = q.DecideApprovalThresholdChange(ctx, DecideApprovalThresholdChangeParams{
+			ID:        change.ID,
+			Status:    ThresholdChangeStatusApproved,
+			DecidedBy: arg.Approver,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.SetApprovalThreshold(ctx, SetApprovalThresholdParams{
+			ID:                change.AccountID,
+			ApprovalThreshold: change.ApprovalThreshold,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApproveThresholdChangeTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := withApprovalThreshold(t, createRandomAccount(t), 1_000)
+	approver := createRandomUser(t)
+
+	change, err := store.CreateApprovalThresholdChange(context.Background(), CreateApprovalThresholdChangeParams{
+		AccountID:         account.ID,
+		ApprovalThreshold: 0,
+		RequestedBy:       account.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ThresholdChangeStatusPending, change.Status)
+
+	_, err = store.ApproveThresholdChangeTx(context.Background(), ApproveThresholdChangeTxParams{
+		ChangeID: change.ID,
+		Approver: account.Owner,
+	})
+	require.ErrorIs(t, err, ErrSelfThresholdApproval)
+
+	// the request alone leaves the threshold in place
+	unchanged, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1_000), unchanged.ApprovalThreshold)
+
+	result, err := store.ApproveThresholdChangeTx(context.Background(), ApproveThresholdChangeTxParams{
+		ChangeID: change.ID,
+		Approver: approver.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ThresholdChangeStatusApproved, result.Change.Status)
+	require.Equal(t, approver.Username, result.Change.DecidedBy)
+	require.True(t, result.Change.DecidedAt.Valid)
+	require.Zero(t, result.Account.ApprovalThreshold)
+
+	_, err = store.ApproveThresholdChangeTx(context.Background(), ApproveThresholdChangeTxParams{
+		ChangeID: change.ID,
+		Approver: approver.Username,
+	})
+	require.ErrorIs(t, err, ErrThresholdChangeNotPending)
+}
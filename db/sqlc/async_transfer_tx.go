@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	TransferStatusPending    = "pending"
+	TransferStatusProcessing = "processing"
+	TransferStatusCompleted  = "completed"
+	TransferStatusFailed     = "failed"
+	TransferStatusReversed   = "reversed"
+)
+
+var ErrTransferNotProcessing = newError(ErrorKindConflict, "transfer is not being processed")
+
+// CreatePendingTransferTx records a transfer without moving any money, for
+// the transfer workers to pick up with ClaimPendingTransfer. The exchange
+// rate, if any, is fixed now; funds and fees are settled when it runs. The
+// idempotency key, if any, stores the pending transfer as a 202 response.
+func (store *SQLStore) CreatePendingTransferTx(ctx context.Context, arg TransferTxParams) (Transfer, error) {
+	var recorded Transfer
+	err := store.execTx(ctx, func(q *Queries) error {
+		exchange := Exchange{Rate: FxRateScale, ToAmount: arg.Amount}
+		if arg.Exchange != nil {
+			exchange = *arg.Exchange
+		}
+
+		var err error
+		recorded, err = q.CreatePendingTransfer(ctx, CreatePendingTransferParams{
+			FromAccountID:     arg.FromAccountID,
+			ToAccountID:       arg.ToAccountID,
+			Amount:            arg.Amount,
+			ToAmount:          exchange.ToAmount,
+			FxRate:            exchange.Rate,
+			SpreadBps:         exchange.SpreadBps,
+			Description:       arg.Description,
+			ExternalReference: arg.ExternalReference,
+			Metadata:          arg.Metadata,
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return ErrAccountNotFound
+			}
+			return err
+		}
+
+		if arg.Idempotency == nil {
+			return nil
+		}
+
+		return saveIdempotencyKey(ctx, q, *arg.Idempotency, recorded.ID, http.StatusAccepted, recorded)
+	})
+
+	return recorded, err
+}
+
+// ProcessTransferTx runs a transfer claimed by ClaimPendingTransfer: it
+// posts the entries, charges the fee and marks the transfer completed. The
+// row is locked and must still be processing, so a transfer reclaimed after
+// its worker stalled is executed at most once.
+//
+// When the transfer breaks a business rule, e.g. the sender no longer has
+// the funds, its entries are rolled back and it is marked failed with the
+// reason; that is not an error. Either outcome is recorded as an event. Any
+// other error leaves it processing, to be claimed again once it goes stale.
+func (store *SQLStore) ProcessTransferTx(ctx context.Context, transferID int64) (TransferTxResult, error) {
+	var result TransferTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		recorded, err := q.GetTransferForUpdate(ctx, transferID)
+		if err != nil {
+			return err
+		}
+
+		if recorded.Status != TransferStatusProcessing {
+			return ErrTransferNotProcessing
+		}
+
+		err = withSavepoint(ctx, q, func() error {
+			var err error
+			result, err = postTransfer(ctx, q, recorded)
+			if err != nil {
+				return err
+			}
+
+			// the threshold was checked when the transfer was queued, but it
+			// may have been lowered since
+			if result.FromAccount.RequiresApproval(recorded.Amount) {
+				return ErrApprovalRequired
+			}
+
+			err = chargeFee(ctx, q, &result)
+			if err != nil {
+				return err
+			}
+
+			return checkAvailableBalance(ctx, q, result.FromAccount)
+		})
+
+		var domainErr *Error
+		if err != nil && !errors.As(err, &domainErr) {
+			return err
+		}
+
+		if err == nil {
+			result.Transfer, err = q.CompleteTransfer(ctx, recorded.ID)
+		} else {
+			result = TransferTxResult{}
+			result.Transfer, err = q.FailTransfer(ctx, FailTransferParams{
+				ID:            recorded.ID,
+				FailureReason: domainErr.Error(),
+			})
+		}
+		if err != nil {
+			return err
+		}
+
+		return recordTransferEvent(ctx, q, result.Transfer)
+	})
+
+	return result, err
+}
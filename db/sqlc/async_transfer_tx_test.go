@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+// processPendingTransfer runs pending transfers the way the transfer
+// workers do until the given one has been processed. Other tests' pending
+// transfers are processed along the way rather than left claimed.
+func processPendingTransfer(t *testing.T, store Store, transferID int64) TransferTxResult {
+	for {
+		claimed, err := store.ClaimPendingTransfer(context.Background(), pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true})
+		require.NoError(t, err)
+		require.Equal(t, TransferStatusProcessing, claimed.Status)
+		require.True(t, claimed.ProcessingAt.Valid)
+
+		result, err := store.ProcessTransferTx(context.Background(), claimed.ID)
+		require.NoError(t, err)
+		if claimed.ID == transferID {
+			return result
+		}
+	}
+}
+
+func TestProcessTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	pending, err := store.CreatePendingTransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Description:   "rent",
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPending, pending.Status)
+	require.False(t, pending.CompletedAt.Valid)
+
+	// no money moves until the transfer is processed
+	unchanged, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, unchanged.Balance)
+
+	// a pending transfer cannot be processed without being claimed first
+	_, err = store.ProcessTransferTx(context.Background(), pending.ID)
+	require.ErrorIs(t, err, ErrTransferNotProcessing)
+
+	_, err = store.ReverseTransferTx(context.Background(), pending.ID)
+	require.ErrorIs(t, err, ErrTransferNotCompleted)
+
+	result := processPendingTransfer(t, store, pending.ID)
+	require.Equal(t, TransferStatusCompleted, result.Transfer.Status)
+	require.True(t, result.Transfer.ProcessingAt.Valid)
+	require.True(t, result.Transfer.CompletedAt.Valid)
+	require.Equal(t, "rent", result.FromEntry.Description)
+	require.Equal(t, account1.Balance-10, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+10, result.ToAccount.Balance)
+
+	// it is processed only once
+	_, err = store.ProcessTransferTx(context.Background(), pending.ID)
+	require.ErrorIs(t, err, ErrTransferNotProcessing)
+
+	_, err = store.ReverseTransferTx(context.Background(), pending.ID)
+	require.NoError(t, err)
+
+	reversed, err := store.GetTransfer(context.Background(), pending.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusReversed, reversed.Status)
+	require.True(t, reversed.ReversedAt.Valid)
+}
+
+func TestProcessTransferTxFails(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFeeTestAccount(t, 5)
+	account2 := createFeeTestAccount(t, 0)
+
+	pending, err := store.CreatePendingTransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	result := processPendingTransfer(t, store, pending.ID)
+	require.Equal(t, TransferStatusFailed, result.Transfer.Status)
+	require.Equal(t, ErrInsufficientFunds.Error(), result.Transfer.FailureReason)
+	require.True(t, result.Transfer.FailedAt.Valid)
+	require.Empty(t, result.FromEntry)
+
+	// the failed attempt was rolled back
+	updated, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), updated.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), pending.ID)
+	require.ErrorIs(t, err, ErrTransferNotCompleted)
+}
+
+func TestTransferTxCompleted(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusCompleted, result.Transfer.Status)
+	require.True(t, result.Transfer.CompletedAt.Valid)
+	require.False(t, result.Transfer.ProcessingAt.Valid)
+}
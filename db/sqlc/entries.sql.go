@@ -7,35 +7,111 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
-    amount, account_id
+    amount, account_id, description
 ) VALUES (
-    $1, $2
-) RETURNING id, amount, account_id, created_at
+    $1, $2, $3
+) RETURNING id, amount, account_id, created_at, description, journal_id, transfer_id
 `
 
 type CreateEntryParams struct {
-	Amount    int64 `json:"amount"`
-	AccountID int64 `json:"account_id"`
+	Amount      int64  `json:"amount"`
+	AccountID   int64  `json:"account_id"`
+	Description string `json:"description"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRow(ctx, createEntry, arg.Amount, arg.AccountID)
+	row := q.db.QueryRow(ctx, createEntry, arg.Amount, arg.AccountID, arg.Description)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.AccountID,
+		&i.CreatedAt,
+		&i.Description,
+		&i.JournalID,
+		&i.TransferID,
+	)
+	return i, err
+}
+
+const createJournalEntry = `-- name: CreateJournalEntry :one
+INSERT INTO entries (
+    amount, account_id, description, journal_id
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, amount, account_id, created_at, description, journal_id, transfer_id
+`
+
+type CreateJournalEntryParams struct {
+	Amount      int64       `json:"amount"`
+	AccountID   int64       `json:"account_id"`
+	Description string      `json:"description"`
+	JournalID   pgtype.Int8 `json:"journal_id"`
+}
+
+func (q *Queries) CreateJournalEntry(ctx context.Context, arg CreateJournalEntryParams) (Entry, error) {
+	row := q.db.QueryRow(ctx, createJournalEntry,
+		arg.Amount,
+		arg.AccountID,
+		arg.Description,
+		arg.JournalID,
+	)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.AccountID,
+		&i.CreatedAt,
+		&i.Description,
+		&i.JournalID,
+		&i.TransferID,
+	)
+	return i, err
+}
+
+const createTransferEntry = `-- name: CreateTransferEntry :one
+INSERT INTO entries (
+    amount, account_id, description, transfer_id
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, amount, account_id, created_at, description, journal_id, transfer_id
+`
+
+type CreateTransferEntryParams struct {
+	Amount      int64       `json:"amount"`
+	AccountID   int64       `json:"account_id"`
+	Description string      `json:"description"`
+	TransferID  pgtype.Int8 `json:"transfer_id"`
+}
+
+func (q *Queries) CreateTransferEntry(ctx context.Context, arg CreateTransferEntryParams) (Entry, error) {
+	row := q.db.QueryRow(ctx, createTransferEntry,
+		arg.Amount,
+		arg.AccountID,
+		arg.Description,
+		arg.TransferID,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.Amount,
 		&i.AccountID,
 		&i.CreatedAt,
+		&i.Description,
+		&i.JournalID,
+		&i.TransferID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, amount, account_id, created_at FROM entries WHERE id = $1
+SELECT id, amount, account_id, created_at, description, journal_id, transfer_id FROM entries WHERE id = $1
 `
 
 func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
@@ -46,12 +122,15 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.Amount,
 		&i.AccountID,
 		&i.CreatedAt,
+		&i.Description,
+		&i.JournalID,
+		&i.TransferID,
 	)
 	return i, err
 }
 
 const listEntries = `-- name: ListEntries :many
-SELECT id, amount, account_id, created_at FROM entries 
+SELECT id, amount, account_id, created_at, description, journal_id, transfer_id FROM entries 
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -78,6 +157,43 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.Amount,
 			&i.AccountID,
 			&i.CreatedAt,
+			&i.Description,
+			&i.JournalID,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJournalEntries = `-- name: ListJournalEntries :many
+SELECT id, amount, account_id, created_at, description, journal_id, transfer_id FROM entries
+WHERE journal_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListJournalEntries(ctx context.Context, journalID pgtype.Int8) ([]Entry, error) {
+	rows, err := q.db.Query(ctx, listJournalEntries, journalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.AccountID,
+			&i.CreatedAt,
+			&i.Description,
+			&i.JournalID,
+			&i.TransferID,
 		); err != nil {
 			return nil, err
 		}
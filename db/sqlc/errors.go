@@ -0,0 +1,55 @@
+package db
+
+import (
+	"errors"
+)
+
+// ErrorKind says what went wrong in a domain error, so callers can react
+// to a whole class of failures without matching each error.
+type ErrorKind int
+
+const (
+	// ErrorKindNotFound means a record the operation needs does not exist.
+	ErrorKindNotFound ErrorKind = iota + 1
+	// ErrorKindInvalid means the request can never succeed as given.
+	ErrorKindInvalid
+	// ErrorKindUnprocessable means the request is valid but breaks a
+	// business rule right now, e.g. the balance does not cover it.
+	ErrorKindUnprocessable
+	// ErrorKindConflict means the record is not in a state that allows
+	// the operation.
+	ErrorKindConflict
+	// ErrorKindForbidden means the user may not perform the operation.
+	ErrorKindForbidden
+)
+
+// Error is a failure the store reports on purpose, as opposed to a
+// database or network error. Each one is a package-level value, so it can
+// be matched with errors.Is as well as by kind.
+type Error struct {
+	Kind    ErrorKind
+	Message string
+}
+
+func newError(kind ErrorKind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ErrorKindOf returns the kind of the domain error in err's chain, or 0
+// when there is none.
+func ErrorKindOf(err error) ErrorKind {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind
+	}
+	return 0
+}
+
+var (
+	ErrAccountNotFound  = newError(ErrorKindNotFound, "account not found")
+	ErrCurrencyMismatch = newError(ErrorKindInvalid, "account currency mismatch")
+)
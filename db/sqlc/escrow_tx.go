@@ -0,0 +1,244 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	EscrowStatusHeld     = "held"
+	EscrowStatusReleased = "released"
+	EscrowStatusRefunded = "refunded"
+)
+
+const (
+	// EscrowActionRelease pays the escrowed funds out to the seller.
+	EscrowActionRelease = "release"
+	// EscrowActionRefund returns the escrowed funds to the buyer.
+	EscrowActionRefund = "refund"
+	// escrowActionFund tags the transfer that moves funds into escrow.
+	escrowActionFund = "fund"
+)
+
+var (
+	ErrNoEscrowAccount = newError(ErrorKindUnprocessable, "no escrow account for this currency")
+	ErrEscrowNotHeld   = newError(ErrorKindConflict, "escrow has already been settled")
+	ErrEscrowAction    = newError(ErrorKindInvalid, "escrow action must be release or refund")
+)
+
+type CreateEscrowTxParams struct {
+	BuyerAccountID  int64  `json:"buyer_account_id"`
+	SellerAccountID int64  `json:"seller_account_id"`
+	Amount          int64  `json:"amount"`
+	Description     string `json:"description"`
+	CreatedBy       string `json:"created_by"`
+	// TimeoutAction is applied by the escrow worker if the escrow is still
+	// held at TimeoutAt.
+	TimeoutAt     pgtype.Timestamptz `json:"timeout_at"`
+	TimeoutAction string             `json:"timeout_action"`
+}
+
+type EscrowTxResult struct {
+	Escrow   Escrow           `json:"escrow"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// escrowTag is the metadata stored on every transfer that moves escrowed
+// funds, so the ledger shows which escrow and parties a movement belongs to.
+type escrowTag struct {
+	EscrowID        int64  `json:"escrow_id"`
+	Action          string `json:"escrow_action"`
+	BuyerAccountID  int64  `json:"buyer_account_id"`
+	SellerAccountID int64  `json:"seller_account_id"`
+}
+
+// escrowReference is the external reference of an escrow's transfers, which
+// ListTransfersByReference searches on.
+func escrowReference(escrowID int64) string {
+	return fmt.Sprintf("escrow-%d", escrowID)
+}
+
+// CreateEscrowTx moves Amount from the buyer into the system escrow account
+// of the buyer's currency and records the escrow. Moving funds into escrow
+// is free, so that a refund returns everything the buyer paid; the fee is
+// charged to the seller when the escrow is released.
+func (store *SQLStore) CreateEscrowTx(ctx context.Context, arg CreateEscrowTxParams) (EscrowTxResult, error) {
+	var result EscrowTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		if arg.TimeoutAction != EscrowActionRelease && arg.TimeoutAction != EscrowActionRefund {
+			return ErrEscrowAction
+		}
+
+		buyer, err := q.GetAccount(ctx, arg.BuyerAccountID)
+		if err != nil {
+			return err
+		}
+
+		escrowAccount, err := q.GetEscrowAccount(ctx, buyer.Currency)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNoEscrowAccount
+			}
+			return err
+		}
+
+		escrow, err := q.CreateEscrow(ctx, CreateEscrowParams{
+			BuyerAccountID:  buyer.ID,
+			SellerAccountID: arg.SellerAccountID,
+			EscrowAccountID: escrowAccount.AccountID,
+			Amount:          arg.Amount,
+			Currency:        buyer.Currency,
+			Description:     arg.Description,
+			CreatedBy:       arg.CreatedBy,
+			TimeoutAt:       arg.TimeoutAt,
+			TimeoutAction:   arg.TimeoutAction,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = escrowTransfer(ctx, q, escrow, escrowActionFund, buyer.ID, escrow.EscrowAccountID)
+		if err != nil {
+			return err
+		}
+
+		err = checkAvailableBalance(ctx, q, result.Transfer.FromAccount)
+		if err != nil {
+			return err
+		}
+
+		result.Escrow, err = q.SetEscrowFundTransfer(ctx, SetEscrowFundTransferParams{
+			ID:             escrow.ID,
+			FundTransferID: pgtype.Int8{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordTransferEvent(ctx, q, result.Transfer.Transfer)
+	})
+
+	return result, err
+}
+
+type SettleEscrowTxParams struct {
+	EscrowID int64 `json:"escrow_id"`
+	// Action is EscrowActionRelease or EscrowActionRefund.
+	Action    string `json:"action"`
+	SettledBy string `json:"settled_by"`
+}
+
+// SettleEscrowTx pays a held escrow out to the seller or back to the buyer.
+// The escrow row stays locked until commit, so it is settled at most once.
+func (store *SQLStore) SettleEscrowTx(ctx context.Context, arg SettleEscrowTxParams) (EscrowTxResult, error) {
+	var result EscrowTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		escrow, err := q.GetEscrowForUpdate(ctx, arg.EscrowID)
+		if err != nil {
+			return err
+		}
+
+		result, err = payOutEscrow(ctx, q, escrow, arg.Action, arg.SettledBy)
+		if err != nil {
+			return err
+		}
+
+		return recordTransferEvent(ctx, q, result.Transfer.Transfer)
+	})
+
+	return result, err
+}
+
+// SettleTimedOutEscrowTx claims one held escrow past its timeout and
+// applies its timeout action. Like RunStandingOrderTx it skips rows claimed
+// by other replicas, and it returns an error wrapping sql.ErrNoRows when
+// nothing has timed out.
+func (store *SQLStore) SettleTimedOutEscrowTx(ctx context.Context) (EscrowTxResult, error) {
+	var result EscrowTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		escrow, err := q.ClaimTimedOutEscrow(ctx)
+		if err != nil {
+			return err
+		}
+
+		result, err = payOutEscrow(ctx, q, escrow, escrow.TimeoutAction, "")
+		if err != nil {
+			return err
+		}
+
+		return recordTransferEvent(ctx, q, result.Transfer.Transfer)
+	})
+
+	return result, err
+}
+
+// payOutEscrow moves a locked escrow's funds out of the escrow account and
+// marks it released or refunded. A release charges the transfer's fee to
+// the seller, out of the payout; a refund is free.
+func payOutEscrow(ctx context.Context, q *Queries, escrow Escrow, action, settledBy string) (EscrowTxResult, error) {
+	var result EscrowTxResult
+	if escrow.Status != EscrowStatusHeld {
+		return result, ErrEscrowNotHeld
+	}
+
+	var toAccountID int64
+	var status string
+	switch action {
+	case EscrowActionRelease:
+		toAccountID, status = escrow.SellerAccountID, EscrowStatusReleased
+	case EscrowActionRefund:
+		toAccountID, status = escrow.BuyerAccountID, EscrowStatusRefunded
+	default:
+		return result, ErrEscrowAction
+	}
+
+	var err error
+	result.Transfer, err = escrowTransfer(ctx, q, escrow, action, escrow.EscrowAccountID, toAccountID)
+	if err != nil {
+		return result, err
+	}
+
+	if action == EscrowActionRelease {
+		err = chargeFeeTo(ctx, q, &result.Transfer, &result.Transfer.ToAccount)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	result.Escrow, err = q.SettleEscrow(ctx, SettleEscrowParams{
+		ID:               escrow.ID,
+		Status:           status,
+		SettleTransferID: pgtype.Int8{Int64: result.Transfer.Transfer.ID, Valid: true},
+		SettledBy:        settledBy,
+	})
+	return result, err
+}
+
+// escrowTransfer moves the escrow amount between two accounts as an
+// ordinary transfer tagged with the escrow. Its fee, if any, is up to the
+// caller.
+func escrowTransfer(ctx context.Context, q *Queries, escrow Escrow, action string, fromAccountID, toAccountID int64) (TransferTxResult, error) {
+	metadata, err := json.Marshal(escrowTag{
+		EscrowID:        escrow.ID,
+		Action:          action,
+		BuyerAccountID:  escrow.BuyerAccountID,
+		SellerAccountID: escrow.SellerAccountID,
+	})
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	return recordTransfer(ctx, q, TransferTxParams{
+		FromAccountID:     fromAccountID,
+		ToAccountID:       toAccountID,
+		Amount:            escrow.Amount,
+		Description:       escrow.Description,
+		ExternalReference: escrowReference(escrow.ID),
+		Metadata:          metadata,
+	})
+}
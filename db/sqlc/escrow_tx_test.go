@@ -0,0 +1,189 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+// escrowTestAccount returns the escrow account of feeTestCurrency, creating
+// it on first use. The currency is shared with the fee tests so that
+// releasing an escrow is charged their fee schedule.
+func escrowTestAccount(t *testing.T) Account {
+	escrowAccount, err := testQueries.GetEscrowAccount(context.Background(), feeTestCurrency)
+	if errors.Is(err, sql.ErrNoRows) {
+		account := createFeeTestAccount(t, 0)
+		escrowAccount, err = testQueries.CreateEscrowAccount(context.Background(), CreateEscrowAccountParams{
+			Currency:  feeTestCurrency,
+			AccountID: account.ID,
+		})
+	}
+	require.NoError(t, err)
+
+	account, err := testQueries.GetAccount(context.Background(), escrowAccount.AccountID)
+	require.NoError(t, err)
+	return account
+}
+
+func createHeldEscrow(t *testing.T, store Store, buyer, seller Account, timeoutAt time.Time, timeoutAction string) EscrowTxResult {
+	result, err := store.CreateEscrowTx(context.Background(), CreateEscrowTxParams{
+		BuyerAccountID:  buyer.ID,
+		SellerAccountID: seller.ID,
+		Amount:          100,
+		Description:     "bike",
+		CreatedBy:       buyer.Owner,
+		TimeoutAt:       pgtype.Timestamptz{Time: timeoutAt, Valid: true},
+		TimeoutAction:   timeoutAction,
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestCreateEscrowTx(t *testing.T) {
+	store := NewStore(testDB)
+	escrowAccount := escrowTestAccount(t)
+	buyer := createFeeTestAccount(t, 1_000)
+	seller := createFeeTestAccount(t, 0)
+
+	result := createHeldEscrow(t, store, buyer, seller, time.Now().Add(time.Hour), EscrowActionRefund)
+	escrow := result.Escrow
+	require.Equal(t, EscrowStatusHeld, escrow.Status)
+	require.Equal(t, escrowAccount.ID, escrow.EscrowAccountID)
+	require.Equal(t, feeTestCurrency, escrow.Currency)
+	require.Equal(t, result.Transfer.Transfer.ID, escrow.FundTransferID.Int64)
+	require.False(t, escrow.SettleTransferID.Valid)
+
+	// the funding transfer is tagged with the escrow and its parties
+	fund := result.Transfer.Transfer
+	require.Equal(t, buyer.ID, fund.FromAccountID)
+	require.Equal(t, escrowAccount.ID, fund.ToAccountID)
+	require.Equal(t, escrowReference(escrow.ID), fund.ExternalReference)
+
+	var tag escrowTag
+	require.NoError(t, json.Unmarshal(fund.Metadata, &tag))
+	require.Equal(t, escrowTag{
+		EscrowID:        escrow.ID,
+		Action:          escrowActionFund,
+		BuyerAccountID:  buyer.ID,
+		SellerAccountID: seller.ID,
+	}, tag)
+
+	// funding an escrow is free
+	require.Zero(t, result.Transfer.Fee)
+	require.Equal(t, int64(900), result.Transfer.FromAccount.Balance)
+	require.Equal(t, escrowAccount.Balance+100, result.Transfer.ToAccount.Balance)
+}
+
+func TestCreateEscrowTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	escrowTestAccount(t)
+	buyer := createFeeTestAccount(t, 50)
+	seller := createFeeTestAccount(t, 0)
+
+	_, err := store.CreateEscrowTx(context.Background(), CreateEscrowTxParams{
+		BuyerAccountID:  buyer.ID,
+		SellerAccountID: seller.ID,
+		Amount:          100,
+		CreatedBy:       buyer.Owner,
+		TimeoutAt:       pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		TimeoutAction:   EscrowActionRefund,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	escrows, err := store.ListEscrows(context.Background(), ListEscrowsParams{AccountID: buyer.ID, Limit: 5})
+	require.NoError(t, err)
+	require.Empty(t, escrows)
+}
+
+func TestSettleEscrowTx(t *testing.T) {
+	store := NewStore(testDB)
+	escrowTestAccount(t)
+	feeAccount := createFeeSchedules(t)
+	buyer := createFeeTestAccount(t, 1_000)
+	seller := createFeeTestAccount(t, 500)
+
+	released := createHeldEscrow(t, store, buyer, seller, time.Now().Add(time.Hour), EscrowActionRefund)
+	refunded := createHeldEscrow(t, store, buyer, seller, time.Now().Add(time.Hour), EscrowActionRefund)
+
+	result, err := store.SettleEscrowTx(context.Background(), SettleEscrowTxParams{
+		EscrowID:  released.Escrow.ID,
+		Action:    EscrowActionRelease,
+		SettledBy: buyer.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, EscrowStatusReleased, result.Escrow.Status)
+	require.Equal(t, buyer.Owner, result.Escrow.SettledBy)
+	require.True(t, result.Escrow.SettledAt.Valid)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Escrow.SettleTransferID.Int64)
+	require.Equal(t, seller.ID, result.Transfer.ToAccount.ID)
+
+	// the seller pays the release's fee out of the payout
+	require.Equal(t, int64(100), result.Transfer.Fee)
+	require.Equal(t, seller.ID, result.Transfer.FeeEntry.AccountID)
+	require.Equal(t, feeAccount.ID, result.Transfer.FeeAccountEntry.AccountID)
+	require.Equal(t, int64(500+100-100), result.Transfer.ToAccount.Balance)
+
+	result, err = store.SettleEscrowTx(context.Background(), SettleEscrowTxParams{
+		EscrowID:  refunded.Escrow.ID,
+		Action:    EscrowActionRefund,
+		SettledBy: seller.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, EscrowStatusRefunded, result.Escrow.Status)
+	require.Equal(t, buyer.ID, result.Transfer.ToAccount.ID)
+
+	// a refund returns the buyer's money in full
+	require.Zero(t, result.Transfer.Fee)
+	require.Equal(t, int64(900), result.Transfer.ToAccount.Balance)
+
+	// a settled escrow cannot be settled again
+	_, err = store.SettleEscrowTx(context.Background(), SettleEscrowTxParams{
+		EscrowID:  released.Escrow.ID,
+		Action:    EscrowActionRefund,
+		SettledBy: seller.Owner,
+	})
+	require.ErrorIs(t, err, ErrEscrowNotHeld)
+
+	// every movement is an ordinary transfer under the escrow's reference
+	transfers, err := store.ListTransfersByReference(context.Background(), ListTransfersByReferenceParams{
+		AccountID:         buyer.ID,
+		ExternalReference: escrowReference(refunded.Escrow.ID),
+		Limit:             5,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+}
+
+func TestSettleTimedOutEscrowTx(t *testing.T) {
+	store := NewStore(testDB)
+	escrowTestAccount(t)
+	buyer := createFeeTestAccount(t, 1_000)
+	seller := createFeeTestAccount(t, 0)
+
+	timedOut := createHeldEscrow(t, store, buyer, seller, time.Now().Add(time.Second), EscrowActionRelease)
+	pending := createHeldEscrow(t, store, buyer, seller, time.Now().Add(time.Hour), EscrowActionRefund)
+	time.Sleep(time.Second)
+
+	// other tests' escrows may also have timed out, so drain until ours
+	// has been settled
+	for {
+		result, err := store.SettleTimedOutEscrowTx(context.Background())
+		require.NoError(t, err)
+		if result.Escrow.ID == timedOut.Escrow.ID {
+			require.Equal(t, EscrowStatusReleased, result.Escrow.Status)
+			require.Empty(t, result.Escrow.SettledBy)
+			require.Equal(t, seller.ID, result.Transfer.ToAccount.ID)
+			break
+		}
+	}
+
+	escrow, err := store.GetEscrow(context.Background(), pending.Escrow.ID)
+	require.NoError(t, err)
+	require.Equal(t, EscrowStatusHeld, escrow.Status)
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrFeeAccountCurrency means the fee schedule points at an account in
+// another currency. It is a domain error so that an async transfer hitting
+// it is failed rather than retried forever.
+var ErrFeeAccountCurrency = newError(ErrorKindUnprocessable, "fee account currency does not match the transfer")
+
+// FeeFor is the flat fee plus PercentageBps of amount, rounded down.
+func (schedule FeeSchedule) FeeFor(amount int64) int64 {
+	return schedule.FlatFee + shareOf(amount, schedule.PercentageBps, 10_000)
+}
+
+// chargeFee moves the fee for a posted transfer from the sender to the fee
+// account of the tier that covers its amount, as a journal of its own so
+// that the transfer keeps exactly two entries. Currencies without a fee
+// schedule are free. transfer charges it on every customer path; the only
+// transfers exempt from it are
+//   - funding an escrow, whose fee is charged to the seller on release,
+//   - refunding an escrow, which returns the buyer's money in full,
+//   - compensations, which undo a transfer rather than make one, and
+//   - PostingTx journals, which are the bank's own bookings.
+func chargeFee(ctx context.Context, q *Queries, result *TransferTxResult) error {
+	return chargeFeeTo(ctx, q, result, &result.FromAccount)
+}
+
+// chargeFeeTo is chargeFee with the fee taken from payer, which points at
+// one of result's accounts and is updated with its new balance.
+func chargeFeeTo(ctx context.Context, q *Queries, result *TransferTxResult, payer *Account) error {
+	charged := *payer
+	schedule, err := q.GetFeeSchedule(ctx, GetFeeScheduleParams{
+		Currency: charged.Currency,
+		Amount:   result.Transfer.Amount,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	fee := schedule.FeeFor(result.Transfer.Amount)
+	if fee == 0 || schedule.FeeAccountID == charged.ID {
+		return nil
+	}
+
+	description := fmt.Sprintf("fee for transfer %d", result.Transfer.ID)
+	journal, err := q.CreateJournal(ctx, description)
+	if err != nil {
+		return err
+	}
+	journalID := pgtype.Int8{Int64: journal.ID, Valid: true}
+
+	result.FeeEntry, err = q.CreateJournalEntry(ctx, CreateJournalEntryParams{
+		Amount:      -fee,
+		AccountID:   charged.ID,
+		Description: description,
+		JournalID:   journalID,
+	})
+	if err != nil {
+		return err
+	}
+
+	result.FeeAccountEntry, err = q.CreateJournalEntry(ctx, CreateJournalEntryParams{
+		Amount:      fee,
+		AccountID:   schedule.FeeAccountID,
+		Description: description,
+		JournalID:   journalID,
+	})
+	if err != nil {
+		return err
+	}
+
+	*payer, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     charged.ID,
+		Amount: -fee,
+	})
+	if err != nil {
+		return err
+	}
+
+	feeAccount, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     schedule.FeeAccountID,
+		Amount: fee,
+	})
+	if err != nil {
+		return err
+	}
+
+	if feeAccount.Currency != charged.Currency {
+		return ErrFeeAccountCurrency
+	}
+
+	switch feeAccount.ID {
+	case result.FromAccount.ID:
+		result.FromAccount = feeAccount
+	case result.ToAccount.ID:
+		result.ToAccount = feeAccount
+	}
+
+	result.Fee = fee
+	return nil
+}
@@ -0,0 +1,266 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+// feeTestCurrency is the ISO 4217 code reserved for testing, so the fee
+// schedules created here never apply to other tests' transfers.
+const feeTestCurrency = "XTS"
+
+func createFeeTestAccount(t *testing.T, balance int64) Account {
+	user := createRandomUser(t)
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:         user.Username,
+		Balance:       balance,
+		Currency:      feeTestCurrency,
+		Type:          AccountTypeChecking,
+		AccountNumber: newTestAccountNumber(t),
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestFeeFor(t *testing.T) {
+	testCases := []struct {
+		schedule FeeSchedule
+		amount   int64
+		fee      int64
+	}{
+		{FeeSchedule{FlatFee: 50}, 10_000, 50},
+		{FeeSchedule{PercentageBps: 25}, 10_000, 25},
+		{FeeSchedule{PercentageBps: 25}, 399, 0},
+		{FeeSchedule{FlatFee: 10, PercentageBps: 150}, 1_000, 25},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.fee, tc.schedule.FeeFor(tc.amount))
+	}
+}
+
+// createFeeSchedules sets up the fee schedule of feeTestCurrency, a flat
+// 1.00 below 100.00 and 0.5% above it, and returns its fee account.
+func createFeeSchedules(t *testing.T) Account {
+	feeAccount := createFeeTestAccount(t, 0)
+	for _, arg := range []CreateFeeScheduleParams{
+		{Currency: feeTestCurrency, MinAmount: 0, FlatFee: 100, FeeAccountID: feeAccount.ID},
+		{Currency: feeTestCurrency, MinAmount: 10_000, PercentageBps: 50, FeeAccountID: feeAccount.ID},
+	} {
+		_, err := testQueries.CreateFeeSchedule(context.Background(), arg)
+		if err != nil {
+			// left over from an earlier run
+			require.ErrorContains(t, err, "duplicate key")
+		}
+	}
+
+	schedule, err := testQueries.GetFeeSchedule(context.Background(), GetFeeScheduleParams{Currency: feeTestCurrency, Amount: 10_000})
+	require.NoError(t, err)
+	feeAccount, err = testQueries.GetAccount(context.Background(), schedule.FeeAccountID)
+	require.NoError(t, err)
+	return feeAccount
+}
+
+func TestTransferTxFee(t *testing.T) {
+	store := NewStore(testDB)
+	feeAccount := createFeeSchedules(t)
+
+	sender := createFeeTestAccount(t, 100_000)
+	recipient := createFeeTestAccount(t, 0)
+
+	testCases := []struct {
+		amount int64
+		fee    int64
+	}{
+		{500, 100},
+		{30_000, 150},
+	}
+
+	balance := sender.Balance
+	for _, tc := range testCases {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: sender.ID,
+			ToAccountID:   recipient.ID,
+			Amount:        tc.amount,
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, tc.fee, result.Fee)
+		require.Equal(t, -tc.fee, result.FeeEntry.Amount)
+		require.Equal(t, sender.ID, result.FeeEntry.AccountID)
+		require.Equal(t, tc.fee, result.FeeAccountEntry.Amount)
+		require.Equal(t, feeAccount.ID, result.FeeAccountEntry.AccountID)
+
+		balance -= tc.amount + tc.fee
+		require.Equal(t, balance, result.FromAccount.Balance)
+	}
+
+	updated, err := testQueries.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, feeAccount.Balance+250, updated.Balance)
+
+	// the fee counts towards the funds the sender needs
+	poor := createFeeTestAccount(t, 500)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: poor.ID,
+		ToAccountID:   recipient.ID,
+		Amount:        500,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestTransferTxWithoutFeeSchedule(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	require.NotEqual(t, feeTestCurrency, account1.Currency)
+	require.Contains(t, []string{utils.USD, utils.EUR, utils.CAD}, account1.Currency)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Fee)
+	require.Empty(t, result.FeeEntry)
+	require.Empty(t, result.FeeAccountEntry)
+}
+
+// requireFeeCharged checks that the 1.00 fee for a transfer below 100.00
+// was taken from payer.
+func requireFeeCharged(t *testing.T, result TransferTxResult, payer Account) {
+	require.Equal(t, int64(100), result.Fee)
+	require.Equal(t, int64(-100), result.FeeEntry.Amount)
+	require.Equal(t, payer.ID, result.FeeEntry.AccountID)
+}
+
+func TestFeeOnCustomerPaths(t *testing.T) {
+	store := NewStore(testDB)
+	createFeeSchedules(t)
+
+	t.Run("Batch", func(t *testing.T) {
+		sender := createFeeTestAccount(t, 1_000)
+		recipient := createFeeTestAccount(t, 0)
+
+		result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+			Username:      sender.Owner,
+			FromAccountID: sender.ID,
+			Rows:          []BatchTransferRow{batchRow(1, recipient, 200), batchRow(2, recipient, 300)},
+		})
+		require.NoError(t, err)
+		require.Equal(t, TransferBatchStatusCompleted, result.Batch.Status)
+
+		updated, err := store.GetAccount(context.Background(), sender.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(1_000-500-2*100), updated.Balance)
+	})
+
+	t.Run("Scheduled", func(t *testing.T) {
+		sender := createFeeTestAccount(t, 1_000)
+		recipient := createFeeTestAccount(t, 0)
+		scheduled := createDueScheduledTransfer(t, sender, recipient, 500)
+
+		executed := executeUntil(t, store, scheduled.ID, ExecuteScheduledTransferTxParams{MaxAttempts: 3, RetryDelay: time.Hour})
+		require.Equal(t, ScheduledTransferStatusCompleted, executed.Status)
+
+		updated, err := store.GetAccount(context.Background(), sender.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(1_000-500-100), updated.Balance)
+	})
+
+	t.Run("CaptureHold", func(t *testing.T) {
+		sender := createFeeTestAccount(t, 1_000)
+		recipient := createFeeTestAccount(t, 0)
+		hold, err := store.PlaceHoldTx(context.Background(), PlaceHoldTxParams{
+			AccountID: sender.ID,
+			Amount:    500,
+			Reference: utils.RandomString(10),
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+			HoldID:      hold.ID,
+			ToAccountID: recipient.ID,
+		})
+		require.NoError(t, err)
+		requireFeeCharged(t, result.Transfer, sender)
+		require.Equal(t, int64(1_000-500-100), result.Transfer.FromAccount.Balance)
+	})
+
+	t.Run("Approval", func(t *testing.T) {
+		sender := createFeeTestAccount(t, 1_000)
+		recipient := createFeeTestAccount(t, 0)
+		approval := createPendingApproval(t, sender, recipient, 500)
+
+		result, err := store.ApproveTransferTx(context.Background(), ApproveTransferTxParams{
+			ApprovalID: approval.ID,
+			Approver:   recipient.Owner,
+		})
+		require.NoError(t, err)
+		requireFeeCharged(t, result.Transfer, sender)
+	})
+
+	t.Run("PaymentRequest", func(t *testing.T) {
+		sender := createFeeTestAccount(t, 1_000)
+		recipient := createFeeTestAccount(t, 0)
+		request := createPendingPaymentRequest(t, sender.Owner, recipient, 500, time.Now().Add(time.Hour))
+
+		result, err := store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+			PaymentRequestID: request.ID,
+			Payer:            sender.Owner,
+			FromAccountID:    sender.ID,
+		})
+		require.NoError(t, err)
+		requireFeeCharged(t, result.Transfer, sender)
+	})
+}
+
+func TestFeeExemptions(t *testing.T) {
+	store := NewStore(testDB)
+	createFeeSchedules(t)
+
+	t.Run("Reversal", func(t *testing.T) {
+		sender := createFeeTestAccount(t, 1_000)
+		recipient := createFeeTestAccount(t, 0)
+		original, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: sender.ID,
+			ToAccountID:   recipient.ID,
+			Amount:        500,
+		})
+		require.NoError(t, err)
+		requireFeeCharged(t, original, sender)
+
+		// undoing a transfer sends back the amount, neither charging the
+		// recipient nor returning the fee
+		reversal, err := store.ReverseTransferTx(context.Background(), original.Transfer.ID)
+		require.NoError(t, err)
+		require.Zero(t, reversal.Fee)
+		require.Zero(t, reversal.FromAccount.Balance)
+		require.Equal(t, int64(1_000-100), reversal.ToAccount.Balance)
+	})
+
+	t.Run("Posting", func(t *testing.T) {
+		payer := createFeeTestAccount(t, 1_000)
+		payee := createFeeTestAccount(t, 0)
+
+		result, err := store.PostingTx(context.Background(), PostingTxParams{
+			Description: "booking",
+			Legs: []PostingLeg{
+				{AccountID: payer.ID, Amount: -500},
+				{AccountID: payee.ID, Amount: 500},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Entries, 2)
+
+		updated, err := store.GetAccount(context.Background(), payer.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(500), updated.Balance)
+	})
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	FraudOutcomeAllow = "allow"
+	FraudOutcomeBlock = "block"
+	FraudOutcomeHold  = "hold"
+)
+
+type RecordFraudDecisionTxParams struct {
+	Decision CreateFraudDecisionParams `json:"decision"`
+	// Hold is the transfer to park for review when the outcome is hold.
+	Hold *CreateTransferApprovalParams `json:"hold"`
+	// Idempotency, when set, stores a held transfer's approval in the same
+	// transaction so a retried request replays it.
+	Idempotency *IdempotencyKeyParams `json:"-"`
+}
+
+type RecordFraudDecisionTxResult struct {
+	Decision FraudDecision `json:"decision"`
+	// Approval is empty unless the transfer was held.
+	Approval TransferApproval `json:"approval"`
+}
+
+// RecordFraudDecisionTx records the outcome of screening a transfer. A held
+// transfer is parked as a fraud_review approval in the same transaction and
+// the decision points at it.
+func (store *SQLStore) RecordFraudDecisionTx(ctx context.Context, arg RecordFraudDecisionTxParams) (RecordFraudDecisionTxResult, error) {
+	var result RecordFraudDecisionTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		result = RecordFraudDecisionTxResult{}
+		decision := arg.Decision
+		if arg.Hold != nil {
+			hold := *arg.Hold
+			hold.Reason = ApprovalReasonFraudReview
+
+			var err error
+			result.Approval, err = parkTransfer(ctx, q, hold)
+			if err != nil {
+				return err
+			}
+			decision.ApprovalID = pgtype.Int8{Int64: result.Approval.ID, Valid: true}
+
+			if arg.Idempotency != nil {
+				err = saveApprovalIdempotencyKey(ctx, q, *arg.Idempotency, result.Approval)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		var err error
+		result.Decision, err = q.CreateFraudDecision(ctx, decision)
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordFraudDecisionTxAllow(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := store.RecordFraudDecisionTx(context.Background(), RecordFraudDecisionTxParams{
+		Decision: CreateFraudDecisionParams{
+			Username:      account1.Owner,
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        100,
+			Outcome:       FraudOutcomeAllow,
+		},
+	})
+	require.NoError(t, err)
+	require.NotZero(t, result.Decision.ID)
+	require.Equal(t, FraudOutcomeAllow, result.Decision.Outcome)
+	require.False(t, result.Decision.ApprovalID.Valid)
+	require.Zero(t, result.Approval.ID)
+}
+
+func TestRecordFraudDecisionTxHold(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := store.RecordFraudDecisionTx(context.Background(), RecordFraudDecisionTxParams{
+		Decision: CreateFraudDecisionParams{
+			Username:      account1.Owner,
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        100_000,
+			Outcome:       FraudOutcomeHold,
+			Rule:          "large-first-payment",
+			Reason:        "first transfer to this recipient is 100000, limit 50000",
+		},
+		Hold: &CreateTransferApprovalParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        100_000,
+			Description:   "deposit",
+			Initiator:     account1.Owner,
+		},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, ApprovalReasonFraudReview, result.Approval.Reason)
+	require.Equal(t, TransferApprovalStatusPending, result.Approval.Status)
+	require.Equal(t, "deposit", result.Approval.Description)
+	require.True(t, result.Decision.ApprovalID.Valid)
+	require.Equal(t, result.Approval.ID, result.Decision.ApprovalID.Int64)
+	require.Equal(t, "large-first-payment", result.Decision.Rule)
+
+	decisions, err := testQueries.ListFraudDecisions(context.Background(), ListFraudDecisionsParams{
+		FromAccountID: account1.ID,
+		Limit:         5,
+	})
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	require.Equal(t, result.Decision.ID, decisions[0].ID)
+}
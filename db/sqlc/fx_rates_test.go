@@ -0,0 +1,30 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func TestGetLatestFxRate(t *testing.T) {
+	// a pair no other test uses, so older rows cannot interfere
+	base := utils.RandomString(3)
+	arg := CreateFxRateParams{BaseCurrency: base, QuoteCurrency: utils.USD, Rate: 100_000_000}
+
+	_, err := testQueries.CreateFxRate(context.Background(), arg)
+	require.NoError(t, err)
+
+	arg.Rate = 101_000_000
+	latest, err := testQueries.CreateFxRate(context.Background(), arg)
+	require.NoError(t, err)
+
+	rate, err := testQueries.GetLatestFxRate(context.Background(), GetLatestFxRateParams{
+		BaseCurrency:  base,
+		QuoteCurrency: utils.USD,
+	})
+	require.NoError(t, err)
+	require.Equal(t, latest.ID, rate.ID)
+	require.Equal(t, arg.Rate, rate.Rate)
+}
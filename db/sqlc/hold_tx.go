@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+)
+
+var (
+	ErrHoldNotActive      = newError(ErrorKindConflict, "hold is not active")
+	ErrHoldExpired        = newError(ErrorKindConflict, "hold has expired")
+	ErrHoldAmountExceeded = newError(ErrorKindInvalid, "capture amount exceeds the held amount")
+)
+
+type PlaceHoldTxParams struct {
+	AccountID int64     `json:"account_id"`
+	Amount    int64     `json:"amount"`
+	Reference string    `json:"reference"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PlaceHoldTx reserves funds on an account. The account row is locked so
+// that concurrent holds and transfers cannot overdraw the available balance.
+// A hold cannot wait for a second approver, so one above the account's
+// approval threshold is refused with ErrApprovalRequired.
+func (store *SQLStore) PlaceHoldTx(ctx context.Context, arg PlaceHoldTxParams) (Hold, error) {
+	var hold Hold
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if account.RequiresApproval(arg.Amount) {
+			return ErrApprovalRequired
+		}
+
+		held, err := q.GetActiveHoldsAmount(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		if account.Balance-held < arg.Amount {
+			return ErrInsufficientFunds
+		}
+
+		hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID: arg.AccountID,
+			Amount:    arg.Amount,
+			Reference: arg.Reference,
+			ExpiresAt: pgtype.Timestamptz{Time: arg.ExpiresAt, Valid: true},
+		})
+		return err
+	})
+
+	return hold, err
+}
+
+type CaptureHoldTxParams struct {
+	HoldID      int64 `json:"hold_id"`
+	ToAccountID int64 `json:"to_account_id"`
+	// Amount defaults to the full held amount when zero.
+	Amount int64 `json:"amount"`
+}
+
+type CaptureHoldTxResult struct {
+	Hold     Hold             `json:"hold"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// CaptureHoldTx settles a hold by turning it into a transfer. Capturing
+// less than the held amount releases the remainder.
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if hold.Status != HoldStatusActive {
+			return ErrHoldNotActive
+		}
+
+		if !hold.ExpiresAt.Time.After(time.Now()) {
+			return ErrHoldExpired
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+
+		if amount > hold.Amount {
+			return ErrHoldAmountExceeded
+		}
+
+		result.Transfer, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: hold.AccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        amount,
+			// the merchant's reference identifies the capture
+			ExternalReference: hold.Reference,
+			// the threshold was checked when the hold was placed
+			Approved: true,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.CaptureHold(ctx, CaptureHoldParams{
+			ID:         hold.ID,
+			TransferID: pgtype.Int8{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = checkAvailableBalance(ctx, q, result.Transfer.FromAccount)
+		if err != nil {
+			return err
+		}
+
+		return recordTransferEvent(ctx, q, result.Transfer.Transfer)
+	})
+
+	return result, err
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func createRandomHold(t *testing.T, account Account) Hold {
+	arg := CreateHoldParams{
+		AccountID: account.ID,
+		Amount:    utils.RandomInt(1, 10),
+		Reference: utils.RandomString(10),
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	hold, err := testQueries.CreateHold(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, hold)
+
+	require.Equal(t, arg.AccountID, hold.AccountID)
+	require.Equal(t, arg.Amount, hold.Amount)
+	require.Equal(t, arg.Reference, hold.Reference)
+	require.Equal(t, HoldStatusActive, hold.Status)
+	require.False(t, hold.TransferID.Valid)
+	require.WithinDuration(t, arg.ExpiresAt.Time, hold.ExpiresAt.Time, time.Second)
+
+	require.NotZero(t, hold.ID)
+	require.NotZero(t, hold.CreatedAt)
+	return hold
+}
+
+func TestCreateHold(t *testing.T) {
+	account := createRandomAccount(t)
+	createRandomHold(t, account)
+}
+
+func TestGetHold(t *testing.T) {
+	account := createRandomAccount(t)
+	hold1 := createRandomHold(t, account)
+	hold2, err := testQueries.GetHold(context.Background(), hold1.ID)
+	require.NoError(t, err)
+	require.Equal(t, hold1.ID, hold2.ID)
+	require.Equal(t, hold1.AccountID, hold2.AccountID)
+	require.Equal(t, hold1.Amount, hold2.Amount)
+	require.Equal(t, hold1.Status, hold2.Status)
+}
+
+func TestGetActiveHoldsAmount(t *testing.T) {
+	account := createRandomAccount(t)
+	hold1 := createRandomHold(t, account)
+	hold2 := createRandomHold(t, account)
+
+	_, err := testQueries.CreateHold(context.Background(), CreateHoldParams{
+		AccountID: account.ID,
+		Amount:    100,
+		Reference: utils.RandomString(10),
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+
+	released := createRandomHold(t, account)
+	_, err = testQueries.ReleaseHold(context.Background(), released.ID)
+	require.NoError(t, err)
+
+	total, err := testQueries.GetActiveHoldsAmount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, hold1.Amount+hold2.Amount, total)
+}
+
+func TestReleaseHold(t *testing.T) {
+	account := createRandomAccount(t)
+	hold1 := createRandomHold(t, account)
+
+	hold2, err := testQueries.ReleaseHold(context.Background(), hold1.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusReleased, hold2.Status)
+
+	_, err = testQueries.ReleaseHold(context.Background(), hold1.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
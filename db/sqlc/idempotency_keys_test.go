@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func TestTransferTxIdempotencyKey(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	idempotency := &IdempotencyKeyParams{
+		Username:    account1.Owner,
+		Key:         utils.RandomString(16),
+		RequestHash: utils.RandomString(64),
+	}
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Idempotency:   idempotency,
+	}
+
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	stored, err := store.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: idempotency.Username,
+		Key:      idempotency.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, idempotency.RequestHash, stored.RequestHash)
+	require.Equal(t, result.Transfer.ID, stored.TransferID.Int64)
+	require.False(t, stored.ApprovalID.Valid)
+	require.Equal(t, int32(http.StatusOK), stored.StatusCode)
+
+	var replayed TransferTxResult
+	require.NoError(t, json.Unmarshal(stored.Response, &replayed))
+	require.Equal(t, result.Transfer.ID, replayed.Transfer.ID)
+	require.Equal(t, result.FromAccount.Balance, replayed.FromAccount.Balance)
+
+	// the same key rolls the second transfer back
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrDuplicateIdempotencyKey)
+
+	fromAccount, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.FromAccount.Balance, fromAccount.Balance)
+}
+
+func TestCreatePendingTransferTxIdempotencyKey(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	idempotency := &IdempotencyKeyParams{
+		Username:    account1.Owner,
+		Key:         utils.RandomString(16),
+		RequestHash: utils.RandomString(64),
+	}
+	recorded, err := store.CreatePendingTransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Idempotency:   idempotency,
+	})
+	require.NoError(t, err)
+
+	stored, err := store.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: idempotency.Username,
+		Key:      idempotency.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, recorded.ID, stored.TransferID.Int64)
+	require.Equal(t, int32(http.StatusAccepted), stored.StatusCode)
+}
+
+func TestCreateTransferApprovalTxIdempotencyKey(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	idempotency := &IdempotencyKeyParams{
+		Username:    account1.Owner,
+		Key:         utils.RandomString(16),
+		RequestHash: utils.RandomString(64),
+	}
+	arg := CreateTransferApprovalTxParams{
+		Approval: CreateTransferApprovalParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+			Initiator:     account1.Owner,
+			Reason:        ApprovalReasonThreshold,
+		},
+		Idempotency: idempotency,
+	}
+
+	approval, err := store.CreateTransferApprovalTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	stored, err := store.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: idempotency.Username,
+		Key:      idempotency.Key,
+	})
+	require.NoError(t, err)
+	require.False(t, stored.TransferID.Valid)
+	require.Equal(t, approval.ID, stored.ApprovalID.Int64)
+	require.Equal(t, int32(http.StatusAccepted), stored.StatusCode)
+
+	var replayed TransferApproval
+	require.NoError(t, json.Unmarshal(stored.Response, &replayed))
+	require.Equal(t, approval.ID, replayed.ID)
+
+	// the same key rolls the second approval back
+	_, err = store.CreateTransferApprovalTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrDuplicateIdempotencyKey)
+
+	approvals, err := store.ListTransferApprovals(context.Background(), ListTransferApprovalsParams{
+		FromAccountID: account1.ID,
+		Limit:         5,
+	})
+	require.NoError(t, err)
+	require.Len(t, approvals, 1)
+}
+
+func TestRecordFraudDecisionTxIdempotencyKey(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	idempotency := &IdempotencyKeyParams{
+		Username:    account1.Owner,
+		Key:         utils.RandomString(16),
+		RequestHash: utils.RandomString(64),
+	}
+	result, err := store.RecordFraudDecisionTx(context.Background(), RecordFraudDecisionTxParams{
+		Decision: CreateFraudDecisionParams{
+			Username:      account1.Owner,
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        100_000,
+			Outcome:       FraudOutcomeHold,
+			Rule:          "large-first-payment",
+			Reason:        "first transfer to this recipient is 100000, limit 50000",
+		},
+		Hold: &CreateTransferApprovalParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        100_000,
+			Initiator:     account1.Owner,
+			Reason:        ApprovalReasonFraudReview,
+		},
+		Idempotency: idempotency,
+	})
+	require.NoError(t, err)
+
+	stored, err := store.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: idempotency.Username,
+		Key:      idempotency.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, result.Approval.ID, stored.ApprovalID.Int64)
+	require.Equal(t, int32(http.StatusAccepted), stored.StatusCode)
+}
@@ -5,6 +5,8 @@
 package db
 
 import (
+	"encoding/json"
+
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -14,16 +16,253 @@ type Account struct {
 	Balance   int64              `json:"balance"`
 	Currency  string             `json:"currency"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// checking or savings
+	Type          string `json:"type"`
+	Nickname      string `json:"nickname"`
+	AccountNumber string `json:"account_number"`
+	// transfers above it need a second approver, 0 disables approvals
+	ApprovalThreshold int64 `json:"approval_threshold"`
+}
+
+type AccountMember struct {
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+	// view, transact or manage
+	Permission string             `json:"permission"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type Alias struct {
+	// lower-cased email or E.164 phone number
+	Alias string `json:"alias"`
+	// email or phone
+	Kind     string `json:"kind"`
+	Username string `json:"username"`
+	// unverified aliases do not resolve
+	VerifiedAt pgtype.Timestamptz `json:"verified_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type ApprovalThresholdChange struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// the threshold the account gets once the change is approved
+	ApprovalThreshold int64  `json:"approval_threshold"`
+	RequestedBy       string `json:"requested_by"`
+	// pending, approved or rejected
+	Status    string             `json:"status"`
+	DecidedBy string             `json:"decided_by"`
+	DecidedAt pgtype.Timestamptz `json:"decided_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type Entry struct {
 	ID int64 `json:"id"`
 	// can be neg or pos number
-	Amount    int64              `json:"amount"`
+	Amount      int64              `json:"amount"`
+	AccountID   int64              `json:"account_id"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	Description string             `json:"description"`
+	// the posting or transfer fee the entry belongs to, empty for transfer entries
+	JournalID pgtype.Int8 `json:"journal_id"`
+	// the transfer the entry is a leg of, empty for posting and fee entries
+	TransferID pgtype.Int8 `json:"transfer_id"`
+}
+
+type Escrow struct {
+	ID              int64  `json:"id"`
+	BuyerAccountID  int64  `json:"buyer_account_id"`
+	SellerAccountID int64  `json:"seller_account_id"`
+	EscrowAccountID int64  `json:"escrow_account_id"`
+	Amount          int64  `json:"amount"`
+	Currency        string `json:"currency"`
+	Description     string `json:"description"`
+	CreatedBy       string `json:"created_by"`
+	// held, released or refunded
+	Status    string             `json:"status"`
+	TimeoutAt pgtype.Timestamptz `json:"timeout_at"`
+	// release or refund, applied when the escrow is still held at timeout_at
+	TimeoutAction string `json:"timeout_action"`
+	// the transfer from the buyer into the escrow account
+	FundTransferID pgtype.Int8 `json:"fund_transfer_id"`
+	// the transfer out of the escrow account to the seller or back to the buyer
+	SettleTransferID pgtype.Int8 `json:"settle_transfer_id"`
+	// who released or refunded the escrow, empty when it timed out
+	SettledBy string             `json:"settled_by"`
+	SettledAt pgtype.Timestamptz `json:"settled_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type EscrowAccount struct {
+	Currency string `json:"currency"`
+	// system account that holds escrowed funds in the currency
 	AccountID int64              `json:"account_id"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
+type FeeSchedule struct {
+	ID       int64  `json:"id"`
+	Currency string `json:"currency"`
+	// the tier covers amounts from here up to the next tier
+	MinAmount int64 `json:"min_amount"`
+	FlatFee   int64 `json:"flat_fee"`
+	// charged on top of the flat fee, in basis points of the amount
+	PercentageBps int64 `json:"percentage_bps"`
+	// system account credited with the fee, in the same currency
+	FeeAccountID int64              `json:"fee_account_id"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type FraudDecision struct {
+	ID            int64  `json:"id"`
+	Username      string `json:"username"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	// allow, block or hold
+	Outcome string `json:"outcome"`
+	// the rule that fired, empty when the transfer was allowed
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+	// the approval a held transfer waits on
+	ApprovalID pgtype.Int8        `json:"approval_id"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type FxRate struct {
+	ID            int64  `json:"id"`
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	// quote units per base unit, scaled by 1e8
+	Rate      int64              `json:"rate"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type Hold struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// it must be pos num
+	Amount    int64  `json:"amount"`
+	Reference string `json:"reference"`
+	// active, captured or released
+	Status     string             `json:"status"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	TransferID pgtype.Int8        `json:"transfer_id"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Username string `json:"username"`
+	Key      string `json:"key"`
+	// sha256 of the request body
+	RequestHash string `json:"request_hash"`
+	// serialized transfer result
+	Response   []byte             `json:"response"`
+	TransferID pgtype.Int8        `json:"transfer_id"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	// HTTP status of the stored response, 202 for async transfers
+	StatusCode int32 `json:"status_code"`
+	// set instead of transfer_id when the transfer was parked for approval
+	ApprovalID pgtype.Int8 `json:"approval_id"`
+}
+
+type Journal struct {
+	ID          int64              `json:"id"`
+	Description string             `json:"description"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type Outbox struct {
+	// publish order, writers hold an advisory lock so ids commit in order
+	ID int64 `json:"id"`
+	// user, account or transfer
+	AggregateType string `json:"aggregate_type"`
+	AggregateID   string `json:"aggregate_id"`
+	EventType     string `json:"event_type"`
+	// version of the payload schema of event_type
+	Version   int32              `json:"version"`
+	Payload   json.RawMessage    `json:"payload"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type OutboxRelay struct {
+	Name string `json:"name"`
+	// id of the last event the relay published
+	Position  int64              `json:"position"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type PaymentRequest struct {
+	ID        int64  `json:"id"`
+	Requester string `json:"requester"`
+	Payer     string `json:"payer"`
+	// the requester's account the money is paid into
+	ToAccountID int64  `json:"to_account_id"`
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+	Description string `json:"description"`
+	// pending, paid, declined or cancelled
+	Status    string             `json:"status"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	// the transfer that paid the request
+	TransferID pgtype.Int8        `json:"transfer_id"`
+	DecidedAt  pgtype.Timestamptz `json:"decided_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type Payee struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username"`
+	Nickname  string `json:"nickname"`
+	AccountID int64  `json:"account_id"`
+	Currency  string `json:"currency"`
+	// transfers to the payee are capped at a lower limit until then
+	CoolingOffUntil pgtype.Timestamptz `json:"cooling_off_until"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Username      string `json:"username"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	// it must be pos num
+	Amount    int64              `json:"amount"`
+	ExecuteAt pgtype.Timestamptz `json:"execute_at"`
+	// pending, completed, failed or cancelled
+	Status            string             `json:"status"`
+	Attempts          int32              `json:"attempts"`
+	NextAttemptAt     pgtype.Timestamptz `json:"next_attempt_at"`
+	FailureReason     string             `json:"failure_reason"`
+	TransferID        pgtype.Int8        `json:"transfer_id"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	StandingOrderID   pgtype.Int8        `json:"standing_order_id"`
+	Description       string             `json:"description"`
+	ExternalReference string             `json:"external_reference"`
+	Metadata          json.RawMessage    `json:"metadata"`
+}
+
+type StandingOrder struct {
+	ID            int64  `json:"id"`
+	Username      string `json:"username"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	// it must be pos num
+	Amount int64 `json:"amount"`
+	// e.g. weekly:mon, monthly:15 or monthly:last-business-day
+	Schedule string `json:"schedule"`
+	// active, paused or completed
+	Status            string             `json:"status"`
+	NextRunAt         pgtype.Timestamptz `json:"next_run_at"`
+	EndAt             pgtype.Timestamptz `json:"end_at"`
+	MaxRuns           pgtype.Int4        `json:"max_runs"`
+	Runs              int32              `json:"runs"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	Description       string             `json:"description"`
+	ExternalReference string             `json:"external_reference"`
+	Metadata          json.RawMessage    `json:"metadata"`
+}
+
 type Transfer struct {
 	ID int64 `json:"id"`
 	// it must be pos num
@@ -31,6 +270,80 @@ type Transfer struct {
 	FromAccountID int64              `json:"from_account_id"`
 	ToAccountID   int64              `json:"to_account_id"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	// amount credited in the destination currency
+	ToAmount int64 `json:"to_amount"`
+	// scaled by 1e8, 1e8 for same-currency transfers
+	FxRate int64 `json:"fx_rate"`
+	// spread applied to the rate in basis points
+	SpreadBps int64 `json:"spread_bps"`
+	// transfer, reversal or refund
+	Kind string `json:"kind"`
+	// the transfer a reversal or refund compensates
+	OriginalTransferID pgtype.Int8 `json:"original_transfer_id"`
+	Description        string      `json:"description"`
+	// caller supplied reference, e.g. an invoice number
+	ExternalReference string `json:"external_reference"`
+	// caller supplied JSON object
+	Metadata json.RawMessage `json:"metadata"`
+	// pending, processing, completed, failed or reversed
+	Status       string             `json:"status"`
+	ProcessingAt pgtype.Timestamptz `json:"processing_at"`
+	CompletedAt  pgtype.Timestamptz `json:"completed_at"`
+	FailedAt     pgtype.Timestamptz `json:"failed_at"`
+	ReversedAt   pgtype.Timestamptz `json:"reversed_at"`
+	// why an asynchronous transfer failed
+	FailureReason string `json:"failure_reason"`
+}
+
+type TransferApproval struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// it must be pos num
+	Amount            int64           `json:"amount"`
+	Description       string          `json:"description"`
+	ExternalReference string          `json:"external_reference"`
+	Metadata          json.RawMessage `json:"metadata"`
+	Initiator         string          `json:"initiator"`
+	// pending, approved or rejected
+	Status string `json:"status"`
+	// the approver who approved or rejected the transfer
+	DecidedBy  string             `json:"decided_by"`
+	DecidedAt  pgtype.Timestamptz `json:"decided_at"`
+	Note       string             `json:"note"`
+	TransferID pgtype.Int8        `json:"transfer_id"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	// threshold, or fraud_review when a fraud rule held the transfer
+	Reason string `json:"reason"`
+	// the payment request the transfer pays, marked paid when it is approved
+	PaymentRequestID pgtype.Int8 `json:"payment_request_id"`
+}
+
+type TransferBatch struct {
+	ID            int64  `json:"id"`
+	Username      string `json:"username"`
+	FromAccountID int64  `json:"from_account_id"`
+	AllowPartial  bool   `json:"allow_partial"`
+	// processing, completed, partial or failed
+	Status    string             `json:"status"`
+	Succeeded int32              `json:"succeeded"`
+	Failed    int32              `json:"failed"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type TransferBatchItem struct {
+	ID        int64 `json:"id"`
+	BatchID   int64 `json:"batch_id"`
+	RowNumber int32 `json:"row_number"`
+	// account number as submitted
+	ToAccount string `json:"to_account"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Memo      string `json:"memo"`
+	// succeeded or failed
+	Status     string      `json:"status"`
+	Error      string      `json:"error"`
+	TransferID pgtype.Int8 `json:"transfer_id"`
 }
 
 type User struct {
@@ -40,4 +353,6 @@ type User struct {
 	HashedPassword    string             `json:"hashed_password"`
 	PasswordChangedAt pgtype.Timestamptz `json:"password_changed_at"`
 	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	// depositor or banker
+	Role string `json:"role"`
 }
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	AggregateUser     = "user"
+	AggregateAccount  = "account"
+	AggregateTransfer = "transfer"
+	AggregateJournal  = "journal"
+)
+
+// Event types written to the outbox. A payload's schema is identified by
+// its event type and version; a breaking change to a payload bumps the
+// version so consumers can tell the two apart.
+const (
+	EventUserCreated       = "user.created"
+	EventAccountCreated    = "account.created"
+	EventTransferCompleted = "transfer.completed"
+	EventTransferFailed    = "transfer.failed"
+	// EventTransferReversed is recorded for the original transfer once
+	// refunds and reversals have sent all of it back.
+	EventTransferReversed = "transfer.reversed"
+	EventJournalPosted    = "journal.posted"
+)
+
+var eventVersions = map[string]int32{
+	EventUserCreated:       1,
+	EventAccountCreated:    1,
+	EventTransferCompleted: 1,
+	EventTransferFailed:    1,
+	EventTransferReversed:  1,
+	EventJournalPosted:     1,
+}
+
+// UserEvent is the payload of user events. It leaves out the password
+// hash, which must never leave the database.
+type UserEvent struct {
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	FullName  string    `json:"full_name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// JournalEvent is the payload of journal.posted events.
+type JournalEvent struct {
+	Journal Journal `json:"journal"`
+	Entries []Entry `json:"entries"`
+}
+
+// recordEvent writes an event to the outbox as part of the caller's
+// transaction, so it is published if and only if the transaction commits.
+// It takes the outbox lock, which is held until commit: ids are then
+// committed in the order they are assigned and a relay that has published
+// up to some id cannot miss a smaller one committed later. Callers should
+// record their event last to keep the lock short.
+func recordEvent(ctx context.Context, q *Queries, aggregateType, aggregateID, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	err = q.LockOutbox(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Version:       eventVersions[eventType],
+		Payload:       data,
+	})
+	return err
+}
+
+// recordTransferEvent records the event for a transfer's status. Every Tx
+// that moves money calls it as its last statement, after the fee and the
+// funds check, so the outbox lock is always taken after the row locks.
+func recordTransferEvent(ctx context.Context, q *Queries, transfer Transfer) error {
+	eventType := EventTransferCompleted
+	switch transfer.Status {
+	case TransferStatusFailed:
+		eventType = EventTransferFailed
+	case TransferStatusReversed:
+		eventType = EventTransferReversed
+	}
+
+	return recordEvent(ctx, q, AggregateTransfer, strconv.FormatInt(transfer.ID, 10), eventType, transfer)
+}
+
+func recordAccountCreated(ctx context.Context, q *Queries, account Account) error {
+	return recordEvent(ctx, q, AggregateAccount, strconv.FormatInt(account.ID, 10), EventAccountCreated, account)
+}
+
+// CreateUserTx creates a user and records a user.created event with it.
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordEvent(ctx, q, AggregateUser, user.Username, EventUserCreated, UserEvent{
+			Username:  user.Username,
+			Email:     user.Email,
+			FullName:  user.FullName,
+			Role:      user.Role,
+			CreatedAt: user.CreatedAt.Time,
+		})
+	})
+
+	return user, err
+}
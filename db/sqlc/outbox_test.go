@@ -0,0 +1,281 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+// lastOutboxID returns the id of the newest event, so a test can look only
+// at the events written after it.
+func lastOutboxID(t *testing.T) int64 {
+	var id int64
+	err := testDB.QueryRow(context.Background(), "SELECT COALESCE(max(id), 0) FROM outbox").Scan(&id)
+	require.NoError(t, err)
+	return id
+}
+
+// findOutboxEvent returns the single event of the aggregate written after
+// the given id, failing the test unless there is exactly one.
+func findOutboxEvent(t *testing.T, after int64, aggregateType, aggregateID string) Outbox {
+	events, err := testQueries.ListOutboxEvents(context.Background(), ListOutboxEventsParams{
+		AfterID: after,
+		Limit:   1000,
+	})
+	require.NoError(t, err)
+
+	var found []Outbox
+	for i, event := range events {
+		if i > 0 {
+			require.Greater(t, event.ID, events[i-1].ID)
+		}
+		if event.AggregateType == aggregateType && event.AggregateID == aggregateID {
+			found = append(found, event)
+		}
+	}
+	require.Len(t, found, 1)
+	return found[0]
+}
+
+func TestCreateUserTxRecordsEvent(t *testing.T) {
+	store := NewStore(testDB)
+	after := lastOutboxID(t)
+
+	hashedPassword, err := utils.HashPassword(utils.RandomString(6))
+	require.NoError(t, err)
+	user, err := store.CreateUserTx(context.Background(), CreateUserParams{
+		Username:       utils.RandomOwner(),
+		HashedPassword: hashedPassword,
+		Email:          utils.RandomEmail(),
+		FullName:       utils.RandomOwner(),
+	})
+	require.NoError(t, err)
+
+	event := findOutboxEvent(t, after, AggregateUser, user.Username)
+	require.Equal(t, EventUserCreated, event.EventType)
+	require.Equal(t, int32(1), event.Version)
+	require.NotContains(t, string(event.Payload), hashedPassword)
+
+	var payload UserEvent
+	require.NoError(t, json.Unmarshal(event.Payload, &payload))
+	require.Equal(t, user.Username, payload.Username)
+	require.Equal(t, user.Email, payload.Email)
+
+	// a failed insert records nothing
+	after = lastOutboxID(t)
+	_, err = store.CreateUserTx(context.Background(), CreateUserParams{
+		Username:       user.Username,
+		HashedPassword: hashedPassword,
+		Email:          utils.RandomEmail(),
+		FullName:       utils.RandomOwner(),
+	})
+	require.Error(t, err)
+
+	events, err := testQueries.ListOutboxEvents(context.Background(), ListOutboxEventsParams{AfterID: after, Limit: 1000})
+	require.NoError(t, err)
+	for _, event := range events {
+		require.False(t, event.AggregateType == AggregateUser && event.AggregateID == user.Username)
+	}
+}
+
+func TestCreateAccountTxRecordsEvent(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	after := lastOutboxID(t)
+
+	account, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.Username,
+			Currency: utils.USD,
+			Type:     AccountTypeChecking,
+		},
+	})
+	require.NoError(t, err)
+
+	event := findOutboxEvent(t, after, AggregateAccount, strconv.FormatInt(account.ID, 10))
+	require.Equal(t, EventAccountCreated, event.EventType)
+
+	var payload Account
+	require.NoError(t, json.Unmarshal(event.Payload, &payload))
+	require.Equal(t, account.ID, payload.ID)
+	require.Equal(t, account.AccountNumber, payload.AccountNumber)
+}
+
+func TestTransferTxRecordsEvent(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	after := lastOutboxID(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	event := findOutboxEvent(t, after, AggregateTransfer, strconv.FormatInt(result.Transfer.ID, 10))
+	require.Equal(t, EventTransferCompleted, event.EventType)
+
+	var payload Transfer
+	require.NoError(t, json.Unmarshal(event.Payload, &payload))
+	require.Equal(t, result.Transfer.ID, payload.ID)
+	require.Equal(t, int64(10), payload.Amount)
+
+	// a transfer that is rolled back records nothing
+	after = lastOutboxID(t)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	events, err := testQueries.ListOutboxEvents(context.Background(), ListOutboxEventsParams{AfterID: after, Limit: 1000})
+	require.NoError(t, err)
+	for _, event := range events {
+		if event.AggregateType == AggregateTransfer {
+			var payload Transfer
+			require.NoError(t, json.Unmarshal(event.Payload, &payload))
+			require.NotEqual(t, account1.ID, payload.FromAccountID)
+		}
+	}
+}
+
+func TestApproveTransferTxRecordsEvent(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	approver := createRandomUser(t)
+	approval := createPendingApproval(t, account1, account2, 10)
+	after := lastOutboxID(t)
+
+	result, err := store.ApproveTransferTx(context.Background(), ApproveTransferTxParams{
+		ApprovalID: approval.ID,
+		Approver:   approver.Username,
+	})
+	require.NoError(t, err)
+
+	event := findOutboxEvent(t, after, AggregateTransfer, strconv.FormatInt(result.Transfer.Transfer.ID, 10))
+	require.Equal(t, EventTransferCompleted, event.EventType)
+}
+
+func TestBatchTransferTxRecordsEvents(t *testing.T) {
+	store := NewStore(testDB)
+	fromAccount := createRandomAccount(t)
+	payee := createRandomAccount(t)
+	after := lastOutboxID(t)
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Username:      fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		AllowPartial:  true,
+		Rows: []BatchTransferRow{
+			batchRow(1, payee, 10),
+			batchRow(2, payee, fromAccount.Balance),
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Items, 2)
+
+	// one event per paid row; the row rolled back to its savepoint left
+	// none behind
+	paid := result.Items[0]
+	require.Equal(t, TransferBatchItemStatusSucceeded, paid.Status)
+	event := findOutboxEvent(t, after, AggregateTransfer, strconv.FormatInt(paid.TransferID.Int64, 10))
+	require.Equal(t, EventTransferCompleted, event.EventType)
+
+	require.Equal(t, TransferBatchItemStatusFailed, result.Items[1].Status)
+	events, err := testQueries.ListOutboxEvents(context.Background(), ListOutboxEventsParams{AfterID: after, Limit: 1000})
+	require.NoError(t, err)
+	var fromBatch int
+	for _, event := range events {
+		if event.AggregateType != AggregateTransfer {
+			continue
+		}
+		var payload Transfer
+		require.NoError(t, json.Unmarshal(event.Payload, &payload))
+		if payload.FromAccountID == fromAccount.ID {
+			fromBatch++
+		}
+	}
+	require.Equal(t, 1, fromBatch)
+}
+
+func TestReverseTransferTxRecordsEvents(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	after := lastOutboxID(t)
+
+	reversal, err := store.ReverseTransferTx(context.Background(), original.Transfer.ID)
+	require.NoError(t, err)
+
+	event := findOutboxEvent(t, after, AggregateTransfer, strconv.FormatInt(reversal.Transfer.ID, 10))
+	require.Equal(t, EventTransferCompleted, event.EventType)
+
+	event = findOutboxEvent(t, after, AggregateTransfer, strconv.FormatInt(original.Transfer.ID, 10))
+	require.Equal(t, EventTransferReversed, event.EventType)
+
+	var payload Transfer
+	require.NoError(t, json.Unmarshal(event.Payload, &payload))
+	require.Equal(t, TransferStatusReversed, payload.Status)
+}
+
+func TestPostingTxRecordsEvent(t *testing.T) {
+	store := NewStore(testDB)
+	payer := createPostingTestAccount(t, utils.USD, 100)
+	payee := createPostingTestAccount(t, utils.USD, 0)
+	after := lastOutboxID(t)
+
+	result, err := store.PostingTx(context.Background(), PostingTxParams{
+		Description: "sweep",
+		Legs: []PostingLeg{
+			{AccountID: payer.ID, Amount: -100},
+			{AccountID: payee.ID, Amount: 100},
+		},
+	})
+	require.NoError(t, err)
+
+	event := findOutboxEvent(t, after, AggregateJournal, strconv.FormatInt(result.Journal.ID, 10))
+	require.Equal(t, EventJournalPosted, event.EventType)
+
+	var payload JournalEvent
+	require.NoError(t, json.Unmarshal(event.Payload, &payload))
+	require.Equal(t, result.Journal.ID, payload.Journal.ID)
+	require.Len(t, payload.Entries, 2)
+}
+
+func TestSaveOutboxPosition(t *testing.T) {
+	name := utils.RandomString(10)
+
+	_, err := testQueries.GetOutboxPosition(context.Background(), name)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	err = testQueries.SaveOutboxPosition(context.Background(), SaveOutboxPositionParams{Name: name, Position: 5})
+	require.NoError(t, err)
+
+	position, err := testQueries.GetOutboxPosition(context.Background(), name)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), position)
+
+	// a relay that lost a race with its own earlier run never moves back
+	err = testQueries.SaveOutboxPosition(context.Background(), SaveOutboxPositionParams{Name: name, Position: 3})
+	require.NoError(t, err)
+
+	position, err = testQueries.GetOutboxPosition(context.Background(), name)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), position)
+}
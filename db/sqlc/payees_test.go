@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func createRandomPayee(t *testing.T, user User, account Account) Payee {
+	arg := CreatePayeeParams{
+		Username:        user.Username,
+		Nickname:        utils.RandomOwner(),
+		AccountID:       account.ID,
+		Currency:        account.Currency,
+		CoolingOffUntil: pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+	payee, err := testQueries.CreatePayee(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.NotZero(t, payee.ID)
+	require.Equal(t, arg.Username, payee.Username)
+	require.Equal(t, arg.Nickname, payee.Nickname)
+	require.Equal(t, arg.AccountID, payee.AccountID)
+	require.Equal(t, arg.Currency, payee.Currency)
+	require.WithinDuration(t, arg.CoolingOffUntil.Time, payee.CoolingOffUntil.Time, time.Second)
+	return payee
+}
+
+func TestPayees(t *testing.T) {
+	user := createRandomUser(t)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	payee1 := createRandomPayee(t, user, account1)
+	payee2 := createRandomPayee(t, user, account2)
+
+	// an account can only be added once
+	_, err := testQueries.CreatePayee(context.Background(), CreatePayeeParams{
+		Username:        user.Username,
+		Nickname:        "again",
+		AccountID:       account1.ID,
+		Currency:        account1.Currency,
+		CoolingOffUntil: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
+	require.Error(t, err)
+
+	payees, err := testQueries.ListPayees(context.Background(), ListPayeesParams{
+		Username: user.Username,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, payees, 2)
+	require.Equal(t, payee1.ID, payees[0].ID)
+	require.Equal(t, payee2.ID, payees[1].ID)
+
+	// only the owner can delete a payee
+	_, err = testQueries.DeletePayee(context.Background(), DeletePayeeParams{ID: payee1.ID, Username: account1.Owner})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.DeletePayee(context.Background(), DeletePayeeParams{ID: payee1.ID, Username: user.Username})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetPayee(context.Background(), payee1.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
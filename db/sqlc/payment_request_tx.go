@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	PaymentRequestStatusPending   = "pending"
+	PaymentRequestStatusPaid      = "paid"
+	PaymentRequestStatusDeclined  = "declined"
+	PaymentRequestStatusCancelled = "cancelled"
+)
+
+var (
+	ErrPaymentRequestNotPending = newError(ErrorKindConflict, "payment request has already been decided")
+	ErrPaymentRequestExpired    = newError(ErrorKindConflict, "payment request has expired")
+	ErrNotPaymentRequestPayer   = newError(ErrorKindForbidden, "only the payer can accept or decline a payment request")
+	// ErrPaymentRequestAwaitingApproval means an earlier acceptance of the
+	// request is parked for a second approver.
+	ErrPaymentRequestAwaitingApproval = newError(ErrorKindConflict, "payment request is already waiting on an approver")
+)
+
+type PayPaymentRequestTxParams struct {
+	PaymentRequestID int64  `json:"payment_request_id"`
+	Payer            string `json:"payer"`
+	// FromAccountID is the payer's account the money leaves from. It must
+	// hold the request's currency.
+	FromAccountID int64 `json:"from_account_id"`
+}
+
+type PayPaymentRequestTxResult struct {
+	PaymentRequest PaymentRequest   `json:"payment_request"`
+	Transfer       TransferTxResult `json:"transfer"`
+}
+
+// PayPaymentRequestTx transfers the requested amount from the payer's
+// account to the requester, charging its fee and recording its event like
+// TransferTx, and links the transfer to the request. The request row stays
+// locked until commit, so a request is paid at most once. An amount above
+// the payer's approval threshold fails with ErrApprovalRequired; such a
+// payment is parked with CreateTransferApprovalTx and paid by
+// ApproveTransferTx.
+func (store *SQLStore) PayPaymentRequestTx(ctx context.Context, arg PayPaymentRequestTxParams) (PayPaymentRequestTxResult, error) {
+	var result PayPaymentRequestTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		request, err := lockPayableRequest(ctx, q, arg.PaymentRequestID, arg.Payer)
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   request.ToAccountID,
+			Amount:        request.Amount,
+			Description:   request.Description,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.PaymentRequest, err = q.PayPaymentRequest(ctx, PayPaymentRequestParams{
+			ID:         request.ID,
+			TransferID: pgtype.Int8{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = checkAvailableBalance(ctx, q, result.Transfer.FromAccount)
+		if err != nil {
+			return err
+		}
+
+		return recordTransferEvent(ctx, q, result.Transfer.Transfer)
+	})
+
+	return result, err
+}
+
+// lockPayableRequest locks a payment request until commit and checks that
+// payer may still pay it.
+func lockPayableRequest(ctx context.Context, q *Queries, id int64, payer string) (PaymentRequest, error) {
+	request, err := q.GetPaymentRequestForUpdate(ctx, id)
+	if err != nil {
+		return request, err
+	}
+
+	if request.Payer != payer {
+		return request, ErrNotPaymentRequestPayer
+	}
+
+	if request.Status != PaymentRequestStatusPending {
+		return request, ErrPaymentRequestNotPending
+	}
+
+	if !time.Now().Before(request.ExpiresAt.Time) {
+		return request, ErrPaymentRequestExpired
+	}
+
+	return request, nil
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createPendingPaymentRequest(t *testing.T, payer string, to Account, amount int64, expiresAt time.Time) PaymentRequest {
+	request, err := testQueries.CreatePaymentRequest(context.Background(), CreatePaymentRequestParams{
+		Requester:   to.Owner,
+		Payer:       payer,
+		ToAccountID: to.ID,
+		Amount:      amount,
+		Currency:    to.Currency,
+		Description: "dinner",
+		ExpiresAt:   pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestStatusPending, request.Status)
+	require.False(t, request.TransferID.Valid)
+	return request
+}
+
+func TestPayPaymentRequestTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	request := createPendingPaymentRequest(t, account1.Owner, account2, 10, time.Now().Add(time.Hour))
+
+	incoming, err := store.ListIncomingPaymentRequests(context.Background(), ListIncomingPaymentRequestsParams{
+		Payer: account1.Owner,
+		Limit: 5,
+	})
+	require.NoError(t, err)
+	require.Len(t, incoming, 1)
+	require.Equal(t, request.ID, incoming[0].ID)
+
+	// the requester cannot pay their own request
+	_, err = store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            account2.Owner,
+		FromAccountID:    account2.ID,
+	})
+	require.ErrorIs(t, err, ErrNotPaymentRequestPayer)
+
+	result, err := store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            account1.Owner,
+		FromAccountID:    account1.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestStatusPaid, result.PaymentRequest.Status)
+	require.True(t, result.PaymentRequest.DecidedAt.Valid)
+	require.Equal(t, result.Transfer.Transfer.ID, result.PaymentRequest.TransferID.Int64)
+	require.Equal(t, "dinner", result.Transfer.Transfer.Description)
+	require.Equal(t, account1.Balance-10, result.Transfer.FromAccount.Balance)
+	require.Equal(t, account2.Balance+10, result.Transfer.ToAccount.Balance)
+
+	// a paid request cannot be paid, declined or cancelled
+	_, err = store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            account1.Owner,
+		FromAccountID:    account1.ID,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+
+	_, err = store.DeclinePaymentRequest(context.Background(), request.ID)
+	require.Error(t, err)
+
+	_, err = store.CancelPaymentRequest(context.Background(), request.ID)
+	require.Error(t, err)
+}
+
+func TestPayPaymentRequestTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	request := createPendingPaymentRequest(t, account1.Owner, account2, 10, time.Now().Add(-time.Minute))
+
+	_, err := store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            account1.Owner,
+		FromAccountID:    account1.ID,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestExpired)
+
+	// an expired request can still be declined
+	declined, err := store.DeclinePaymentRequest(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestStatusDeclined, declined.Status)
+}
+
+func TestPayPaymentRequestTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	request := createPendingPaymentRequest(t, account1.Owner, account2, 10, time.Now().Add(time.Hour))
+
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+				PaymentRequestID: request.ID,
+				Payer:            account1.Owner,
+				FromAccountID:    account1.ID,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+	}
+	require.Equal(t, 1, succeeded)
+
+	updated, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-10, updated.Balance)
+}
+
+func TestPayPaymentRequestTxAboveApprovalThreshold(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+	account1 := withApprovalThreshold(t, createRandomAccount(t), 10)
+	account2 := createRandomAccount(t)
+	request := createPendingPaymentRequest(t, account1.Owner, account2, 11, time.Now().Add(time.Hour))
+
+	_, err := store.PayPaymentRequestTx(ctx, PayPaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            account1.Owner,
+		FromAccountID:    account1.ID,
+	})
+	require.ErrorIs(t, err, ErrApprovalRequired)
+
+	arg := CreateTransferApprovalTxParams{
+		Approval: CreateTransferApprovalParams{
+			FromAccountID:    account1.ID,
+			ToAccountID:      account2.ID,
+			Amount:           request.Amount,
+			Initiator:        account1.Owner,
+			Reason:           ApprovalReasonThreshold,
+			PaymentRequestID: pgtype.Int8{Int64: request.ID, Valid: true},
+		},
+	}
+	approval, err := store.CreateTransferApprovalTx(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, request.ID, approval.PaymentRequestID.Int64)
+
+	// accepting again while the first acceptance waits is refused
+	_, err = store.CreateTransferApprovalTx(ctx, arg)
+	require.ErrorIs(t, err, ErrPaymentRequestAwaitingApproval)
+
+	approver := createRandomUser(t)
+	result, err := store.ApproveTransferTx(ctx, ApproveTransferTxParams{
+		ApprovalID: approval.ID,
+		Approver:   approver.Username,
+	})
+	require.NoError(t, err)
+
+	paid, err := store.GetPaymentRequest(ctx, request.ID)
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestStatusPaid, paid.Status)
+	require.Equal(t, result.Transfer.Transfer.ID, paid.TransferID.Int64)
+}
@@ -0,0 +1,27 @@
+package db
+
+const (
+	PermissionView     = "view"
+	PermissionTransact = "transact"
+	PermissionManage   = "manage"
+)
+
+var permissionRanks = map[string]int{
+	PermissionView:     1,
+	PermissionTransact: 2,
+	PermissionManage:   3,
+}
+
+// PermissionAllows reports whether a member granted one permission may
+// act at the required level. Each level includes the ones below it.
+func PermissionAllows(granted, required string) bool {
+	rank, ok := permissionRanks[granted]
+	return ok && rank >= permissionRanks[required]
+}
+
+// User roles. Depositors act only through account ownership and
+// memberships; bankers may also correct other users' transfers.
+const (
+	UserRoleDepositor = "depositor"
+	UserRoleBanker    = "banker"
+)
@@ -0,0 +1,16 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionAllows(t *testing.T) {
+	require.True(t, PermissionAllows(PermissionManage, PermissionView))
+	require.True(t, PermissionAllows(PermissionManage, PermissionTransact))
+	require.True(t, PermissionAllows(PermissionTransact, PermissionTransact))
+	require.False(t, PermissionAllows(PermissionView, PermissionTransact))
+	require.False(t, PermissionAllows(PermissionTransact, PermissionManage))
+	require.False(t, PermissionAllows("owner", PermissionView))
+}
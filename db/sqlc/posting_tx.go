@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrPostingTooFewLegs = newError(ErrorKindInvalid, "a posting needs at least two legs")
+	ErrPostingZeroLeg    = newError(ErrorKindInvalid, "posting legs must move a non-zero amount")
+	ErrPostingUnbalanced = newError(ErrorKindInvalid, "posting legs must net to zero in each currency")
+)
+
+// PostingLeg credits Amount to an account, or debits it when negative.
+type PostingLeg struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+	// Description is written to the leg's entry, defaulting to the
+	// posting's description.
+	Description string `json:"description"`
+}
+
+type PostingTxParams struct {
+	Description string       `json:"description"`
+	Legs        []PostingLeg `json:"legs"`
+}
+
+type PostingTxResult struct {
+	Journal Journal `json:"journal"`
+	// Entries are in the order of the legs.
+	Entries []Entry `json:"entries"`
+	// Accounts holds every account the posting touched, by ascending ID,
+	// with its balance after the posting.
+	Accounts []Account `json:"accounts"`
+}
+
+// PostingTx writes a balanced set of legs as one journal, for splits, fees
+// and sweeps that do not fit a single transfer. The legs must net to zero
+// in each currency. Accounts are locked in ID order, so concurrent
+// postings over the same accounts cannot deadlock, and every account left
+// with a net debit must still cover its active holds. A journal.posted
+// event is recorded with the journal.
+func (store *SQLStore) PostingTx(ctx context.Context, arg PostingTxParams) (PostingTxResult, error) {
+	if len(arg.Legs) < 2 {
+		return PostingTxResult{}, ErrPostingTooFewLegs
+	}
+
+	net := make(map[int64]int64, len(arg.Legs))
+	ids := make([]int64, 0, len(arg.Legs))
+	for _, leg := range arg.Legs {
+		if leg.Amount == 0 {
+			return PostingTxResult{}, ErrPostingZeroLeg
+		}
+		if _, ok := net[leg.AccountID]; !ok {
+			ids = append(ids, leg.AccountID)
+		}
+		net[leg.AccountID] += leg.Amount
+	}
+	slices.Sort(ids)
+
+	var result PostingTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		result = PostingTxResult{}
+
+		totals := make(map[string]int64)
+		for _, id := range ids {
+			account, err := q.GetAccountForUpdate(ctx, id)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return ErrAccountNotFound
+				}
+				return err
+			}
+			totals[account.Currency] += net[id]
+		}
+
+		for _, total := range totals {
+			if total != 0 {
+				return ErrPostingUnbalanced
+			}
+		}
+
+		var err error
+		result.Journal, err = q.CreateJournal(ctx, arg.Description)
+		if err != nil {
+			return err
+		}
+
+		result.Entries = make([]Entry, 0, len(arg.Legs))
+		for _, leg := range arg.Legs {
+			description := leg.Description
+			if description == "" {
+				description = arg.Description
+			}
+
+			entry, err := q.CreateJournalEntry(ctx, CreateJournalEntryParams{
+				Amount:      leg.Amount,
+				AccountID:   leg.AccountID,
+				Description: description,
+				JournalID:   pgtype.Int8{Int64: result.Journal.ID, Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+			result.Entries = append(result.Entries, entry)
+		}
+
+		result.Accounts = make([]Account, 0, len(ids))
+		for _, id := range ids {
+			account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+				ID:     id,
+				Amount: net[id],
+			})
+			if err != nil {
+				return err
+			}
+
+			if net[id] < 0 {
+				if err := checkAvailableBalance(ctx, q, account); err != nil {
+					return err
+				}
+			}
+			result.Accounts = append(result.Accounts, account)
+		}
+
+		return recordEvent(ctx, q, AggregateJournal, strconv.FormatInt(result.Journal.ID, 10), EventJournalPosted, JournalEvent{
+			Journal: result.Journal,
+			Entries: result.Entries,
+		})
+	})
+
+	return result, err
+}
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func createPostingTestAccount(t *testing.T, currency string, balance int64) Account {
+	user := createRandomUser(t)
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:         user.Username,
+		Balance:       balance,
+		Currency:      currency,
+		Type:          AccountTypeChecking,
+		AccountNumber: newTestAccountNumber(t),
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestPostingTxSplitPayment(t *testing.T) {
+	store := NewStore(testDB)
+	payer := createPostingTestAccount(t, utils.USD, 1_000)
+	merchant := createPostingTestAccount(t, utils.USD, 0)
+	platform := createPostingTestAccount(t, utils.USD, 0)
+
+	result, err := store.PostingTx(context.Background(), PostingTxParams{
+		Description: "order 1234",
+		Legs: []PostingLeg{
+			{AccountID: payer.ID, Amount: -500},
+			{AccountID: merchant.ID, Amount: 470},
+			{AccountID: platform.ID, Amount: 30, Description: "platform fee"},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NotZero(t, result.Journal.ID)
+	require.Equal(t, "order 1234", result.Journal.Description)
+
+	require.Len(t, result.Entries, 3)
+	require.Equal(t, int64(-500), result.Entries[0].Amount)
+	require.Equal(t, "order 1234", result.Entries[1].Description)
+	require.Equal(t, "platform fee", result.Entries[2].Description)
+	for _, entry := range result.Entries {
+		require.Equal(t, result.Journal.ID, entry.JournalID.Int64)
+	}
+
+	balances := map[int64]int64{payer.ID: 500, merchant.ID: 470, platform.ID: 30}
+	require.Len(t, result.Accounts, 3)
+	for i, account := range result.Accounts {
+		if i > 0 {
+			require.Greater(t, account.ID, result.Accounts[i-1].ID)
+		}
+		require.Equal(t, balances[account.ID], account.Balance)
+	}
+
+	entries, err := testQueries.ListJournalEntries(context.Background(), result.Entries[0].JournalID)
+	require.NoError(t, err)
+	require.Equal(t, result.Entries, entries)
+}
+
+func TestPostingTxPerCurrencyBalance(t *testing.T) {
+	store := NewStore(testDB)
+	usd1 := createPostingTestAccount(t, utils.USD, 1_000)
+	usd2 := createPostingTestAccount(t, utils.USD, 1_000)
+	eur1 := createPostingTestAccount(t, utils.EUR, 1_000)
+	eur2 := createPostingTestAccount(t, utils.EUR, 1_000)
+
+	// each currency nets to zero on its own
+	_, err := store.PostingTx(context.Background(), PostingTxParams{
+		Legs: []PostingLeg{
+			{AccountID: usd1.ID, Amount: -100},
+			{AccountID: usd2.ID, Amount: 100},
+			{AccountID: eur1.ID, Amount: -90},
+			{AccountID: eur2.ID, Amount: 90},
+		},
+	})
+	require.NoError(t, err)
+
+	// the legs net to zero overall but not per currency
+	_, err = store.PostingTx(context.Background(), PostingTxParams{
+		Legs: []PostingLeg{
+			{AccountID: usd1.ID, Amount: -100},
+			{AccountID: eur2.ID, Amount: 100},
+		},
+	})
+	require.ErrorIs(t, err, ErrPostingUnbalanced)
+
+	unchanged, err := testQueries.GetAccount(context.Background(), usd1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(900), unchanged.Balance)
+}
+
+func TestPostingTxInvalid(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createPostingTestAccount(t, utils.USD, 100)
+	account2 := createPostingTestAccount(t, utils.USD, 100)
+
+	testCases := []struct {
+		name string
+		legs []PostingLeg
+		err  error
+	}{
+		{
+			name: "OneLeg",
+			legs: []PostingLeg{{AccountID: account1.ID, Amount: 10}},
+			err:  ErrPostingTooFewLegs,
+		},
+		{
+			name: "ZeroLeg",
+			legs: []PostingLeg{{AccountID: account1.ID, Amount: 0}, {AccountID: account2.ID, Amount: 0}},
+			err:  ErrPostingZeroLeg,
+		},
+		{
+			name: "Unbalanced",
+			legs: []PostingLeg{{AccountID: account1.ID, Amount: -10}, {AccountID: account2.ID, Amount: 9}},
+			err:  ErrPostingUnbalanced,
+		},
+		{
+			name: "AccountNotFound",
+			legs: []PostingLeg{{AccountID: account1.ID, Amount: -10}, {AccountID: account2.ID + 1_000_000, Amount: 10}},
+			err:  ErrAccountNotFound,
+		},
+		{
+			name: "InsufficientFunds",
+			legs: []PostingLeg{{AccountID: account1.ID, Amount: -101}, {AccountID: account2.ID, Amount: 101}},
+			err:  ErrInsufficientFunds,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := store.PostingTx(context.Background(), PostingTxParams{Legs: tc.legs})
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	for _, account := range []Account{account1, account2} {
+		unchanged, err := testQueries.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, unchanged.Balance)
+	}
+}
+
+func TestPostingTxConcurrentSweeps(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createPostingTestAccount(t, utils.USD, 1_000)
+	account2 := createPostingTestAccount(t, utils.USD, 1_000)
+	account3 := createPostingTestAccount(t, utils.USD, 1_000)
+
+	// half the postings list the accounts in the opposite order; locking
+	// by ID keeps them from deadlocking
+	n := 10
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		legs := []PostingLeg{
+			{AccountID: account1.ID, Amount: -10},
+			{AccountID: account2.ID, Amount: 5},
+			{AccountID: account3.ID, Amount: 5},
+		}
+		if i%2 == 1 {
+			legs = []PostingLeg{
+				{AccountID: account3.ID, Amount: -10},
+				{AccountID: account2.ID, Amount: 5},
+				{AccountID: account1.ID, Amount: 5},
+			}
+		}
+
+		go func() {
+			_, err := store.PostingTx(context.Background(), PostingTxParams{Legs: legs})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	expected := map[int64]int64{account1.ID: 975, account2.ID: 1_050, account3.ID: 975}
+	for id, balance := range expected {
+		account, err := testQueries.GetAccount(context.Background(), id)
+		require.NoError(t, err)
+		require.Equal(t, balance, account.Balance)
+	}
+}
@@ -6,24 +6,133 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AdvanceStandingOrder(ctx context.Context, arg AdvanceStandingOrderParams) (StandingOrder, error)
+	ApproveTransferApproval(ctx context.Context, arg ApproveTransferApprovalParams) (TransferApproval, error)
+	CancelPaymentRequest(ctx context.Context, id int64) (PaymentRequest, error)
+	CancelScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	CaptureHold(ctx context.Context, arg CaptureHoldParams) (Hold, error)
+	ClaimDueScheduledTransfer(ctx context.Context) (ScheduledTransfer, error)
+	ClaimDueStandingOrder(ctx context.Context) (StandingOrder, error)
+	ClaimPendingTransfer(ctx context.Context, staleBefore pgtype.Timestamptz) (Transfer, error)
+	ClaimTimedOutEscrow(ctx context.Context) (Escrow, error)
+	CompleteScheduledTransfer(ctx context.Context, arg CompleteScheduledTransferParams) (ScheduledTransfer, error)
+	CompleteStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
+	CompleteTransfer(ctx context.Context, id int64) (Transfer, error)
+	CountAccountsByType(ctx context.Context, arg CountAccountsByTypeParams) (int64, error)
+	CountRoundTransfers(ctx context.Context, arg CountRoundTransfersParams) (int64, error)
+	CountTransfersBetween(ctx context.Context, arg CountTransfersBetweenParams) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountMember(ctx context.Context, arg CreateAccountMemberParams) (AccountMember, error)
+	CreateAlias(ctx context.Context, arg CreateAliasParams) (Alias, error)
+	CreateApprovalThresholdChange(ctx context.Context, arg CreateApprovalThresholdChangeParams) (ApprovalThresholdChange, error)
+	CreateCompensatingTransfer(ctx context.Context, arg CreateCompensatingTransferParams) (Transfer, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateEscrow(ctx context.Context, arg CreateEscrowParams) (Escrow, error)
+	CreateEscrowAccount(ctx context.Context, arg CreateEscrowAccountParams) (EscrowAccount, error)
+	CreateFeeSchedule(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error)
+	CreateFraudDecision(ctx context.Context, arg CreateFraudDecisionParams) (FraudDecision, error)
+	CreateFxRate(ctx context.Context, arg CreateFxRateParams) (FxRate, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateJournal(ctx context.Context, description string) (Journal, error)
+	CreateJournalEntry(ctx context.Context, arg CreateJournalEntryParams) (Entry, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error)
+	CreatePaymentRequest(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error)
+	CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (Transfer, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateStandingOrder(ctx context.Context, arg CreateStandingOrderParams) (StandingOrder, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferApproval(ctx context.Context, arg CreateTransferApprovalParams) (TransferApproval, error)
+	CreateTransferBatch(ctx context.Context, arg CreateTransferBatchParams) (TransferBatch, error)
+	CreateTransferBatchItem(ctx context.Context, arg CreateTransferBatchItemParams) (TransferBatchItem, error)
+	CreateTransferEntry(ctx context.Context, arg CreateTransferEntryParams) (Entry, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DecideApprovalThresholdChange(ctx context.Context, arg DecideApprovalThresholdChangeParams) (ApprovalThresholdChange, error)
+	DeclinePaymentRequest(ctx context.Context, id int64) (PaymentRequest, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteAccountMember(ctx context.Context, arg DeleteAccountMemberParams) (AccountMember, error)
+	DeleteAlias(ctx context.Context, arg DeleteAliasParams) (Alias, error)
+	DeletePayee(ctx context.Context, arg DeletePayeeParams) (Payee, error)
+	FailScheduledTransfer(ctx context.Context, arg FailScheduledTransferParams) (ScheduledTransfer, error)
+	FailTransfer(ctx context.Context, arg FailTransferParams) (Transfer, error)
+	FinishTransferBatch(ctx context.Context, arg FinishTransferBatchParams) (TransferBatch, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountByNumber(ctx context.Context, accountNumber string) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error)
+	GetActiveHoldsAmount(ctx context.Context, accountID int64) (int64, error)
+	GetAlias(ctx context.Context, alias string) (Alias, error)
+	GetApprovalThresholdChange(ctx context.Context, id int64) (ApprovalThresholdChange, error)
+	GetApprovalThresholdChangeForUpdate(ctx context.Context, id int64) (ApprovalThresholdChange, error)
+	GetCompensatedAmount(ctx context.Context, originalTransferID pgtype.Int8) (int64, error)
+	GetDefaultAccount(ctx context.Context, arg GetDefaultAccountParams) (Account, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetEscrow(ctx context.Context, id int64) (Escrow, error)
+	GetEscrowAccount(ctx context.Context, currency string) (EscrowAccount, error)
+	GetEscrowForUpdate(ctx context.Context, id int64) (Escrow, error)
+	GetFeeSchedule(ctx context.Context, arg GetFeeScheduleParams) (FeeSchedule, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetJournal(ctx context.Context, id int64) (Journal, error)
+	GetLatestFxRate(ctx context.Context, arg GetLatestFxRateParams) (FxRate, error)
+	GetOutboxPosition(ctx context.Context, name string) (int64, error)
+	GetPayee(ctx context.Context, id int64) (Payee, error)
+	GetPayeeByAccount(ctx context.Context, arg GetPayeeByAccountParams) (Payee, error)
+	GetPaymentRequest(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPaymentRequestForUpdate(ctx context.Context, id int64) (PaymentRequest, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferApproval(ctx context.Context, id int64) (TransferApproval, error)
+	GetTransferApprovalForUpdate(ctx context.Context, id int64) (TransferApproval, error)
+	GetTransferBatch(ctx context.Context, id int64) (TransferBatch, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferVelocity(ctx context.Context, arg GetTransferVelocityParams) (GetTransferVelocityRow, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAliases(ctx context.Context, username string) ([]Alias, error)
+	ListApprovalThresholdChanges(ctx context.Context, arg ListApprovalThresholdChangesParams) ([]ApprovalThresholdChange, error)
+	ListBalanceDrifts(ctx context.Context) ([]ListBalanceDriftsRow, error)
+	ListCurrencyImbalances(ctx context.Context) ([]ListCurrencyImbalancesRow, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEscrows(ctx context.Context, arg ListEscrowsParams) ([]Escrow, error)
+	ListFraudDecisions(ctx context.Context, arg ListFraudDecisionsParams) ([]FraudDecision, error)
+	ListIncomingPaymentRequests(ctx context.Context, arg ListIncomingPaymentRequestsParams) ([]PaymentRequest, error)
+	ListJournalEntries(ctx context.Context, journalID pgtype.Int8) ([]Entry, error)
+	ListOrphanedEntries(ctx context.Context) ([]Entry, error)
+	ListOutboxEvents(ctx context.Context, arg ListOutboxEventsParams) ([]Outbox, error)
+	ListOutgoingPaymentRequests(ctx context.Context, arg ListOutgoingPaymentRequestsParams) ([]PaymentRequest, error)
+	ListPayees(ctx context.Context, arg ListPayeesParams) ([]Payee, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListStandingOrderRuns(ctx context.Context, arg ListStandingOrderRunsParams) ([]ScheduledTransfer, error)
+	ListStandingOrders(ctx context.Context, arg ListStandingOrdersParams) ([]StandingOrder, error)
+	ListTransferApprovals(ctx context.Context, arg ListTransferApprovalsParams) ([]TransferApproval, error)
+	ListTransferBatchItems(ctx context.Context, batchID int64) ([]TransferBatchItem, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
-	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	ListTransfersByReference(ctx context.Context, arg ListTransfersByReferenceParams) ([]Transfer, error)
+	ListUnbalancedTransfers(ctx context.Context) ([]ListUnbalancedTransfersRow, error)
+	LockOutbox(ctx context.Context) error
+	MarkTransferReversed(ctx context.Context, id int64) (Transfer, error)
+	PauseStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
+	PayPaymentRequest(ctx context.Context, arg PayPaymentRequestParams) (PaymentRequest, error)
+	RejectTransferApproval(ctx context.Context, arg RejectTransferApprovalParams) (TransferApproval, error)
+	ReleaseHold(ctx context.Context, id int64) (Hold, error)
+	ResumeStandingOrder(ctx context.Context, arg ResumeStandingOrderParams) (StandingOrder, error)
+	SaveOutboxPosition(ctx context.Context, arg SaveOutboxPositionParams) error
+	SetApprovalThreshold(ctx context.Context, arg SetApprovalThresholdParams) (Account, error)
+	SetEscrowFundTransfer(ctx context.Context, arg SetEscrowFundTransferParams) (Escrow, error)
+	SettleEscrow(ctx context.Context, arg SettleEscrowParams) (Escrow, error)
+	VerifyAlias(ctx context.Context, alias string) (Alias, error)
 }
 
 var _ Querier = (*Queries)(nil)
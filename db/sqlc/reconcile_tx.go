@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ReconciliationReport lists every place the ledger does not add up. It is
+// OK when all of the lists are empty.
+type ReconciliationReport struct {
+	OK bool `json:"ok"`
+	// BalanceDrifts are accounts whose balance differs from the sum of
+	// their entries.
+	BalanceDrifts []ListBalanceDriftsRow `json:"balance_drifts"`
+	// OrphanedEntries belong to neither a transfer nor a journal.
+	OrphanedEntries []Entry `json:"orphaned_entries"`
+	// UnbalancedTransfers are posted transfers without exactly a matching
+	// debit and credit entry, and unposted ones with any entries at all.
+	UnbalancedTransfers []ListUnbalancedTransfersRow `json:"unbalanced_transfers"`
+	// CurrencyImbalances are currencies whose entries do not sum to zero.
+	// Cross-currency transfers are left out, since their legs are meant
+	// to differ.
+	CurrencyImbalances []ListCurrencyImbalancesRow `json:"currency_imbalances"`
+}
+
+// Problems counts the findings in the report.
+func (report ReconciliationReport) Problems() int {
+	return len(report.BalanceDrifts) + len(report.OrphanedEntries) +
+		len(report.UnbalancedTransfers) + len(report.CurrencyImbalances)
+}
+
+// ReconcileTx scans the whole ledger for inconsistencies. All checks read
+// one snapshot, so transfers committing meanwhile cannot show up as drift.
+func (store *SQLStore) ReconcileTx(ctx context.Context) (ReconciliationReport, error) {
+	var report ReconciliationReport
+	opts := pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+	err := store.execTxWithOptions(ctx, opts, func(q *Queries) error {
+		var err error
+		report.BalanceDrifts, err = q.ListBalanceDrifts(ctx)
+		if err != nil {
+			return err
+		}
+
+		report.OrphanedEntries, err = q.ListOrphanedEntries(ctx)
+		if err != nil {
+			return err
+		}
+
+		report.UnbalancedTransfers, err = q.ListUnbalancedTransfers(ctx)
+		if err != nil {
+			return err
+		}
+
+		report.CurrencyImbalances, err = q.ListCurrencyImbalances(ctx)
+		return err
+	})
+	report.OK = err == nil && report.Problems() == 0
+
+	return report, err
+}
+
+// WriteText writes the report for a human reader, one finding per line.
+func (report ReconciliationReport) WriteText(w io.Writer) error {
+	var b strings.Builder
+	if report.OK {
+		b.WriteString("ledger reconciled: no problems found\n")
+	} else {
+		fmt.Fprintf(&b, "ledger reconciliation found %d problem(s)\n", report.Problems())
+	}
+
+	if len(report.BalanceDrifts) > 0 {
+		b.WriteString("\naccounts whose balance differs from their entries:\n")
+		for _, drift := range report.BalanceDrifts {
+			fmt.Fprintf(&b, "  account %d (%s): balance %d, entries %d, drift %d\n",
+				drift.ID, drift.Currency, drift.Balance, drift.EntriesTotal, drift.Balance-drift.EntriesTotal)
+		}
+	}
+
+	if len(report.OrphanedEntries) > 0 {
+		b.WriteString("\nentries without a transfer or journal:\n")
+		for _, entry := range report.OrphanedEntries {
+			fmt.Fprintf(&b, "  entry %d: account %d, amount %d, %q\n",
+				entry.ID, entry.AccountID, entry.Amount, entry.Description)
+		}
+	}
+
+	if len(report.UnbalancedTransfers) > 0 {
+		b.WriteString("\ntransfers whose entries do not match:\n")
+		for _, transfer := range report.UnbalancedTransfers {
+			fmt.Fprintf(&b, "  transfer %d (%s): %d entries, %d matching debit(s) of %d from account %d, %d matching credit(s) of %d to account %d\n",
+				transfer.ID, transfer.Status, transfer.EntryCount,
+				transfer.DebitCount, transfer.Amount, transfer.FromAccountID,
+				transfer.CreditCount, transfer.ToAmount, transfer.ToAccountID)
+		}
+	}
+
+	if len(report.CurrencyImbalances) > 0 {
+		b.WriteString("\ncurrencies whose entries do not sum to zero:\n")
+		for _, imbalance := range report.CurrencyImbalances {
+			fmt.Fprintf(&b, "  %s: %d\n", imbalance.Currency, imbalance.Total)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
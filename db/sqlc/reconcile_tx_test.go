@@ -0,0 +1,96 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createFeeTestAccount(t, 0)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+
+	// other tests leave problems of their own behind, so only the ones
+	// made here are checked
+	// no query overwrites a balance, so the drift is made by hand
+	_, err := testDB.Exec(context.Background(), "UPDATE accounts SET balance = 50 WHERE id = $1", account1.ID)
+	require.NoError(t, err)
+
+	orphan, err := store.CreateEntry(context.Background(), CreateEntryParams{AccountID: account1.ID, Amount: 5})
+	require.NoError(t, err)
+
+	balanced, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account3.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	unbalanced, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account3.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	_, err = store.CreateTransferEntry(context.Background(), CreateTransferEntryParams{
+		AccountID:  account3.ID,
+		Amount:     1,
+		TransferID: pgtype.Int8{Int64: unbalanced.Transfer.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	report, err := store.ReconcileTx(context.Background())
+	require.NoError(t, err)
+	require.False(t, report.OK)
+	require.NotZero(t, report.Problems())
+
+	require.Contains(t, report.BalanceDrifts, ListBalanceDriftsRow{
+		ID:           account1.ID,
+		Currency:     feeTestCurrency,
+		Balance:      50,
+		EntriesTotal: 5,
+	})
+	require.Contains(t, report.OrphanedEntries, orphan)
+
+	var unbalancedIDs []int64
+	for _, transfer := range report.UnbalancedTransfers {
+		unbalancedIDs = append(unbalancedIDs, transfer.ID)
+		if transfer.ID == unbalanced.Transfer.ID {
+			require.Equal(t, int64(3), transfer.EntryCount)
+			require.Equal(t, int64(1), transfer.DebitCount)
+			require.Equal(t, int64(1), transfer.CreditCount)
+		}
+	}
+	require.Contains(t, unbalancedIDs, unbalanced.Transfer.ID)
+	require.NotContains(t, unbalancedIDs, balanced.Transfer.ID)
+
+	var text bytes.Buffer
+	require.NoError(t, report.WriteText(&text))
+	require.Contains(t, text.String(), "problem(s)")
+}
+
+func TestReconcileTxPendingTransfer(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	pending, err := store.CreatePendingTransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// a transfer that has not run yet is expected to have no entries
+	report, err := store.ReconcileTx(context.Background())
+	require.NoError(t, err)
+	for _, transfer := range report.UnbalancedTransfers {
+		require.NotEqual(t, pending.ID, transfer.ID)
+	}
+}
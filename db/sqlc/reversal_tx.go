@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	TransferKindTransfer = "transfer"
+	TransferKindReversal = "reversal"
+	TransferKindRefund   = "refund"
+)
+
+var (
+	ErrTransferNotReversible = newError(ErrorKindConflict, "only plain transfers can be reversed or refunded")
+	ErrTransferNotCompleted  = newError(ErrorKindConflict, "transfer has not completed")
+	ErrTransferFullyRefunded = newError(ErrorKindConflict, "transfer has already been fully reversed or refunded")
+	ErrRefundAmountExceeded  = newError(ErrorKindUnprocessable, "refund exceeds the amount left on the transfer")
+)
+
+type RefundTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount is in the recipient's currency and defaults to everything not
+	// yet refunded when zero.
+	Amount int64 `json:"amount"`
+}
+
+// ReverseTransferTx sends back whatever is left of a transfer after earlier
+// refunds, recording it as a reversal linked to the original.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, transferID int64) (TransferTxResult, error) {
+	var result TransferTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = compensate(ctx, q, transferID, 0, TransferKindReversal)
+		return err
+	})
+
+	return result, err
+}
+
+// RefundTransferTx sends part or all of a transfer back from the recipient
+// to the sender. Refunds and reversals of one transfer together never
+// exceed what the recipient was credited.
+func (store *SQLStore) RefundTransferTx(ctx context.Context, arg RefundTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = compensate(ctx, q, arg.TransferID, arg.Amount, TransferKindRefund)
+		return err
+	})
+
+	return result, err
+}
+
+// compensate records a transfer of the given kind from the original
+// recipient back to the sender. The original transfer row is locked so
+// that concurrent refunds see each other's totals.
+//
+// amount is debited in the recipient's currency. The sender is credited
+// the matching share of what it originally paid, computed on the running
+// total so that rounding never drifts and a full refund returns exactly
+// the original amount. Once nothing is left to send back the original is
+// marked reversed. Like transfer it records the compensation's event, and a
+// transfer.reversed event for the original once it is marked reversed.
+func compensate(ctx context.Context, q *Queries, transferID int64, amount int64, kind string) (TransferTxResult, error) {
+	original, err := q.GetTransferForUpdate(ctx, transferID)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	if original.Kind != TransferKindTransfer {
+		return TransferTxResult{}, ErrTransferNotReversible
+	}
+
+	// pending, processing and failed transfers have not moved any money
+	if original.Status != TransferStatusCompleted && original.Status != TransferStatusReversed {
+		return TransferTxResult{}, ErrTransferNotCompleted
+	}
+
+	originalID := pgtype.Int8{Int64: original.ID, Valid: true}
+	refunded, err := q.GetCompensatedAmount(ctx, originalID)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	remaining := original.ToAmount - refunded
+	if remaining <= 0 {
+		return TransferTxResult{}, ErrTransferFullyRefunded
+	}
+
+	if amount == 0 {
+		amount = remaining
+	}
+
+	if amount > remaining {
+		return TransferTxResult{}, ErrRefundAmountExceeded
+	}
+
+	credit := shareOf(refunded+amount, original.Amount, original.ToAmount) -
+		shareOf(refunded, original.Amount, original.ToAmount)
+
+	recorded, err := q.CreateCompensatingTransfer(ctx, CreateCompensatingTransferParams{
+		Amount:             amount,
+		FromAccountID:      original.ToAccountID,
+		ToAccountID:        original.FromAccountID,
+		ToAmount:           credit,
+		FxRate:             shareOf(FxRateScale, credit, amount),
+		Kind:               kind,
+		OriginalTransferID: originalID,
+		Description:        fmt.Sprintf("%s of transfer %d", kind, original.ID),
+		// keeps refunds findable under the original reference
+		ExternalReference: original.ExternalReference,
+	})
+	if err != nil {
+		return TransferTxResult{Transfer: recorded}, err
+	}
+
+	result, err := postTransfer(ctx, q, recorded)
+	if err != nil {
+		return result, err
+	}
+
+	// the original stays completed until nothing is left to send back
+	var reversed Transfer
+	if amount == remaining {
+		reversed, err = q.MarkTransferReversed(ctx, original.ID)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	err = checkAvailableBalance(ctx, q, result.FromAccount)
+	if err != nil {
+		return result, err
+	}
+
+	// the events go last, once every row lock is held
+	err = recordTransferEvent(ctx, q, result.Transfer)
+	if err != nil || reversed.ID == 0 {
+		return result, err
+	}
+
+	return result, recordTransferEvent(ctx, q, reversed)
+}
+
+// shareOf returns value * num / den rounded down, without overflowing on
+// large amounts.
+func shareOf(value, num, den int64) int64 {
+	share := new(big.Int).Mul(big.NewInt(value), big.NewInt(num))
+	return share.Quo(share, big.NewInt(den)).Int64()
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefundTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	refund, err := store.RefundTransferTx(context.Background(), RefundTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     4,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferKindRefund, refund.Transfer.Kind)
+	require.Equal(t, original.Transfer.ID, refund.Transfer.OriginalTransferID.Int64)
+	require.Equal(t, account2.ID, refund.Transfer.FromAccountID)
+	require.Equal(t, account1.ID, refund.Transfer.ToAccountID)
+	require.Equal(t, int64(-4), refund.FromEntry.Amount)
+	require.Equal(t, int64(4), refund.ToEntry.Amount)
+
+	_, err = store.RefundTransferTx(context.Background(), RefundTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     7,
+	})
+	require.ErrorIs(t, err, ErrRefundAmountExceeded)
+
+	// reversing sends back only what the refund left over
+	reversal, err := store.ReverseTransferTx(context.Background(), original.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferKindReversal, reversal.Transfer.Kind)
+	require.Equal(t, int64(6), reversal.Transfer.Amount)
+
+	_, err = store.ReverseTransferTx(context.Background(), original.Transfer.ID)
+	require.ErrorIs(t, err, ErrTransferFullyRefunded)
+
+	_, err = store.RefundTransferTx(context.Background(), RefundTransferTxParams{TransferID: refund.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferNotReversible)
+
+	updated1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	updated2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updated1.Balance)
+	require.Equal(t, account2.Balance, updated2.Balance)
+}
+
+func TestRefundTransferTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.RefundTransferTx(context.Background(), RefundTransferTxParams{
+				TransferID: original.Transfer.ID,
+				Amount:     3,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrRefundAmountExceeded)
+	}
+	require.Equal(t, 3, succeeded)
+}
+
+func TestRefundTransferTxExchange(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		Exchange:      &Exchange{Rate: 92_000_000, SpreadBps: 50, ToAmount: 91},
+	})
+	require.NoError(t, err)
+
+	// refunds are given in the recipient's currency and credit the sender
+	// its share of the original amount, so a full refund returns all 100
+	first, err := store.RefundTransferTx(context.Background(), RefundTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     45,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(49), first.Transfer.ToAmount)
+
+	second, err := store.RefundTransferTx(context.Background(), RefundTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(46), second.Transfer.Amount)
+	require.Equal(t, int64(51), second.Transfer.ToAmount)
+}
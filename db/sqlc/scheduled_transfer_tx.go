@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	ScheduledTransferStatusPending   = "pending"
+	ScheduledTransferStatusCompleted = "completed"
+	ScheduledTransferStatusFailed    = "failed"
+	ScheduledTransferStatusCancelled = "cancelled"
+)
+
+var ErrTransactPermissionRevoked = newError(ErrorKindForbidden, "user may no longer transact on the source account")
+
+type ExecuteScheduledTransferTxParams struct {
+	// MaxAttempts is how many times a transfer is tried before it is
+	// marked as failed.
+	MaxAttempts int32 `json:"max_attempts"`
+	// RetryDelay is multiplied by the attempt number to space out retries.
+	RetryDelay time.Duration `json:"retry_delay"`
+}
+
+// ExecuteScheduledTransferTx claims one due scheduled transfer and runs it.
+// The claimed row stays locked until the transfer commits and other workers
+// skip locked rows, so each scheduled transfer executes at most once even
+// when several replicas poll at the same time. A failed attempt is rolled
+// back, its reason recorded, and retried until MaxAttempts is reached;
+// retrying cannot help once the user lost the permission to transact or the
+// amount needs a second approver, so those fail at once.
+// It returns an error wrapping sql.ErrNoRows when nothing is due.
+func (store *SQLStore) ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ScheduledTransfer, error) {
+	var scheduled ScheduledTransfer
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		scheduled, err = q.ClaimDueScheduledTransfer(ctx)
+		if err != nil {
+			return err
+		}
+
+		var result TransferTxResult
+		err = withSavepoint(ctx, q, func() error {
+			err := checkTransactPermission(ctx, q, scheduled.FromAccountID, scheduled.Username)
+			if err != nil {
+				return err
+			}
+
+			result, err = transfer(ctx, q, TransferTxParams{
+				FromAccountID:     scheduled.FromAccountID,
+				ToAccountID:       scheduled.ToAccountID,
+				Amount:            scheduled.Amount,
+				Description:       scheduled.Description,
+				ExternalReference: scheduled.ExternalReference,
+				Metadata:          scheduled.Metadata,
+			})
+			if err != nil {
+				return err
+			}
+
+			return checkAvailableBalance(ctx, q, result.FromAccount)
+		})
+		if _, retryable := retryableTxError(err); retryable {
+			return err
+		}
+
+		if err == nil {
+			scheduled, err = q.CompleteScheduledTransfer(ctx, CompleteScheduledTransferParams{
+				ID:         scheduled.ID,
+				TransferID: pgtype.Int8{Int64: result.Transfer.ID, Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+
+			return recordTransferEvent(ctx, q, result.Transfer)
+		}
+
+		status := ScheduledTransferStatusPending
+		attempt := scheduled.Attempts + 1
+		if attempt >= arg.MaxAttempts || errors.Is(err, ErrTransactPermissionRevoked) || errors.Is(err, ErrApprovalRequired) {
+			status = ScheduledTransferStatusFailed
+		}
+
+		scheduled, err = q.FailScheduledTransfer(ctx, FailScheduledTransferParams{
+			ID:            scheduled.ID,
+			Status:        status,
+			FailureReason: err.Error(),
+			NextAttemptAt: pgtype.Timestamptz{Time: time.Now().Add(arg.RetryDelay * time.Duration(attempt)), Valid: true},
+		})
+		return err
+	})
+
+	return scheduled, err
+}
+
+// checkTransactPermission confirms that username still owns the account or
+// holds a membership that allows transacting on it.
+func checkTransactPermission(ctx context.Context, q *Queries, accountID int64, username string) error {
+	account, err := q.GetAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	if account.Owner == username {
+		return nil
+	}
+
+	member, err := q.GetAccountMember(ctx, GetAccountMemberParams{
+		AccountID: accountID,
+		Username:  username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTransactPermissionRevoked
+		}
+		return err
+	}
+
+	if !PermissionAllows(member.Permission, PermissionTransact) {
+		return ErrTransactPermissionRevoked
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+// createDueScheduledTransfer schedules a transfer that is already due,
+// bypassing the API's check that execute_at lies in the future.
+func createDueScheduledTransfer(t *testing.T, from, to Account, amount int64) ScheduledTransfer {
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), CreateScheduledTransferParams{
+		Username:      from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		ExecuteAt:     pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferStatusPending, scheduled.Status)
+	return scheduled
+}
+
+// executeUntil runs due transfers until the given one has been attempted;
+// other tests may leave due transfers behind.
+func executeUntil(t *testing.T, store Store, id int64, arg ExecuteScheduledTransferTxParams) ScheduledTransfer {
+	for {
+		scheduled, err := store.ExecuteScheduledTransferTx(context.Background(), arg)
+		require.NoError(t, err)
+		if scheduled.ID == id {
+			return scheduled
+		}
+	}
+}
+
+func TestExecuteScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	scheduled := createDueScheduledTransfer(t, account1, account2, 10)
+
+	arg := ExecuteScheduledTransferTxParams{MaxAttempts: 3, RetryDelay: time.Hour}
+	executed := executeUntil(t, store, scheduled.ID, arg)
+	require.Equal(t, ScheduledTransferStatusCompleted, executed.Status)
+	require.Equal(t, int32(1), executed.Attempts)
+	require.True(t, executed.TransferID.Valid)
+
+	transfer, err := store.GetTransfer(context.Background(), executed.TransferID.Int64)
+	require.NoError(t, err)
+	require.Equal(t, scheduled.Amount, transfer.Amount)
+
+	updated, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-scheduled.Amount, updated.Balance)
+}
+
+func TestExecuteScheduledTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	scheduled := createDueScheduledTransfer(t, account1, account2, account1.Balance+1)
+
+	// a zero delay keeps the retry due immediately
+	arg := ExecuteScheduledTransferTxParams{MaxAttempts: 2}
+
+	executed := executeUntil(t, store, scheduled.ID, arg)
+	require.Equal(t, ScheduledTransferStatusPending, executed.Status)
+	require.Equal(t, int32(1), executed.Attempts)
+	require.Equal(t, ErrInsufficientFunds.Error(), executed.FailureReason)
+	require.False(t, executed.TransferID.Valid)
+
+	executed = executeUntil(t, store, scheduled.ID, arg)
+	require.Equal(t, ScheduledTransferStatusFailed, executed.Status)
+	require.Equal(t, int32(2), executed.Attempts)
+
+	// the failed attempts left no trace on the balance
+	updated, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updated.Balance)
+}
+
+func TestExecuteScheduledTransferTxPermissionRevoked(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	member := createRandomAccountMember(t, account1, PermissionView)
+
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), CreateScheduledTransferParams{
+		Username:      member.Username,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		ExecuteAt:     pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+
+	executed := executeUntil(t, store, scheduled.ID, ExecuteScheduledTransferTxParams{MaxAttempts: 3})
+	require.Equal(t, ScheduledTransferStatusFailed, executed.Status)
+	require.Equal(t, ErrTransactPermissionRevoked.Error(), executed.FailureReason)
+}
+
+func TestExecuteScheduledTransferTxOnce(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	scheduled := createDueScheduledTransfer(t, account1, account2, 10)
+
+	arg := ExecuteScheduledTransferTxParams{MaxAttempts: 3, RetryDelay: time.Hour}
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			for {
+				_, err := store.ExecuteScheduledTransferTx(context.Background(), arg)
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.ErrorIs(t, <-errs, sql.ErrNoRows)
+	}
+
+	executed, err := store.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferStatusCompleted, executed.Status)
+	require.Equal(t, int32(1), executed.Attempts)
+
+	updated, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-scheduled.Amount, updated.Balance)
+}
+
+func TestCancelScheduledTransfer(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	scheduled := createDueScheduledTransfer(t, account1, account2, 10)
+
+	cancelled, err := testQueries.CancelScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferStatusCancelled, cancelled.Status)
+
+	_, err = testQueries.CancelScheduledTransfer(context.Background(), scheduled.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
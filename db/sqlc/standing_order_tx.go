@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+const (
+	StandingOrderStatusActive    = "active"
+	StandingOrderStatusPaused    = "paused"
+	StandingOrderStatusCompleted = "completed"
+)
+
+type RunStandingOrderTxResult struct {
+	StandingOrder StandingOrder `json:"standing_order"`
+	// Run is empty when the order had already ended and was only closed.
+	Run ScheduledTransfer `json:"run"`
+}
+
+// RunStandingOrderTx claims one due standing order, queues its next run as
+// a scheduled transfer linked to the order, and advances the order to its
+// following date. Occurrences missed while the scheduler was down are
+// skipped rather than run in a burst. Like ExecuteScheduledTransferTx it
+// skips rows claimed by other replicas, and it returns an error wrapping
+// sql.ErrNoRows when nothing is due.
+func (store *SQLStore) RunStandingOrderTx(ctx context.Context) (RunStandingOrderTxResult, error) {
+	var result RunStandingOrderTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		result = RunStandingOrderTxResult{}
+		order, err := q.ClaimDueStandingOrder(ctx)
+		if err != nil {
+			return err
+		}
+
+		if standingOrderEnded(order, order.Runs, order.NextRunAt.Time) {
+			result.StandingOrder, err = q.CompleteStandingOrder(ctx, order.ID)
+			return err
+		}
+
+		schedule, err := utils.ParseSchedule(order.Schedule)
+		if err != nil {
+			return err
+		}
+
+		result.Run, err = q.CreateScheduledTransfer(ctx, CreateScheduledTransferParams{
+			Username:          order.Username,
+			FromAccountID:     order.FromAccountID,
+			ToAccountID:       order.ToAccountID,
+			Amount:            order.Amount,
+			ExecuteAt:         order.NextRunAt,
+			StandingOrderID:   pgtype.Int8{Int64: order.ID, Valid: true},
+			Description:       order.Description,
+			ExternalReference: order.ExternalReference,
+			Metadata:          order.Metadata,
+		})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		next := schedule.Next(order.NextRunAt.Time)
+		for !next.After(now) {
+			next = schedule.Next(next)
+		}
+
+		status := StandingOrderStatusActive
+		if standingOrderEnded(order, order.Runs+1, next) {
+			status = StandingOrderStatusCompleted
+		}
+
+		result.StandingOrder, err = q.AdvanceStandingOrder(ctx, AdvanceStandingOrderParams{
+			ID:        order.ID,
+			NextRunAt: pgtype.Timestamptz{Time: next, Valid: true},
+			Status:    status,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// standingOrderEnded reports whether an order that has made runs runs
+// should stop instead of running at next.
+func standingOrderEnded(order StandingOrder, runs int32, next time.Time) bool {
+	if order.MaxRuns.Valid && runs >= order.MaxRuns.Int32 {
+		return true
+	}
+	return order.EndAt.Valid && next.After(order.EndAt.Time)
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+// createDueStandingOrder creates an order whose first run is already due.
+func createDueStandingOrder(t *testing.T, from, to Account, maxRuns int32) StandingOrder {
+	arg := CreateStandingOrderParams{
+		Username:      from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Schedule:      "weekly:mon",
+		NextRunAt:     pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true},
+	}
+	if maxRuns > 0 {
+		arg.MaxRuns = pgtype.Int4{Int32: maxRuns, Valid: true}
+	}
+
+	order, err := testQueries.CreateStandingOrder(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, StandingOrderStatusActive, order.Status)
+	return order
+}
+
+// runUntil runs due standing orders until the given one has been processed.
+func runUntil(t *testing.T, store Store, id int64) RunStandingOrderTxResult {
+	for {
+		result, err := store.RunStandingOrderTx(context.Background())
+		require.NoError(t, err)
+		if result.StandingOrder.ID == id {
+			return result
+		}
+	}
+}
+
+func TestRunStandingOrderTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	order := createDueStandingOrder(t, account1, account2, 0)
+
+	result := runUntil(t, store, order.ID)
+	require.Equal(t, StandingOrderStatusActive, result.StandingOrder.Status)
+	require.Equal(t, int32(1), result.StandingOrder.Runs)
+	require.True(t, result.StandingOrder.NextRunAt.Time.After(time.Now()))
+	require.Equal(t, time.Monday, result.StandingOrder.NextRunAt.Time.UTC().Weekday())
+
+	run := result.Run
+	require.Equal(t, order.Amount, run.Amount)
+	require.Equal(t, order.FromAccountID, run.FromAccountID)
+	require.Equal(t, ScheduledTransferStatusPending, run.Status)
+	require.Equal(t, pgtype.Int8{Int64: order.ID, Valid: true}, run.StandingOrderID)
+
+	runs, err := store.ListStandingOrderRuns(context.Background(), ListStandingOrderRunsParams{
+		StandingOrderID: run.StandingOrderID,
+		Limit:           5,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, run.ID, runs[0].ID)
+}
+
+func TestRunStandingOrderTxMaxRuns(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	order := createDueStandingOrder(t, account1, account2, 1)
+
+	result := runUntil(t, store, order.ID)
+	require.Equal(t, StandingOrderStatusCompleted, result.StandingOrder.Status)
+	require.Equal(t, int32(1), result.StandingOrder.Runs)
+	require.NotZero(t, result.Run.ID)
+}
+
+func TestPauseResumeStandingOrder(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	order := createDueStandingOrder(t, account1, account2, 0)
+
+	paused, err := testQueries.PauseStandingOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, StandingOrderStatusPaused, paused.Status)
+
+	_, err = testQueries.PauseStandingOrder(context.Background(), order.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	nextRunAt := pgtype.Timestamptz{Time: time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond), Valid: true}
+	resumed, err := testQueries.ResumeStandingOrder(context.Background(), ResumeStandingOrderParams{
+		ID:        order.ID,
+		NextRunAt: nextRunAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, StandingOrderStatusActive, resumed.Status)
+	require.WithinDuration(t, nextRunAt.Time, resumed.NextRunAt.Time, time.Second)
+}
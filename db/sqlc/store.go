@@ -2,32 +2,118 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"expvar"
 	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// FxRateScale is the fixed-point scale of exchange rates; a same-currency
+// transfer is recorded with a rate of exactly FxRateScale.
+const FxRateScale = 100_000_000
+
+var (
+	ErrInsufficientFunds       = newError(ErrorKindUnprocessable, "insufficient funds")
+	ErrDuplicateIdempotencyKey = newError(ErrorKindConflict, "idempotency key has already been used")
+)
+
 type Store interface {
 	Querier
+	CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error)
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	PlaceHoldTx(ctx context.Context, arg PlaceHoldTxParams) (Hold, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ScheduledTransfer, error)
+	RunStandingOrderTx(ctx context.Context) (RunStandingOrderTxResult, error)
+	ReverseTransferTx(ctx context.Context, transferID int64) (TransferTxResult, error)
+	RefundTransferTx(ctx context.Context, arg RefundTransferTxParams) (TransferTxResult, error)
+	BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error)
+	CreateTransferApprovalTx(ctx context.Context, arg CreateTransferApprovalTxParams) (TransferApproval, error)
+	ApproveTransferTx(ctx context.Context, arg ApproveTransferTxParams) (ApproveTransferTxResult, error)
+	ApproveThresholdChangeTx(ctx context.Context, arg ApproveThresholdChangeTxParams) (ApproveThresholdChangeTxResult, error)
+	RecordFraudDecisionTx(ctx context.Context, arg RecordFraudDecisionTxParams) (RecordFraudDecisionTxResult, error)
+	PostingTx(ctx context.Context, arg PostingTxParams) (PostingTxResult, error)
+	PayPaymentRequestTx(ctx context.Context, arg PayPaymentRequestTxParams) (PayPaymentRequestTxResult, error)
+	CreateEscrowTx(ctx context.Context, arg CreateEscrowTxParams) (EscrowTxResult, error)
+	SettleEscrowTx(ctx context.Context, arg SettleEscrowTxParams) (EscrowTxResult, error)
+	SettleTimedOutEscrowTx(ctx context.Context) (EscrowTxResult, error)
+	CreatePendingTransferTx(ctx context.Context, arg TransferTxParams) (Transfer, error)
+	ProcessTransferTx(ctx context.Context, transferID int64) (TransferTxResult, error)
+	ReconcileTx(ctx context.Context) (ReconciliationReport, error)
 }
 
 // Path: db/sqlc/store.go
 type SQLStore struct {
 	*Queries
-	db *pgxpool.Pool
+	db           *pgxpool.Pool
+	maxTxRetries int
 }
 
 func NewStore(db *pgxpool.Pool) Store {
 	return &SQLStore{
-		New(db),
-		db,
+		Queries:      New(db),
+		db:           db,
+		maxTxRetries: defaultMaxTxRetries,
 	}
 }
 
+const (
+	defaultMaxTxRetries = 3
+	txRetryBaseDelay    = 10 * time.Millisecond
+)
+
+// txRetries counts transactions re-run after a retryable failure, keyed by
+// SQLSTATE, and under "exhausted" those that still failed on the last
+// attempt. expvar publishes it as db_tx_retries.
+var txRetries = expvar.NewMap("db_tx_retries")
+
+// execTx runs fn in a transaction at the server's default isolation level.
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, pgx.TxOptions{})
+	return store.execTxWithOptions(ctx, pgx.TxOptions{}, fn)
+}
+
+// execTxWithOptions runs fn in a transaction with the given options. A
+// transaction that loses a serialization conflict or is picked as a
+// deadlock victim is rolled back and fn runs again from the start, after a
+// jittered exponential backoff, up to maxTxRetries times. fn must therefore
+// not keep state from an earlier attempt.
+func (store *SQLStore) execTxWithOptions(ctx context.Context, opts pgx.TxOptions, fn func(*Queries) error) error {
+	for attempt := 0; ; attempt++ {
+		err := store.runTx(ctx, opts, fn)
+		code, retryable := retryableTxError(err)
+		if !retryable {
+			return err
+		}
+
+		if attempt == store.maxTxRetries {
+			txRetries.Add("exhausted", 1)
+			return err
+		}
+		txRetries.Add(code, 1)
+
+		// full jitter keeps the losers of a conflict from colliding again
+		timer := time.NewTimer(rand.N(txRetryBaseDelay << attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (store *SQLStore) runTx(ctx context.Context, opts pgx.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -43,10 +129,69 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	return tx.Commit(ctx)
 }
 
+// retryableTxError reports whether err is a serialization failure (40001)
+// or a deadlock (40P01), which succeed when the transaction is retried.
+func retryableTxError(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return "", false
+	}
+
+	switch pgErr.Code {
+	case "40001", "40P01":
+		return pgErr.Code, true
+	}
+	return "", false
+}
+
+// withSavepoint runs fn inside a savepoint of the current transaction so
+// that a failure undoes fn's writes without aborting the transaction.
+func withSavepoint(ctx context.Context, q *Queries, fn func() error) error {
+	if _, err := q.db.Exec(ctx, "SAVEPOINT store_savepoint"); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		if _, rbErr := q.db.Exec(ctx, "ROLLBACK TO SAVEPOINT store_savepoint"); rbErr != nil {
+			return fmt.Errorf("savepoint error: %v, rb error: %v", err, rbErr)
+		}
+		return err
+	}
+
+	_, err := q.db.Exec(ctx, "RELEASE SAVEPOINT store_savepoint")
+	return err
+}
+
 type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// Description is copied to both entries so statements show it.
+	Description       string          `json:"description"`
+	ExternalReference string          `json:"external_reference"`
+	Metadata          json.RawMessage `json:"metadata"`
+	// Exchange converts the credited amount when the accounts use
+	// different currencies. Nil means both sides move Amount.
+	Exchange *Exchange `json:"exchange"`
+	// Idempotency, when set, is stored with the result in the same
+	// transaction so a retried request can be replayed.
+	Idempotency *IdempotencyKeyParams `json:"-"`
+	// Approved is set once a second approver has released the transfer,
+	// which lifts the sender's approval threshold.
+	Approved bool `json:"-"`
+}
+
+// Exchange is the conversion applied to a cross-currency transfer.
+type Exchange struct {
+	Rate      int64 `json:"rate"`
+	SpreadBps int64 `json:"spread_bps"`
+	ToAmount  int64 `json:"to_amount"`
+}
+
+type IdempotencyKeyParams struct {
+	Username    string `json:"username"`
+	Key         string `json:"key"`
+	RequestHash string `json:"request_hash"`
 }
 
 type TransferTxResult struct {
@@ -55,58 +200,212 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account_id"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// Fee is charged to the sender on top of the amount, except on an
+	// escrow release, where the seller pays it out of the payout. The fee
+	// entries are empty when the transfer is free.
+	Fee             int64 `json:"fee"`
+	FeeEntry        Entry `json:"fee_entry"`
+	FeeAccountEntry Entry `json:"fee_account_entry"`
 }
 
+// TransferTx moves money between two accounts and charges the sender the
+// fee from the fee schedule.
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
-
+		result, err = transfer(ctx, q, arg)
 		if err != nil {
 			return err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			Amount:    -arg.Amount,
-			AccountID: arg.FromAccountID,
-		})
-
-		if err != nil {
-			return nil
-		}
-
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			Amount:    arg.Amount,
-			AccountID: arg.ToAccountID,
-		})
+		err = checkAvailableBalance(ctx, q, result.FromAccount)
 		if err != nil {
 			return err
 		}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+		if arg.Idempotency != nil {
+			err = saveIdempotencyKey(ctx, q, *arg.Idempotency, result.Transfer.ID, http.StatusOK, result)
+			if err != nil {
+				return err
+			}
 		}
 
-		if err != nil {
-			return err
+		return recordTransferEvent(ctx, q, result.Transfer)
+	})
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// saveIdempotencyKey records the response of a transfer and its HTTP status
+// under its key. A concurrent request that already claimed the key makes
+// this fail with ErrDuplicateIdempotencyKey, which rolls the second
+// transfer back.
+func saveIdempotencyKey(ctx context.Context, q *Queries, arg IdempotencyKeyParams, transferID int64, statusCode int32, result any) error {
+	response, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	return storeIdempotencyKey(ctx, q, CreateIdempotencyKeyParams{
+		Username:    arg.Username,
+		Key:         arg.Key,
+		RequestHash: arg.RequestHash,
+		Response:    response,
+		TransferID:  pgtype.Int8{Int64: transferID, Valid: true},
+		StatusCode:  statusCode,
+	})
+}
+
+// saveApprovalIdempotencyKey records a transfer parked for approval as a
+// 202 response under its key, so that a retry replays the approval rather
+// than parking the transfer again.
+func saveApprovalIdempotencyKey(ctx context.Context, q *Queries, arg IdempotencyKeyParams, approval TransferApproval) error {
+	response, err := json.Marshal(approval)
+	if err != nil {
+		return err
+	}
+
+	return storeIdempotencyKey(ctx, q, CreateIdempotencyKeyParams{
+		Username:    arg.Username,
+		Key:         arg.Key,
+		RequestHash: arg.RequestHash,
+		Response:    response,
+		StatusCode:  http.StatusAccepted,
+		ApprovalID:  pgtype.Int8{Int64: approval.ID, Valid: true},
+	})
+}
+
+func storeIdempotencyKey(ctx context.Context, q *Queries, arg CreateIdempotencyKeyParams) error {
+	_, err := q.CreateIdempotencyKey(ctx, arg)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrDuplicateIdempotencyKey
 		}
+		return err
+	}
 
-		return nil
+	return nil
+}
+
+// transfer records the transfer and its entries, moves the money and
+// charges the sender its fee. It runs inside a caller's transaction and
+// does not check funds or record the transfer's event: the caller records
+// that as its last statement, after the fee and the funds check, so the
+// outbox lock is taken after the fee account's row as in ProcessTransferTx.
+// Every path on which a customer moves money goes through it, so it is
+// where the approval threshold is enforced and the fee charged: unless arg
+// is Approved, an amount above the sender's threshold fails with
+// ErrApprovalRequired.
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	result, err := recordTransfer(ctx, q, arg)
+	if err != nil {
+		return result, err
+	}
+
+	err = chargeFee(ctx, q, &result)
+	return result, err
+}
+
+// recordTransfer is transfer without the fee, debiting Amount and crediting
+// the converted amount. Only the fee exemptions listed on chargeFee use it
+// directly.
+func recordTransfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	exchange := Exchange{Rate: FxRateScale, ToAmount: arg.Amount}
+	if arg.Exchange != nil {
+		exchange = *arg.Exchange
+	}
+
+	recorded, err := q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID:     arg.FromAccountID,
+		ToAccountID:       arg.ToAccountID,
+		Amount:            arg.Amount,
+		ToAmount:          exchange.ToAmount,
+		FxRate:            exchange.Rate,
+		SpreadBps:         exchange.SpreadBps,
+		Description:       arg.Description,
+		ExternalReference: arg.ExternalReference,
+		Metadata:          arg.Metadata,
 	})
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return TransferTxResult{}, ErrAccountNotFound
+		}
+		return TransferTxResult{}, err
+	}
+
+	result, err := postTransfer(ctx, q, recorded)
 	if err != nil {
 		return result, err
 	}
 
+	if !arg.Approved && result.FromAccount.RequiresApproval(arg.Amount) {
+		return result, ErrApprovalRequired
+	}
+
 	return result, nil
 }
 
+// postTransfer writes the entries of a recorded transfer and moves the
+// money, debiting its Amount and crediting its ToAmount.
+func postTransfer(ctx context.Context, q *Queries, recorded Transfer) (TransferTxResult, error) {
+	result := TransferTxResult{Transfer: recorded}
+	transferID := pgtype.Int8{Int64: recorded.ID, Valid: true}
+	var err error
+	result.FromEntry, err = q.CreateTransferEntry(ctx, CreateTransferEntryParams{
+		Amount:      -recorded.Amount,
+		AccountID:   recorded.FromAccountID,
+		Description: recorded.Description,
+		TransferID:  transferID,
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateTransferEntry(ctx, CreateTransferEntryParams{
+		Amount:      recorded.ToAmount,
+		AccountID:   recorded.ToAccountID,
+		Description: recorded.Description,
+		TransferID:  transferID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if recorded.FromAccountID < recorded.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, recorded.FromAccountID, -recorded.Amount, recorded.ToAccountID, recorded.ToAmount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, recorded.ToAccountID, recorded.ToAmount, recorded.FromAccountID, -recorded.Amount)
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return result, ErrAccountNotFound
+	}
+	return result, err
+}
+
+// checkAvailableBalance fails when the account's balance, after the
+// money has moved, no longer covers its active holds.
+func checkAvailableBalance(ctx context.Context, q *Queries, account Account) error {
+	held, err := q.GetActiveHoldsAmount(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+
+	if account.Balance-held < 0 {
+		return ErrInsufficientFunds
+	}
+
+	return nil
+}
+
 func addMoney(
 	ctx context.Context,
 	q *Queries,
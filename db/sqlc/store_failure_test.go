@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+// failingDBTX passes queries through to DBTX but fails the failAt-th one
+// with err.
+type failingDBTX struct {
+	DBTX
+	failAt int
+	calls  int
+	err    error
+}
+
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...any) error {
+	return r.err
+}
+
+func (f *failingDBTX) fail() bool {
+	f.calls++
+	return f.calls == f.failAt
+}
+
+func (f *failingDBTX) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	if f.fail() {
+		return pgconn.CommandTag{}, f.err
+	}
+	return f.DBTX.Exec(ctx, sql, args...)
+}
+
+func (f *failingDBTX) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	if f.fail() {
+		return nil, f.err
+	}
+	return f.DBTX.Query(ctx, sql, args...)
+}
+
+func (f *failingDBTX) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	if f.fail() {
+		return errRow{err: f.err}
+	}
+	return f.DBTX.QueryRow(ctx, sql, args...)
+}
+
+func TestTransferFailureRollsBack(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	injected := errors.New("injected failure")
+	feeAccount := createFeeSchedules(t)
+
+	// transferTx is what TransferTx runs: transfer creates the transfer,
+	// both entries and updates both balances, then charges the fee as a
+	// journal of its own; the funds check and the outbox event come last.
+	// A failure at any of those steps must leave nothing behind.
+	transferTx := func(q *Queries, arg TransferTxParams) error {
+		result, err := transfer(context.Background(), q, arg)
+		if err != nil {
+			return err
+		}
+
+		err = checkAvailableBalance(context.Background(), q, result.FromAccount)
+		if err != nil {
+			return err
+		}
+
+		return recordTransferEvent(context.Background(), q, result.Transfer)
+	}
+
+	steps := []string{
+		"CreateTransfer",
+		"CreateEntry from",
+		"CreateEntry to",
+		"AddAccountBalance first",
+		"AddAccountBalance second",
+		"GetFeeSchedule",
+		"CreateJournal fee",
+		"CreateJournalEntry payer",
+		"CreateJournalEntry fee account",
+		"AddAccountBalance payer",
+		"AddAccountBalance fee account",
+		"GetActiveHoldsAmount",
+		"LockOutbox",
+		"CreateOutboxEvent",
+	}
+
+	t.Run("AllSteps", func(t *testing.T) {
+		account1 := createFeeTestAccount(t, 1_000)
+		account2 := createFeeTestAccount(t, 0)
+
+		var counting *failingDBTX
+		err := store.execTx(context.Background(), func(q *Queries) error {
+			counting = &failingDBTX{DBTX: q.db}
+			err := transferTx(New(counting), TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        10,
+			})
+			if err != nil {
+				return err
+			}
+			return injected
+		})
+		require.ErrorIs(t, err, injected)
+
+		// a new step must be added to steps to have its failure tested
+		require.Equal(t, len(steps), counting.calls)
+	})
+
+	for i, step := range steps {
+		t.Run(step, func(t *testing.T) {
+			account1 := createFeeTestAccount(t, 1_000)
+			account2 := createFeeTestAccount(t, 0)
+			feeBefore, err := testQueries.GetAccount(context.Background(), feeAccount.ID)
+			require.NoError(t, err)
+			outboxBefore := lastOutboxID(t)
+
+			err = store.execTx(context.Background(), func(q *Queries) error {
+				failing := New(&failingDBTX{DBTX: q.db, failAt: i + 1, err: injected})
+				return transferTx(failing, TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        10,
+				})
+			})
+			require.ErrorIs(t, err, injected)
+
+			for _, account := range []Account{account1, account2} {
+				unchanged, err := testQueries.GetAccount(context.Background(), account.ID)
+				require.NoError(t, err)
+				require.Equal(t, account.Balance, unchanged.Balance)
+
+				entries, err := testQueries.ListEntries(context.Background(), ListEntriesParams{AccountID: account.ID, Limit: 5})
+				require.NoError(t, err)
+				require.Empty(t, entries)
+			}
+
+			feeAfter, err := testQueries.GetAccount(context.Background(), feeAccount.ID)
+			require.NoError(t, err)
+			require.Equal(t, feeBefore.Balance, feeAfter.Balance)
+
+			transfers, err := testQueries.ListTransfers(context.Background(), ListTransfersParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account1.ID,
+				Limit:         5,
+			})
+			require.NoError(t, err)
+			require.Empty(t, transfers)
+
+			require.Equal(t, outboxBefore, lastOutboxID(t))
+		})
+	}
+}
+
+func TestTransferTxAccountNotFound(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   account.ID + 1_000_000,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountNotFound)
+	require.Equal(t, ErrorKindNotFound, ErrorKindOf(err))
+}
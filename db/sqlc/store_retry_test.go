@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func txRetryCount(key string) int64 {
+	v, ok := txRetries.Get(key).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return v.Value()
+}
+
+func TestExecTxRetriesDeadlock(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	before := txRetryCount("40P01")
+
+	// both transactions hold their first lock before taking the second, in
+	// opposite orders, so Postgres has to abort one of them
+	var locked sync.WaitGroup
+	locked.Add(2)
+	addBoth := func(first, second int64) error {
+		var once sync.Once
+		return store.execTx(context.Background(), func(q *Queries) error {
+			_, err := q.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: first, Amount: 1})
+			if err != nil {
+				return err
+			}
+			once.Do(func() {
+				locked.Done()
+				locked.Wait()
+			})
+			_, err = q.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: second, Amount: 1})
+			return err
+		})
+	}
+
+	errs := make(chan error)
+	go func() { errs <- addBoth(account1.ID, account2.ID) }()
+	go func() { errs <- addBoth(account2.ID, account1.ID) }()
+	require.NoError(t, <-errs)
+	require.NoError(t, <-errs)
+
+	require.Greater(t, txRetryCount("40P01"), before)
+
+	updated1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance+2, updated1.Balance)
+
+	updated2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance+2, updated2.Balance)
+}
+
+func TestExecTxRetriesSerializationFailure(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	account := createRandomAccount(t)
+	before := txRetryCount("40001")
+
+	// both transactions read the balance before either writes it, so the
+	// second writer cannot be serialized after the first
+	var read sync.WaitGroup
+	read.Add(2)
+	increment := func() error {
+		var once sync.Once
+		return store.execTxWithOptions(context.Background(), pgx.TxOptions{IsoLevel: pgx.Serializable}, func(q *Queries) error {
+			_, err := q.GetAccount(context.Background(), account.ID)
+			if err != nil {
+				return err
+			}
+			once.Do(func() {
+				read.Done()
+				read.Wait()
+			})
+			_, err = q.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: account.ID, Amount: 1})
+			return err
+		})
+	}
+
+	errs := make(chan error)
+	for i := 0; i < 2; i++ {
+		go func() { errs <- increment() }()
+	}
+	require.NoError(t, <-errs)
+	require.NoError(t, <-errs)
+
+	require.Greater(t, txRetryCount("40001"), before)
+
+	updated, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+2, updated.Balance)
+}
+
+func TestExecTxGivesUpAfterMaxRetries(t *testing.T) {
+	store := &SQLStore{Queries: New(testDB), db: testDB, maxTxRetries: 2}
+	before := txRetryCount("exhausted")
+
+	calls := 0
+	conflict := &pgconn.PgError{Code: "40001"}
+	err := store.execTx(context.Background(), func(q *Queries) error {
+		calls++
+		return conflict
+	})
+	require.ErrorIs(t, err, conflict)
+	require.Equal(t, 3, calls)
+	require.Equal(t, before+1, txRetryCount("exhausted"))
+}
+
+func TestExecTxDoesNotRetryOtherErrors(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+
+	calls := 0
+	failure := errors.New("boom")
+	err := store.execTx(context.Background(), func(q *Queries) error {
+		calls++
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+	require.Equal(t, 1, calls)
+
+	calls = 0
+	err = store.execTx(context.Background(), func(q *Queries) error {
+		calls++
+		return &pgconn.PgError{Code: "23505"}
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, calls)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -140,3 +141,50 @@ func TestTransferTxDeadLock(t *testing.T) {
 	require.Equal(t, updatedAccount1.Balance, account1.Balance)
 	require.Equal(t, updatedAccount2.Balance, account2.Balance)
 }
+
+func TestTransferTxExchange(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	exchange := &Exchange{Rate: 92_000_000, SpreadBps: 50, ToAmount: 91}
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		Exchange:      exchange,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(100), result.Transfer.Amount)
+	require.Equal(t, exchange.ToAmount, result.Transfer.ToAmount)
+	require.Equal(t, exchange.Rate, result.Transfer.FxRate)
+	require.Equal(t, exchange.SpreadBps, result.Transfer.SpreadBps)
+
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, exchange.ToAmount, result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-100, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+exchange.ToAmount, result.ToAccount.Balance)
+}
+
+func TestTransferTxDetails(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:     account1.ID,
+		ToAccountID:       account2.ID,
+		Amount:            10,
+		Description:       "march rent",
+		ExternalReference: "INV-0317",
+		Metadata:          json.RawMessage(`{"flat": "4B"}`),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "march rent", result.Transfer.Description)
+	require.Equal(t, "INV-0317", result.Transfer.ExternalReference)
+	require.JSONEq(t, `{"flat": "4B"}`, string(result.Transfer.Metadata))
+	require.Equal(t, "march rent", result.FromEntry.Description)
+	require.Equal(t, "march rent", result.ToEntry.Description)
+}
@@ -0,0 +1,212 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	TransferApprovalStatusPending  = "pending"
+	TransferApprovalStatusApproved = "approved"
+	TransferApprovalStatusRejected = "rejected"
+)
+
+const (
+	// ApprovalReasonThreshold approvals wait on a second approver because
+	// the amount is above the account's approval threshold.
+	ApprovalReasonThreshold = "threshold"
+	// ApprovalReasonFraudReview approvals wait on a banker because a fraud
+	// rule held the transfer.
+	ApprovalReasonFraudReview = "fraud_review"
+)
+
+var (
+	ErrApprovalNotPending = newError(ErrorKindConflict, "transfer approval has already been decided")
+	ErrSelfApproval       = newError(ErrorKindForbidden, "a transfer cannot be approved by its initiator")
+	ErrApprovalRequired   = newError(ErrorKindForbidden, "amount is above the account's approval threshold and needs a second approver")
+)
+
+// RequiresApproval reports whether a transfer of amount out of the account
+// has to be approved by a second member first. A threshold of zero turns
+// approvals off.
+func (account Account) RequiresApproval(amount int64) bool {
+	return account.ApprovalThreshold > 0 && amount > account.ApprovalThreshold
+}
+
+type CreateTransferApprovalTxParams struct {
+	Approval CreateTransferApprovalParams `json:"approval"`
+	// Idempotency, when set, is stored with the approval in the same
+	// transaction so a retried request replays it.
+	Idempotency *IdempotencyKeyParams `json:"-"`
+}
+
+// CreateTransferApprovalTx parks a transfer until a second approver
+// decides on it.
+func (store *SQLStore) CreateTransferApprovalTx(ctx context.Context, arg CreateTransferApprovalTxParams) (TransferApproval, error) {
+	var approval TransferApproval
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		approval, err = parkTransfer(ctx, q, arg.Approval)
+		if err != nil {
+			return err
+		}
+
+		if arg.Idempotency == nil {
+			return nil
+		}
+
+		return saveApprovalIdempotencyKey(ctx, q, *arg.Idempotency, approval)
+	})
+
+	return approval, err
+}
+
+// parkTransfer records a transfer approval. A payment request can wait on
+// one pending approval at a time.
+func parkTransfer(ctx context.Context, q *Queries, arg CreateTransferApprovalParams) (TransferApproval, error) {
+	approval, err := q.CreateTransferApproval(ctx, arg)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return approval, ErrPaymentRequestAwaitingApproval
+		}
+		return approval, err
+	}
+
+	return approval, nil
+}
+
+type ApproveTransferTxParams struct {
+	ApprovalID int64  `json:"approval_id"`
+	Approver   string `json:"approver"`
+	// Exchange is priced when the transfer is approved, not when it was
+	// requested. Nil means both accounts use the same currency.
+	Exchange *Exchange `json:"exchange"`
+}
+
+type ApproveTransferTxResult struct {
+	Approval TransferApproval `json:"approval"`
+	Transfer TransferTxResult `json:"transfer"`
+	// Pending is set instead of Transfer when an approved fraud review is
+	// above the account's approval threshold and still waits on a second
+	// approver.
+	Pending *TransferApproval `json:"pending_approval,omitempty"`
+}
+
+// ApproveTransferTx executes a pending transfer, charging its fee like
+// TransferTx, and records who approved it. The approval row stays locked
+// until commit, so two approvers cannot both execute the same transfer.
+// A transfer that pays a payment request marks the request paid.
+// Clearing a fraud review does not lift the approval threshold: a transfer
+// above it is parked again for a second approver, as createTransfer would
+// have done had no fraud rule held it.
+func (store *SQLStore) ApproveTransferTx(ctx context.Context, arg ApproveTransferTxParams) (ApproveTransferTxResult, error) {
+	var result ApproveTransferTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		approval, err := q.GetTransferApprovalForUpdate(ctx, arg.ApprovalID)
+		if err != nil {
+			return err
+		}
+
+		if approval.Status != TransferApprovalStatusPending {
+			return ErrApprovalNotPending
+		}
+
+		if approval.Initiator == arg.Approver {
+			return ErrSelfApproval
+		}
+
+		if approval.Reason == ApprovalReasonFraudReview {
+			fromAccount, err := q.GetAccount(ctx, approval.FromAccountID)
+			if err != nil {
+				return err
+			}
+
+			if fromAccount.RequiresApproval(approval.Amount) {
+				return passFraudReview(ctx, q, approval, arg.Approver, &result)
+			}
+		}
+
+		result.Transfer, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID:     approval.FromAccountID,
+			ToAccountID:       approval.ToAccountID,
+			Amount:            approval.Amount,
+			Description:       approval.Description,
+			ExternalReference: approval.ExternalReference,
+			Metadata:          approval.Metadata,
+			Exchange:          arg.Exchange,
+			Approved:          approval.Reason == ApprovalReasonThreshold,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Approval, err = q.ApproveTransferApproval(ctx, ApproveTransferApprovalParams{
+			ID:         approval.ID,
+			DecidedBy:  arg.Approver,
+			TransferID: pgtype.Int8{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		if approval.PaymentRequestID.Valid {
+			request, err := lockPayableRequest(ctx, q, approval.PaymentRequestID.Int64, approval.Initiator)
+			if err != nil {
+				return err
+			}
+
+			_, err = q.PayPaymentRequest(ctx, PayPaymentRequestParams{
+				ID:         request.ID,
+				TransferID: result.Approval.TransferID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = checkAvailableBalance(ctx, q, result.Transfer.FromAccount)
+		if err != nil {
+			return err
+		}
+
+		return recordTransferEvent(ctx, q, result.Transfer.Transfer)
+	})
+
+	return result, err
+}
+
+// passFraudReview marks a fraud review approved without executing its
+// transfer and parks the transfer for the approval threshold instead. The
+// initiator stays the same, so they cannot approve it themselves.
+func passFraudReview(ctx context.Context, q *Queries, review TransferApproval, approver string, result *ApproveTransferTxResult) error {
+	var err error
+	result.Approval, err = q.ApproveTransferApproval(ctx, ApproveTransferApprovalParams{
+		ID:        review.ID,
+		DecidedBy: approver,
+	})
+	if err != nil {
+		return err
+	}
+
+	pending, err := parkTransfer(ctx, q, CreateTransferApprovalParams{
+		FromAccountID:     review.FromAccountID,
+		ToAccountID:       review.ToAccountID,
+		Amount:            review.Amount,
+		Description:       review.Description,
+		ExternalReference: review.ExternalReference,
+		Metadata:          review.Metadata,
+		Initiator:         review.Initiator,
+		Reason:            ApprovalReasonThreshold,
+		PaymentRequestID:  review.PaymentRequestID,
+	})
+	if err != nil {
+		return err
+	}
+
+	result.Pending = &pending
+	return nil
+}
@@ -0,0 +1,278 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func createPendingApproval(t *testing.T, from, to Account, amount int64) TransferApproval {
+	approval, err := testQueries.CreateTransferApproval(context.Background(), CreateTransferApprovalParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Description:   "equipment",
+		Initiator:     from.Owner,
+		Reason:        ApprovalReasonThreshold,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferApprovalStatusPending, approval.Status)
+	require.Equal(t, ApprovalReasonThreshold, approval.Reason)
+	return approval
+}
+
+func TestApproveTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	approver := createRandomUser(t)
+
+	approval := createPendingApproval(t, account1, account2, 10)
+
+	_, err := store.ApproveTransferTx(context.Background(), ApproveTransferTxParams{
+		ApprovalID: approval.ID,
+		Approver:   account1.Owner,
+	})
+	require.ErrorIs(t, err, ErrSelfApproval)
+
+	result, err := store.ApproveTransferTx(context.Background(), ApproveTransferTxParams{
+		ApprovalID: approval.ID,
+		Approver:   approver.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferApprovalStatusApproved, result.Approval.Status)
+	require.Equal(t, approver.Username, result.Approval.DecidedBy)
+	require.True(t, result.Approval.DecidedAt.Valid)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Approval.TransferID.Int64)
+	require.Equal(t, "equipment", result.Transfer.Transfer.Description)
+	require.Equal(t, account1.Balance-10, result.Transfer.FromAccount.Balance)
+	require.Equal(t, account2.Balance+10, result.Transfer.ToAccount.Balance)
+
+	// a decided approval cannot be approved or rejected again
+	_, err = store.ApproveTransferTx(context.Background(), ApproveTransferTxParams{
+		ApprovalID: approval.ID,
+		Approver:   approver.Username,
+	})
+	require.ErrorIs(t, err, ErrApprovalNotPending)
+
+	_, err = store.RejectTransferApproval(context.Background(), RejectTransferApprovalParams{
+		ID:        approval.ID,
+		DecidedBy: approver.Username,
+	})
+	require.Error(t, err)
+}
+
+func TestApproveTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	approver := createRandomUser(t)
+
+	approval := createPendingApproval(t, account1, account2, account1.Balance+1)
+
+	_, err := store.ApproveTransferTx(context.Background(), ApproveTransferTxParams{
+		ApprovalID: approval.ID,
+		Approver:   approver.Username,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the failed approval is rolled back and can still be rejected
+	rejected, err := store.RejectTransferApproval(context.Background(), RejectTransferApprovalParams{
+		ID:        approval.ID,
+		DecidedBy: approver.Username,
+		Note:      "not enough funds",
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferApprovalStatusRejected, rejected.Status)
+	require.False(t, rejected.TransferID.Valid)
+}
+
+func TestApproveTransferTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	approval := createPendingApproval(t, account1, account2, 10)
+
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		approver := createRandomUser(t)
+		go func() {
+			_, err := store.ApproveTransferTx(context.Background(), ApproveTransferTxParams{
+				ApprovalID: approval.ID,
+				Approver:   approver.Username,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrApprovalNotPending)
+	}
+	require.Equal(t, 1, succeeded)
+
+	updated, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-10, updated.Balance)
+}
+
+// withApprovalThreshold makes transfers above threshold out of account need
+// a second approver.
+func withApprovalThreshold(t *testing.T, account Account, threshold int64) Account {
+	account, err := testQueries.SetApprovalThreshold(context.Background(), SetApprovalThresholdParams{
+		ID:                account.ID,
+		ApprovalThreshold: threshold,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+// TestApprovalThresholdEnforced checks that no path moves an amount above
+// the approval threshold without a second approver.
+func TestApprovalThresholdEnforced(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+	from := withApprovalThreshold(t, createRandomAccount(t), 10)
+	to := createRandomAccount(t)
+
+	_, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 11})
+	require.ErrorIs(t, err, ErrApprovalRequired)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 10})
+	require.NoError(t, err)
+
+	_, err = store.BatchTransferTx(ctx, BatchTransferTxParams{
+		Username:      from.Owner,
+		FromAccountID: from.ID,
+		Rows:          []BatchTransferRow{batchRow(1, to, 5), batchRow(2, to, 11)},
+	})
+	require.ErrorIs(t, err, ErrApprovalRequired)
+
+	scheduled := createDueScheduledTransfer(t, from, to, 11)
+	executed := executeUntil(t, store, scheduled.ID, ExecuteScheduledTransferTxParams{MaxAttempts: 3})
+	require.Equal(t, ScheduledTransferStatusFailed, executed.Status)
+	require.Equal(t, ErrApprovalRequired.Error(), executed.FailureReason)
+
+	request := createPendingPaymentRequest(t, from.Owner, to, 11, time.Now().Add(time.Hour))
+	_, err = store.PayPaymentRequestTx(ctx, PayPaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            from.Owner,
+		FromAccountID:    from.ID,
+	})
+	require.ErrorIs(t, err, ErrApprovalRequired)
+
+	_, err = store.PlaceHoldTx(ctx, PlaceHoldTxParams{
+		AccountID: from.ID,
+		Amount:    11,
+		Reference: utils.RandomString(10),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrApprovalRequired)
+
+	escrowTestAccount(t)
+	buyer := withApprovalThreshold(t, createFeeTestAccount(t, 1_000), 10)
+	seller := createFeeTestAccount(t, 0)
+	_, err = store.CreateEscrowTx(ctx, CreateEscrowTxParams{
+		BuyerAccountID:  buyer.ID,
+		SellerAccountID: seller.ID,
+		Amount:          11,
+		CreatedBy:       buyer.Owner,
+		TimeoutAt:       pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true},
+		TimeoutAction:   EscrowActionRefund,
+	})
+	require.ErrorIs(t, err, ErrApprovalRequired)
+
+	// a second approver lifts the threshold
+	approver := createRandomUser(t)
+	approval := createPendingApproval(t, from, to, 11)
+	result, err := store.ApproveTransferTx(ctx, ApproveTransferTxParams{
+		ApprovalID: approval.ID,
+		Approver:   approver.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(11), result.Transfer.Transfer.Amount)
+
+	// only the approved transfer and the one within the threshold moved money
+	updated, err := store.GetAccount(ctx, from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance-21, updated.Balance)
+}
+
+func TestApproveFraudReviewAboveThreshold(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+	from := withApprovalThreshold(t, createRandomAccount(t), 10)
+	to := createRandomAccount(t)
+	banker := createRandomUser(t)
+
+	review, err := testQueries.CreateTransferApproval(ctx, CreateTransferApprovalParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        11,
+		Initiator:     from.Owner,
+		Reason:        ApprovalReasonFraudReview,
+	})
+	require.NoError(t, err)
+
+	// clearing the fraud review leaves the threshold to a second approver
+	result, err := store.ApproveTransferTx(ctx, ApproveTransferTxParams{
+		ApprovalID: review.ID,
+		Approver:   banker.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferApprovalStatusApproved, result.Approval.Status)
+	require.False(t, result.Approval.TransferID.Valid)
+	require.Zero(t, result.Transfer.Transfer.ID)
+	require.NotNil(t, result.Pending)
+	require.Equal(t, ApprovalReasonThreshold, result.Pending.Reason)
+	require.Equal(t, TransferApprovalStatusPending, result.Pending.Status)
+	require.Equal(t, from.Owner, result.Pending.Initiator)
+
+	updated, err := store.GetAccount(ctx, from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, updated.Balance)
+
+	// the initiator still cannot approve it, but another member can
+	_, err = store.ApproveTransferTx(ctx, ApproveTransferTxParams{
+		ApprovalID: result.Pending.ID,
+		Approver:   from.Owner,
+	})
+	require.ErrorIs(t, err, ErrSelfApproval)
+
+	approver := createRandomUser(t)
+	approved, err := store.ApproveTransferTx(ctx, ApproveTransferTxParams{
+		ApprovalID: result.Pending.ID,
+		Approver:   approver.Username,
+	})
+	require.NoError(t, err)
+	require.Nil(t, approved.Pending)
+	require.Equal(t, int64(11), approved.Transfer.Transfer.Amount)
+
+	// a review within the threshold executes at once
+	within, err := testQueries.CreateTransferApproval(ctx, CreateTransferApprovalParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Initiator:     from.Owner,
+		Reason:        ApprovalReasonFraudReview,
+	})
+	require.NoError(t, err)
+
+	executed, err := store.ApproveTransferTx(ctx, ApproveTransferTxParams{
+		ApprovalID: within.ID,
+		Approver:   banker.Username,
+	})
+	require.NoError(t, err)
+	require.Nil(t, executed.Pending)
+	require.Equal(t, int64(10), executed.Transfer.Transfer.Amount)
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	TransferBatchStatusProcessing = "processing"
+	TransferBatchStatusCompleted  = "completed"
+	TransferBatchStatusPartial    = "partial"
+	TransferBatchStatusFailed     = "failed"
+)
+
+const (
+	TransferBatchItemStatusSucceeded = "succeeded"
+	TransferBatchItemStatusFailed    = "failed"
+)
+
+// BatchTransferRow is one validated row of a batch. Rows that failed
+// validation carry the reason in Error and are recorded without moving
+// money.
+type BatchTransferRow struct {
+	RowNumber   int32  `json:"row_number"`
+	ToAccount   string `json:"to_account"`
+	ToAccountID int64  `json:"-"`
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+	Memo        string `json:"memo"`
+	Error       string `json:"error,omitempty"`
+}
+
+type BatchTransferTxParams struct {
+	Username      string `json:"username"`
+	FromAccountID int64  `json:"from_account_id"`
+	// AllowPartial records failing rows and keeps going instead of rolling
+	// the whole batch back.
+	AllowPartial bool               `json:"allow_partial"`
+	Rows         []BatchTransferRow `json:"rows"`
+}
+
+type BatchTransferTxResult struct {
+	Batch TransferBatch       `json:"batch"`
+	Items []TransferBatchItem `json:"items"`
+}
+
+// BatchTransferTx pays every row of a batch from one account in a single
+// transaction. By default any failing row rolls the whole batch back and
+// its error is returned with the row number. With AllowPartial each row
+// runs in a savepoint, failures are recorded on their item and the batch
+// is committed with whatever succeeded.
+func (store *SQLStore) BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		batch, err := q.CreateTransferBatch(ctx, CreateTransferBatchParams{
+			Username:      arg.Username,
+			FromAccountID: arg.FromAccountID,
+			AllowPartial:  arg.AllowPartial,
+		})
+		if err != nil {
+			return err
+		}
+
+		var fromAccount Account
+		var paid []Transfer
+		var succeeded, failed int32
+		result.Items = make([]TransferBatchItem, 0, len(arg.Rows))
+		for _, row := range arg.Rows {
+			if row.Error != "" && !arg.AllowPartial {
+				return fmt.Errorf("row %d: %s", row.RowNumber, row.Error)
+			}
+
+			item := CreateTransferBatchItemParams{
+				BatchID:   batch.ID,
+				RowNumber: row.RowNumber,
+				ToAccount: row.ToAccount,
+				Amount:    row.Amount,
+				Currency:  row.Currency,
+				Memo:      row.Memo,
+				Status:    TransferBatchItemStatusSucceeded,
+				Error:     row.Error,
+			}
+
+			if row.Error == "" {
+				var transferred TransferTxResult
+				pay := func() error {
+					var err error
+					transferred, err = transfer(ctx, q, TransferTxParams{
+						FromAccountID: arg.FromAccountID,
+						ToAccountID:   row.ToAccountID,
+						Amount:        row.Amount,
+						Description:   row.Memo,
+					})
+					if err != nil || !arg.AllowPartial {
+						return err
+					}
+					return checkAvailableBalance(ctx, q, transferred.FromAccount)
+				}
+
+				if arg.AllowPartial {
+					err = withSavepoint(ctx, q, pay)
+				} else {
+					err = pay()
+				}
+
+				if err != nil {
+					if _, retryable := retryableTxError(err); retryable || !arg.AllowPartial {
+						return fmt.Errorf("row %d: %w", row.RowNumber, err)
+					}
+					item.Error = err.Error()
+				} else {
+					fromAccount = transferred.FromAccount
+					paid = append(paid, transferred.Transfer)
+					item.TransferID = pgtype.Int8{Int64: transferred.Transfer.ID, Valid: true}
+				}
+			}
+
+			if item.Error != "" {
+				item.Status = TransferBatchItemStatusFailed
+				failed++
+			} else {
+				succeeded++
+			}
+
+			created, err := q.CreateTransferBatchItem(ctx, item)
+			if err != nil {
+				return err
+			}
+			result.Items = append(result.Items, created)
+		}
+
+		// an atomic batch only has to leave the holds covered once all
+		// rows have been paid
+		if !arg.AllowPartial && succeeded > 0 {
+			if err := checkAvailableBalance(ctx, q, fromAccount); err != nil {
+				return err
+			}
+		}
+
+		status := TransferBatchStatusCompleted
+		switch {
+		case succeeded == 0:
+			status = TransferBatchStatusFailed
+		case failed > 0:
+			status = TransferBatchStatusPartial
+		}
+
+		result.Batch, err = q.FinishTransferBatch(ctx, FinishTransferBatchParams{
+			ID:        batch.ID,
+			Status:    status,
+			Succeeded: succeeded,
+			Failed:    failed,
+		})
+		if err != nil {
+			return err
+		}
+
+		// the events go last so the outbox lock is not held across rows
+		for _, transfer := range paid {
+			if err := recordTransferEvent(ctx, q, transfer); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func batchRow(number int32, to Account, amount int64) BatchTransferRow {
+	return BatchTransferRow{
+		RowNumber:   number,
+		ToAccount:   to.AccountNumber,
+		ToAccountID: to.ID,
+		Amount:      amount,
+		Currency:    to.Currency,
+	}
+}
+
+func TestBatchTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	fromAccount := createRandomAccount(t)
+	payee1 := createRandomAccount(t)
+	payee2 := createRandomAccount(t)
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Username:      fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		Rows:          []BatchTransferRow{batchRow(1, payee1, 10), batchRow(2, payee2, 20)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchStatusCompleted, result.Batch.Status)
+	require.Equal(t, int32(2), result.Batch.Succeeded)
+	require.Len(t, result.Items, 2)
+	for _, item := range result.Items {
+		require.Equal(t, TransferBatchItemStatusSucceeded, item.Status)
+		require.True(t, item.TransferID.Valid)
+	}
+
+	items, err := store.ListTransferBatchItems(context.Background(), result.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Items, items)
+
+	updated, err := store.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance-30, updated.Balance)
+}
+
+func TestBatchTransferTxAtomic(t *testing.T) {
+	store := NewStore(testDB)
+	fromAccount := createRandomAccount(t)
+	payee := createRandomAccount(t)
+
+	// the second row overdraws the account, so neither row is paid
+	_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Username:      fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		Rows:          []BatchTransferRow{batchRow(1, payee, 1), batchRow(2, payee, fromAccount.Balance)},
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updated, err := store.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, updated.Balance)
+}
+
+func TestBatchTransferTxPartial(t *testing.T) {
+	store := NewStore(testDB)
+	fromAccount := createRandomAccount(t)
+	payee := createRandomAccount(t)
+
+	invalid := batchRow(3, payee, 5)
+	invalid.Error = "account not found"
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Username:      fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		AllowPartial:  true,
+		Rows: []BatchTransferRow{
+			batchRow(1, payee, 1),
+			batchRow(2, payee, fromAccount.Balance),
+			invalid,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferBatchStatusPartial, result.Batch.Status)
+	require.Equal(t, int32(1), result.Batch.Succeeded)
+	require.Equal(t, int32(2), result.Batch.Failed)
+
+	require.Equal(t, TransferBatchItemStatusSucceeded, result.Items[0].Status)
+	require.Equal(t, TransferBatchItemStatusFailed, result.Items[1].Status)
+	require.Equal(t, ErrInsufficientFunds.Error(), result.Items[1].Error)
+	require.False(t, result.Items[1].TransferID.Valid)
+	require.Equal(t, invalid.Error, result.Items[2].Error)
+
+	updated, err := store.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance-1, updated.Balance)
+}
@@ -7,24 +7,272 @@ package db
 
 import (
 	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimPendingTransfer = `-- name: ClaimPendingTransfer :one
+UPDATE transfers
+  set status = 'processing', processing_at = now()
+WHERE id = (
+    SELECT id FROM transfers
+    WHERE status = 'pending' OR (status = 'processing' AND processing_at < $1)
+    ORDER BY id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
 )
+RETURNING id, amount, from_account_id, to_account_id, created_at, to_amount, fx_rate, spread_bps, kind, original_transfer_id, description, external_reference, metadata, status, processing_at, completed_at, failed_at, reversed_at, failure_reason
+`
+
+func (q *Queries) ClaimPendingTransfer(ctx context.Context, staleBefore pgtype.Timestamptz) (Transfer, error) {
+	row := q.db.QueryRow(ctx, claimPendingTransfer, staleBefore)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.SpreadBps,
+		&i.Kind,
+		&i.OriginalTransferID,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+		&i.Status,
+		&i.ProcessingAt,
+		&i.CompletedAt,
+		&i.FailedAt,
+		&i.ReversedAt,
+		&i.FailureReason,
+	)
+	return i, err
+}
+
+const completeTransfer = `-- name: CompleteTransfer :one
+UPDATE transfers
+  set status = 'completed', completed_at = now()
+WHERE id = $1 AND status = 'processing'
+RETURNING id, amount, from_account_id, to_account_id, created_at, to_amount, fx_rate, spread_bps, kind, original_transfer_id, description, external_reference, metadata, status, processing_at, completed_at, failed_at, reversed_at, failure_reason
+`
+
+func (q *Queries) CompleteTransfer(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRow(ctx, completeTransfer, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.SpreadBps,
+		&i.Kind,
+		&i.OriginalTransferID,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+		&i.Status,
+		&i.ProcessingAt,
+		&i.CompletedAt,
+		&i.FailedAt,
+		&i.ReversedAt,
+		&i.FailureReason,
+	)
+	return i, err
+}
+
+const countRoundTransfers = `-- name: CountRoundTransfers :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = $1 AND created_at >= $2 AND status <> 'failed'
+    AND amount % $3::bigint = 0
+`
+
+type CountRoundTransfersParams struct {
+	FromAccountID int64              `json:"from_account_id"`
+	Since         pgtype.Timestamptz `json:"since"`
+	RoundTo       int64              `json:"round_to"`
+}
+
+func (q *Queries) CountRoundTransfers(ctx context.Context, arg CountRoundTransfersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countRoundTransfers, arg.FromAccountID, arg.Since, arg.RoundTo)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countTransfersBetween = `-- name: CountTransfersBetween :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = $1 AND to_account_id = $2 AND status <> 'failed'
+`
+
+type CountTransfersBetweenParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+}
+
+func (q *Queries) CountTransfersBetween(ctx context.Context, arg CountTransfersBetweenParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countTransfersBetween, arg.FromAccountID, arg.ToAccountID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createCompensatingTransfer = `-- name: CreateCompensatingTransfer :one
+INSERT INTO transfers (
+    amount, from_account_id, to_account_id, to_amount, fx_rate, spread_bps, kind, original_transfer_id,
+    description, external_reference, status, completed_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 'completed', now()
+) RETURNING id, amount, from_account_id, to_account_id, created_at, to_amount, fx_rate, spread_bps, kind, original_transfer_id, description, external_reference, metadata, status, processing_at, completed_at, failed_at, reversed_at, failure_reason
+`
+
+type CreateCompensatingTransferParams struct {
+	Amount             int64       `json:"amount"`
+	FromAccountID      int64       `json:"from_account_id"`
+	ToAccountID        int64       `json:"to_account_id"`
+	ToAmount           int64       `json:"to_amount"`
+	FxRate             int64       `json:"fx_rate"`
+	SpreadBps          int64       `json:"spread_bps"`
+	Kind               string      `json:"kind"`
+	OriginalTransferID pgtype.Int8 `json:"original_transfer_id"`
+	Description        string      `json:"description"`
+	ExternalReference  string      `json:"external_reference"`
+}
+
+func (q *Queries) CreateCompensatingTransfer(ctx context.Context, arg CreateCompensatingTransferParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, createCompensatingTransfer,
+		arg.Amount,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.ToAmount,
+		arg.FxRate,
+		arg.SpreadBps,
+		arg.Kind,
+		arg.OriginalTransferID,
+		arg.Description,
+		arg.ExternalReference,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.SpreadBps,
+		&i.Kind,
+		&i.OriginalTransferID,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+		&i.Status,
+		&i.ProcessingAt,
+		&i.CompletedAt,
+		&i.FailedAt,
+		&i.ReversedAt,
+		&i.FailureReason,
+	)
+	return i, err
+}
+
+const createPendingTransfer = `-- name: CreatePendingTransfer :one
+INSERT INTO transfers (
+    amount, from_account_id, to_account_id, to_amount, fx_rate, spread_bps,
+    description, external_reference, metadata, status
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, 'pending'
+) RETURNING id, amount, from_account_id, to_account_id, created_at, to_amount, fx_rate, spread_bps, kind, original_transfer_id, description, external_reference, metadata, status, processing_at, completed_at, failed_at, reversed_at, failure_reason
+`
+
+type CreatePendingTransferParams struct {
+	Amount            int64           `json:"amount"`
+	FromAccountID     int64           `json:"from_account_id"`
+	ToAccountID       int64           `json:"to_account_id"`
+	ToAmount          int64           `json:"to_amount"`
+	FxRate            int64           `json:"fx_rate"`
+	SpreadBps         int64           `json:"spread_bps"`
+	Description       string          `json:"description"`
+	ExternalReference string          `json:"external_reference"`
+	Metadata          json.RawMessage `json:"metadata"`
+}
+
+func (q *Queries) CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, createPendingTransfer,
+		arg.Amount,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.ToAmount,
+		arg.FxRate,
+		arg.SpreadBps,
+		arg.Description,
+		arg.ExternalReference,
+		arg.Metadata,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.SpreadBps,
+		&i.Kind,
+		&i.OriginalTransferID,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+		&i.Status,
+		&i.ProcessingAt,
+		&i.CompletedAt,
+		&i.FailedAt,
+		&i.ReversedAt,
+		&i.FailureReason,
+	)
+	return i, err
+}
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
-    amount, from_account_id, to_account_id
+    amount, from_account_id, to_account_id, to_amount, fx_rate, spread_bps,
+    description, external_reference, metadata, status, completed_at
 ) VALUES (
-    $1, $2, $3
-) RETURNING id, amount, from_account_id, to_account_id, created_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, 'completed', now()
+) RETURNING id, amount, from_account_id, to_account_id, created_at, to_amount, fx_rate, spread_bps, kind, original_transfer_id, description, external_reference, metadata, status, processing_at, completed_at, failed_at, reversed_at, failure_reason
 `
 
 type CreateTransferParams struct {
-	Amount        int64 `json:"amount"`
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
+	Amount            int64           `json:"amount"`
+	FromAccountID     int64           `json:"from_account_id"`
+	ToAccountID       int64           `json:"to_account_id"`
+	ToAmount          int64           `json:"to_amount"`
+	FxRate            int64           `json:"fx_rate"`
+	SpreadBps         int64           `json:"spread_bps"`
+	Description       string          `json:"description"`
+	ExternalReference string          `json:"external_reference"`
+	Metadata          json.RawMessage `json:"metadata"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRow(ctx, createTransfer, arg.Amount, arg.FromAccountID, arg.ToAccountID)
+	row := q.db.QueryRow(ctx, createTransfer,
+		arg.Amount,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.ToAmount,
+		arg.FxRate,
+		arg.SpreadBps,
+		arg.Description,
+		arg.ExternalReference,
+		arg.Metadata,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -32,12 +280,77 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.FromAccountID,
 		&i.ToAccountID,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.SpreadBps,
+		&i.Kind,
+		&i.OriginalTransferID,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+		&i.Status,
+		&i.ProcessingAt,
+		&i.CompletedAt,
+		&i.FailedAt,
+		&i.ReversedAt,
+		&i.FailureReason,
 	)
 	return i, err
 }
 
+const failTransfer = `-- name: FailTransfer :one
+UPDATE transfers
+  set status = 'failed', failed_at = now(), failure_reason = $2
+WHERE id = $1 AND status = 'processing'
+RETURNING id, amount, from_account_id, to_account_id, created_at, to_amount, fx_rate, spread_bps, kind, original_transfer_id, description, external_reference, metadata, status, processing_at, completed_at, failed_at, reversed_at, failure_reason
+`
+
+type FailTransferParams struct {
+	ID            int64  `json:"id"`
+	FailureReason string `json:"failure_reason"`
+}
+
+func (q *Queries) FailTransfer(ctx context.Context, arg FailTransferParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, failTransfer, arg.ID, arg.FailureReason)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.SpreadBps,
+		&i.Kind,
+		&i.OriginalTransferID,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+		&i.Status,
+		&i.ProcessingAt,
+		&i.CompletedAt,
+		&i.FailedAt,
+		&i.ReversedAt,
+		&i.FailureReason,
+	)
+	return i, err
+}
+
+const getCompensatedAmount = `-- name: GetCompensatedAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS compensated FROM transfers
+WHERE original_transfer_id = $1
+`
+
+func (q *Queries) GetCompensatedAmount(ctx context.Context, originalTransferID pgtype.Int8) (int64, error) {
+	row := q.db.QueryRow(ctx, getCompensatedAmount, originalTransferID)
+	var compensated int64
+	err := row.Scan(&compensated)
+	return compensated, err
+}
+
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, amount, from_account_id, to_account_id, created_at FROM transfers WHERE id = $1
+SELECT id, amount, from_account_id, to_account_id, created_at, to_amount, fx_rate, spread_bps, kind, original_transfer_id, description, external_reference, metadata, status, processing_at, completed_at, failed_at, reversed_at, failure_reason FROM transfers WHERE id = $1
 `
 
 func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
@@ -49,12 +362,80 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.FromAccountID,
 		&i.ToAccountID,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.SpreadBps,
+		&i.Kind,
+		&i.OriginalTransferID,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+		&i.Status,
+		&i.ProcessingAt,
+		&i.CompletedAt,
+		&i.FailedAt,
+		&i.ReversedAt,
+		&i.FailureReason,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, amount, from_account_id, to_account_id, created_at, to_amount, fx_rate, spread_bps, kind, original_transfer_id, description, external_reference, metadata, status, processing_at, completed_at, failed_at, reversed_at, failure_reason FROM transfers WHERE id = $1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRow(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.SpreadBps,
+		&i.Kind,
+		&i.OriginalTransferID,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+		&i.Status,
+		&i.ProcessingAt,
+		&i.CompletedAt,
+		&i.FailedAt,
+		&i.ReversedAt,
+		&i.FailureReason,
 	)
 	return i, err
 }
 
+const getTransferVelocity = `-- name: GetTransferVelocity :one
+SELECT COUNT(*)::bigint AS count, COALESCE(SUM(amount), 0)::bigint AS total FROM transfers
+WHERE from_account_id = $1 AND created_at >= $2 AND status <> 'failed'
+`
+
+type GetTransferVelocityParams struct {
+	FromAccountID int64              `json:"from_account_id"`
+	Since         pgtype.Timestamptz `json:"since"`
+}
+
+type GetTransferVelocityRow struct {
+	Count int64 `json:"count"`
+	Total int64 `json:"total"`
+}
+
+func (q *Queries) GetTransferVelocity(ctx context.Context, arg GetTransferVelocityParams) (GetTransferVelocityRow, error) {
+	row := q.db.QueryRow(ctx, getTransferVelocity, arg.FromAccountID, arg.Since)
+	var i GetTransferVelocityRow
+	err := row.Scan(&i.Count, &i.Total)
+	return i, err
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, amount, from_account_id, to_account_id, created_at FROM transfers WHERE 
+SELECT id, amount, from_account_id, to_account_id, created_at, to_amount, fx_rate, spread_bps, kind, original_transfer_id, description, external_reference, metadata, status, processing_at, completed_at, failed_at, reversed_at, failure_reason FROM transfers WHERE 
     from_account_id = $1 OR to_account_id = $2
 ORDER BY id
 LIMIT $3
@@ -88,6 +469,20 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.FromAccountID,
 			&i.ToAccountID,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.FxRate,
+			&i.SpreadBps,
+			&i.Kind,
+			&i.OriginalTransferID,
+			&i.Description,
+			&i.ExternalReference,
+			&i.Metadata,
+			&i.Status,
+			&i.ProcessingAt,
+			&i.CompletedAt,
+			&i.FailedAt,
+			&i.ReversedAt,
+			&i.FailureReason,
 		); err != nil {
 			return nil, err
 		}
@@ -98,3 +493,98 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 	}
 	return items, nil
 }
+
+const listTransfersByReference = `-- name: ListTransfersByReference :many
+SELECT id, amount, from_account_id, to_account_id, created_at, to_amount, fx_rate, spread_bps, kind, original_transfer_id, description, external_reference, metadata, status, processing_at, completed_at, failed_at, reversed_at, failure_reason FROM transfers WHERE
+    (from_account_id = $1 OR to_account_id = $1)
+    AND external_reference = $2
+ORDER BY id
+LIMIT $3
+OFFSET $4
+`
+
+type ListTransfersByReferenceParams struct {
+	AccountID         int64  `json:"account_id"`
+	ExternalReference string `json:"external_reference"`
+	Limit             int32  `json:"limit"`
+	Offset            int32  `json:"offset"`
+}
+
+func (q *Queries) ListTransfersByReference(ctx context.Context, arg ListTransfersByReferenceParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listTransfersByReference,
+		arg.AccountID,
+		arg.ExternalReference,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.FxRate,
+			&i.SpreadBps,
+			&i.Kind,
+			&i.OriginalTransferID,
+			&i.Description,
+			&i.ExternalReference,
+			&i.Metadata,
+			&i.Status,
+			&i.ProcessingAt,
+			&i.CompletedAt,
+			&i.FailedAt,
+			&i.ReversedAt,
+			&i.FailureReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markTransferReversed = `-- name: MarkTransferReversed :one
+UPDATE transfers
+  set status = 'reversed', reversed_at = now()
+WHERE id = $1 AND status = 'completed'
+RETURNING id, amount, from_account_id, to_account_id, created_at, to_amount, fx_rate, spread_bps, kind, original_transfer_id, description, external_reference, metadata, status, processing_at, completed_at, failed_at, reversed_at, failure_reason
+`
+
+func (q *Queries) MarkTransferReversed(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRow(ctx, markTransferReversed, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.SpreadBps,
+		&i.Kind,
+		&i.OriginalTransferID,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+		&i.Status,
+		&i.ProcessingAt,
+		&i.CompletedAt,
+		&i.FailedAt,
+		&i.ReversedAt,
+		&i.FailureReason,
+	)
+	return i, err
+}
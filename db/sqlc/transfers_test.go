@@ -3,17 +3,22 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
 	"github.com/thanhphuocnguyen/go-simple-bank/utils"
 )
 
 func createNewTransfer(t *testing.T, account1ID, account2ID int64) Transfer {
 
+	amount := utils.RandomMoney()
 	entryModel := CreateTransferParams{
-		Amount:        utils.RandomMoney(),
+		Amount:        amount,
 		FromAccountID: account1ID,
 		ToAccountID:   account2ID,
+		ToAmount:      amount,
+		FxRate:        FxRateScale,
 	}
 	transfer, err := testQueries.CreateTransfer(context.Background(), entryModel)
 
@@ -21,6 +26,8 @@ func createNewTransfer(t *testing.T, account1ID, account2ID int64) Transfer {
 	require.NotEmpty(t, transfer)
 	require.NotEmpty(t, transfer.FromAccountID)
 	require.NotEmpty(t, transfer.ToAccountID)
+	require.Equal(t, entryModel.ToAmount, transfer.ToAmount)
+	require.Equal(t, entryModel.FxRate, transfer.FxRate)
 	require.NotEmpty(t, transfer.CreatedAt)
 
 	return transfer
@@ -69,3 +76,93 @@ func TestListTransfers(t *testing.T) {
 		require.NotEmpty(t, transfer)
 	}
 }
+
+func TestListTransfersByReference(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	reference := utils.RandomString(12)
+
+	for i := 0; i < 3; i++ {
+		_, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID:     account1.ID,
+			ToAccountID:       account2.ID,
+			Amount:            utils.RandomMoney(),
+			ToAmount:          1,
+			FxRate:            FxRateScale,
+			ExternalReference: reference,
+		})
+		require.NoError(t, err)
+	}
+	createNewTransfer(t, account1.ID, account2.ID)
+
+	transfers, err := testQueries.ListTransfersByReference(context.Background(), ListTransfersByReferenceParams{
+		AccountID:         account2.ID,
+		ExternalReference: reference,
+		Limit:             5,
+		Offset:            0,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 3)
+	for _, transfer := range transfers {
+		require.Equal(t, reference, transfer.ExternalReference)
+	}
+}
+
+func TestTransferVelocity(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+
+	var total int64
+	for _, amount := range []int64{5_000, 20_000, 1_234} {
+		transfer, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+			Amount:        amount,
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			ToAmount:      amount,
+			FxRate:        FxRateScale,
+		})
+		require.NoError(t, err)
+		total += transfer.Amount
+	}
+
+	since := pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true}
+	velocity, err := testQueries.GetTransferVelocity(context.Background(), GetTransferVelocityParams{
+		FromAccountID: account1.ID,
+		Since:         since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), velocity.Count)
+	require.Equal(t, total, velocity.Total)
+
+	later := pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true}
+	velocity, err = testQueries.GetTransferVelocity(context.Background(), GetTransferVelocityParams{
+		FromAccountID: account1.ID,
+		Since:         later,
+	})
+	require.NoError(t, err)
+	require.Zero(t, velocity.Count)
+	require.Zero(t, velocity.Total)
+
+	round, err := testQueries.CountRoundTransfers(context.Background(), CountRoundTransfersParams{
+		FromAccountID: account1.ID,
+		Since:         since,
+		RoundTo:       5_000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), round)
+
+	count, err := testQueries.CountTransfersBetween(context.Background(), CountTransfersBetweenParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+
+	count, err = testQueries.CountTransfersBetween(context.Background(), CountTransfersBetweenParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account3.ID,
+	})
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
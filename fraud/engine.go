@@ -0,0 +1,129 @@
+package fraud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+// Transfer is what the rules look at.
+type Transfer struct {
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	Currency      string
+}
+
+// Decision is the outcome of screening a transfer. Rule and Reason name
+// the rule that fired and are empty when the transfer is allowed.
+type Decision struct {
+	Outcome string `json:"outcome"`
+	Rule    string `json:"rule"`
+	Reason  string `json:"reason"`
+}
+
+// Engine screens transfers against its rules using the transfer history in
+// the store.
+type Engine struct {
+	rules []Rule
+	store db.Querier
+	now   func() time.Time
+}
+
+func NewEngine(rules []Rule, store db.Querier) (*Engine, error) {
+	checked := make([]Rule, len(rules))
+	copy(checked, rules)
+	for i := range checked {
+		if err := checked[i].validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Engine{rules: checked, store: store, now: time.Now}, nil
+}
+
+// Evaluate runs every rule that applies to the transfer. A block beats a
+// hold; otherwise the first rule that fires decides.
+func (engine *Engine) Evaluate(ctx context.Context, transfer Transfer) (Decision, error) {
+	decision := Decision{Outcome: db.FraudOutcomeAllow}
+	for _, rule := range engine.rules {
+		if rule.Currency != "" && rule.Currency != transfer.Currency {
+			continue
+		}
+
+		reason, fired, err := engine.check(ctx, rule, transfer)
+		if err != nil {
+			return Decision{}, err
+		}
+		if !fired {
+			continue
+		}
+
+		if rule.Action == db.FraudOutcomeBlock {
+			return Decision{Outcome: rule.Action, Rule: rule.Name, Reason: reason}, nil
+		}
+		if decision.Outcome == db.FraudOutcomeAllow {
+			decision = Decision{Outcome: rule.Action, Rule: rule.Name, Reason: reason}
+		}
+	}
+
+	return decision, nil
+}
+
+// check reports whether rule fires for transfer, and why.
+func (engine *Engine) check(ctx context.Context, rule Rule, transfer Transfer) (string, bool, error) {
+	since := pgtype.Timestamptz{Time: engine.now().Add(-rule.window), Valid: true}
+
+	switch rule.Kind {
+	case KindTransferCount, KindAmountTotal:
+		velocity, err := engine.store.GetTransferVelocity(ctx, db.GetTransferVelocityParams{
+			FromAccountID: transfer.FromAccountID,
+			Since:         since,
+		})
+		if err != nil {
+			return "", false, err
+		}
+
+		if rule.Kind == KindTransferCount {
+			count := velocity.Count + 1
+			return fmt.Sprintf("%d transfers within %s, limit %d", count, rule.window, rule.Limit), count > rule.Limit, nil
+		}
+		total := velocity.Total + transfer.Amount
+		return fmt.Sprintf("%d sent within %s, limit %d", total, rule.window, rule.Limit), total > rule.Limit, nil
+
+	case KindNewRecipient:
+		if transfer.Amount <= rule.Limit {
+			return "", false, nil
+		}
+
+		count, err := engine.store.CountTransfersBetween(ctx, db.CountTransfersBetweenParams{
+			FromAccountID: transfer.FromAccountID,
+			ToAccountID:   transfer.ToAccountID,
+		})
+		if err != nil {
+			return "", false, err
+		}
+		return fmt.Sprintf("first transfer to this recipient is %d, limit %d", transfer.Amount, rule.Limit), count == 0, nil
+
+	case KindRoundAmountBurst:
+		if transfer.Amount%rule.RoundTo != 0 {
+			return "", false, nil
+		}
+
+		count, err := engine.store.CountRoundTransfers(ctx, db.CountRoundTransfersParams{
+			FromAccountID: transfer.FromAccountID,
+			Since:         since,
+			RoundTo:       rule.RoundTo,
+		})
+		if err != nil {
+			return "", false, err
+		}
+		count++
+		return fmt.Sprintf("%d transfers in multiples of %d within %s, limit %d", count, rule.RoundTo, rule.window, rule.Limit), count > rule.Limit, nil
+	}
+
+	return "", false, fmt.Errorf("unknown rule kind %q", rule.Kind)
+}
@@ -0,0 +1,211 @@
+package fraud
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+func writeRulesFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "fraud_rules.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadRules(t *testing.T) {
+	rules, err := LoadRules(writeRulesFile(t, `[
+		{"name": "hourly", "kind": "transfer_count", "window": "1h", "limit": 5, "action": "block"},
+		{"name": "first", "kind": "new_recipient", "limit": 1000, "currency": "USD", "action": "hold"}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	require.Equal(t, time.Hour, rules[0].window)
+	require.Equal(t, "USD", rules[1].Currency)
+}
+
+func TestLoadRulesInvalid(t *testing.T) {
+	for _, content := range []string{
+		`not json`,
+		`[{"kind": "transfer_count", "window": "1h", "limit": 5, "action": "block"}]`,
+		`[{"name": "a", "kind": "transfer_count", "window": "1h", "limit": 5, "action": "alert"}]`,
+		`[{"name": "a", "kind": "transfer_count", "window": "soon", "limit": 5, "action": "block"}]`,
+		`[{"name": "a", "kind": "amount_total", "limit": 5, "action": "block"}]`,
+		`[{"name": "a", "kind": "round_amount_burst", "window": "1h", "limit": 5, "action": "hold"}]`,
+		`[{"name": "a", "kind": "new_recipient", "limit": 5, "currency": "XXX", "action": "hold"}]`,
+		`[{"name": "a", "kind": "geo", "window": "1h", "limit": 5, "action": "hold"}]`,
+	} {
+		_, err := LoadRules(writeRulesFile(t, content))
+		require.Error(t, err, content)
+	}
+
+	_, err := LoadRules(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
+
+func TestRepositoryRulesFile(t *testing.T) {
+	_, err := LoadRules("../fraud_rules.json")
+	require.NoError(t, err)
+}
+
+func TestEvaluate(t *testing.T) {
+	transfer := Transfer{FromAccountID: 1, ToAccountID: 2, Amount: 50_000, Currency: "USD"}
+	countRule := Rule{Name: "hourly", Kind: KindTransferCount, Window: "1h", Limit: 5, Action: db.FraudOutcomeBlock}
+	totalRule := Rule{Name: "daily", Kind: KindAmountTotal, Window: "24h", Limit: 100_000, Action: db.FraudOutcomeHold}
+	newRule := Rule{Name: "first", Kind: KindNewRecipient, Limit: 10_000, Action: db.FraudOutcomeHold}
+	roundRule := Rule{Name: "round", Kind: KindRoundAmountBurst, Window: "1h", RoundTo: 10_000, Limit: 3, Action: db.FraudOutcomeHold}
+
+	testCases := []struct {
+		name       string
+		rules      []Rule
+		buildStubs func(store *mockdb.MockStore)
+		decision   Decision
+		hasErr     bool
+	}{
+		{
+			name:     "NoRules",
+			decision: Decision{Outcome: db.FraudOutcomeAllow},
+		},
+		{
+			name:  "UnderCount",
+			rules: []Rule{countRule},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferVelocity(gomock.Any(), gomock.Any()).Times(1).Return(db.GetTransferVelocityRow{Count: 4}, nil)
+			},
+			decision: Decision{Outcome: db.FraudOutcomeAllow},
+		},
+		{
+			name:  "OverCount",
+			rules: []Rule{countRule},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferVelocity(gomock.Any(), gomock.Any()).Times(1).Return(db.GetTransferVelocityRow{Count: 5}, nil)
+			},
+			decision: Decision{Outcome: db.FraudOutcomeBlock, Rule: "hourly", Reason: "6 transfers within 1h0m0s, limit 5"},
+		},
+		{
+			name:  "OverTotal",
+			rules: []Rule{totalRule},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferVelocity(gomock.Any(), gomock.Any()).Times(1).Return(db.GetTransferVelocityRow{Count: 1, Total: 60_000}, nil)
+			},
+			decision: Decision{Outcome: db.FraudOutcomeHold, Rule: "daily", Reason: "110000 sent within 24h0m0s, limit 100000"},
+		},
+		{
+			name:  "NewRecipient",
+			rules: []Rule{newRule},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CountTransfersBetweenParams{FromAccountID: 1, ToAccountID: 2}
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(0), nil)
+			},
+			decision: Decision{Outcome: db.FraudOutcomeHold, Rule: "first", Reason: "first transfer to this recipient is 50000, limit 10000"},
+		},
+		{
+			name:  "KnownRecipient",
+			rules: []Rule{newRule},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(1).Return(int64(3), nil)
+			},
+			decision: Decision{Outcome: db.FraudOutcomeAllow},
+		},
+		{
+			name:     "NewRecipientSmallAmount",
+			rules:    []Rule{{Name: "first", Kind: KindNewRecipient, Limit: 50_000, Action: db.FraudOutcomeHold}},
+			decision: Decision{Outcome: db.FraudOutcomeAllow},
+		},
+		{
+			name:  "RoundAmountBurst",
+			rules: []Rule{roundRule},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountRoundTransfers(gomock.Any(), gomock.Any()).Times(1).Return(int64(3), nil)
+			},
+			decision: Decision{Outcome: db.FraudOutcomeHold, Rule: "round", Reason: "4 transfers in multiples of 10000 within 1h0m0s, limit 3"},
+		},
+		{
+			name:     "NotRoundAmount",
+			rules:    []Rule{{Name: "round", Kind: KindRoundAmountBurst, Window: "1h", RoundTo: 30_000, Limit: 3, Action: db.FraudOutcomeHold}},
+			decision: Decision{Outcome: db.FraudOutcomeAllow},
+		},
+		{
+			name:     "OtherCurrency",
+			rules:    []Rule{{Name: "eur", Kind: KindNewRecipient, Currency: "EUR", Action: db.FraudOutcomeBlock}},
+			decision: Decision{Outcome: db.FraudOutcomeAllow},
+		},
+		{
+			name:  "BlockBeatsHold",
+			rules: []Rule{newRule, countRule},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetTransferVelocity(gomock.Any(), gomock.Any()).Times(1).Return(db.GetTransferVelocityRow{Count: 10}, nil)
+			},
+			decision: Decision{Outcome: db.FraudOutcomeBlock, Rule: "hourly", Reason: "11 transfers within 1h0m0s, limit 5"},
+		},
+		{
+			name:  "FirstHoldWins",
+			rules: []Rule{newRule, totalRule},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().GetTransferVelocity(gomock.Any(), gomock.Any()).Times(1).Return(db.GetTransferVelocityRow{Total: 90_000}, nil)
+			},
+			decision: Decision{Outcome: db.FraudOutcomeHold, Rule: "first", Reason: "first transfer to this recipient is 50000, limit 10000"},
+		},
+		{
+			name:  "StoreError",
+			rules: []Rule{countRule},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferVelocity(gomock.Any(), gomock.Any()).Times(1).Return(db.GetTransferVelocityRow{}, sql.ErrConnDone)
+			},
+			hasErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			if tc.buildStubs != nil {
+				tc.buildStubs(store)
+			}
+
+			engine, err := NewEngine(tc.rules, store)
+			require.NoError(t, err)
+
+			decision, err := engine.Evaluate(context.Background(), transfer)
+			if tc.hasErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.decision, decision)
+		})
+	}
+}
+
+func TestEvaluateWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetTransferVelocity(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.GetTransferVelocityParams) (db.GetTransferVelocityRow, error) {
+			require.Equal(t, int64(7), arg.FromAccountID)
+			require.Equal(t, now.Add(-2*time.Hour), arg.Since.Time)
+			return db.GetTransferVelocityRow{}, nil
+		})
+
+	engine, err := NewEngine([]Rule{{Name: "a", Kind: KindTransferCount, Window: "2h", Limit: 1, Action: db.FraudOutcomeBlock}}, store)
+	require.NoError(t, err)
+	engine.now = func() time.Time { return now }
+
+	_, err = engine.Evaluate(context.Background(), Transfer{FromAccountID: 7, ToAccountID: 8, Amount: 1, Currency: "USD"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,111 @@
+// Package fraud screens transfers against configurable rules before money
+// moves.
+package fraud
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+// Rule kinds. Counts and totals cover the source account's outgoing
+// transfers within the rule's window and include the transfer being
+// screened.
+const (
+	// KindTransferCount fires on more than Limit transfers.
+	KindTransferCount = "transfer_count"
+	// KindAmountTotal fires when the amounts add up to more than Limit.
+	KindAmountTotal = "amount_total"
+	// KindNewRecipient fires on a first transfer to a recipient above
+	// Limit. It has no window.
+	KindNewRecipient = "new_recipient"
+	// KindRoundAmountBurst fires on more than Limit transfers whose amount
+	// is a multiple of RoundTo.
+	KindRoundAmountBurst = "round_amount_burst"
+)
+
+// Rule is one check from the rules file, for example:
+//
+//	[
+//	  {"name": "hourly-count", "kind": "transfer_count", "window": "1h", "limit": 10, "action": "block"},
+//	  {"name": "big-first-payment", "kind": "new_recipient", "limit": 100000, "currency": "USD", "action": "hold"}
+//	]
+//
+// Amounts are in the minor units of the source account. A rule with a
+// Currency only applies to transfers in it.
+type Rule struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	Action   string `json:"action"`
+	Currency string `json:"currency"`
+	Window   string `json:"window"`
+	Limit    int64  `json:"limit"`
+	RoundTo  int64  `json:"round_to"`
+
+	window time.Duration
+}
+
+// LoadRules reads and checks the rules in a JSON file.
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+
+	for i := range rules {
+		if err := rules[i].validate(); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+func (rule *Rule) validate() error {
+	if rule.Name == "" {
+		return fmt.Errorf("rule of kind %q has no name", rule.Kind)
+	}
+
+	switch rule.Action {
+	case db.FraudOutcomeBlock, db.FraudOutcomeHold:
+	default:
+		return fmt.Errorf("rule %q: action must be %q or %q", rule.Name, db.FraudOutcomeBlock, db.FraudOutcomeHold)
+	}
+
+	if rule.Currency != "" {
+		if _, ok := utils.LookupCurrency(rule.Currency); !ok {
+			return fmt.Errorf("rule %q: unknown currency code %q", rule.Name, rule.Currency)
+		}
+	}
+
+	if rule.Limit < 0 {
+		return fmt.Errorf("rule %q: limit must not be negative", rule.Name)
+	}
+
+	switch rule.Kind {
+	case KindNewRecipient:
+		return nil
+	case KindTransferCount, KindAmountTotal:
+	case KindRoundAmountBurst:
+		if rule.RoundTo <= 0 {
+			return fmt.Errorf("rule %q: round_to must be positive", rule.Name)
+		}
+	default:
+		return fmt.Errorf("rule %q: unknown kind %q", rule.Name, rule.Kind)
+	}
+
+	window, err := time.ParseDuration(rule.Window)
+	if err != nil || window <= 0 {
+		return fmt.Errorf("rule %q: invalid window %q", rule.Name, rule.Window)
+	}
+	rule.window = window
+	return nil
+}
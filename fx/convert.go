@@ -0,0 +1,45 @@
+package fx
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+const maxSpreadBps = 10000
+
+var (
+	ErrAmountTooSmall = errors.New("amount is too small to convert")
+	ErrAmountTooLarge = errors.New("converted amount is too large")
+	ErrInvalidSpread  = errors.New("spread must be between 0 and 10000 basis points")
+)
+
+// Convert turns an amount in from's minor units into to's minor units at
+// rate, less spreadBps basis points. The result is rounded down so the
+// bank never credits more than it debits.
+func Convert(amount, rate, spreadBps int64, from, to utils.Currency) (int64, error) {
+	if spreadBps < 0 || spreadBps >= maxSpreadBps {
+		return 0, ErrInvalidSpread
+	}
+
+	numerator := new(big.Int).Mul(big.NewInt(amount), big.NewInt(rate))
+	numerator.Mul(numerator, big.NewInt(maxSpreadBps-spreadBps))
+	numerator.Mul(numerator, pow10(to.MinorUnits))
+
+	denominator := new(big.Int).Mul(big.NewInt(RateScale), big.NewInt(maxSpreadBps))
+	denominator.Mul(denominator, pow10(from.MinorUnits))
+
+	converted := numerator.Quo(numerator, denominator)
+	if !converted.IsInt64() {
+		return 0, ErrAmountTooLarge
+	}
+	if converted.Sign() <= 0 {
+		return 0, ErrAmountTooSmall
+	}
+	return converted.Int64(), nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,49 @@
+package fx
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+func TestConvert(t *testing.T) {
+	usd, _ := utils.LookupCurrency(utils.USD)
+	eur, _ := utils.LookupCurrency(utils.EUR)
+	jpy, _ := utils.LookupCurrency("JPY")
+	kwd, _ := utils.LookupCurrency("KWD")
+
+	testCases := []struct {
+		name      string
+		amount    int64
+		rate      int64
+		spreadBps int64
+		from      utils.Currency
+		to        utils.Currency
+		converted int64
+		err       error
+	}{
+		{"SameScale", 10_000, 92_000_000, 0, usd, eur, 9_200, nil},
+		{"Spread", 10_000, 92_000_000, 50, usd, eur, 9_154, nil},
+		{"RoundsDown", 1, 92_000_000, 0, usd, eur, 0, ErrAmountTooSmall},
+		{"ToZeroMinorUnits", 10_050, 15_012_000_000, 0, usd, jpy, 15_087, nil},
+		{"FromZeroMinorUnits", 1_000, 670_000, 0, jpy, usd, 670, nil},
+		{"ToThreeMinorUnits", 10_000, 30_700_000, 0, usd, kwd, 30_700, nil},
+		{"InvalidSpread", 10_000, 92_000_000, 10_000, usd, eur, 0, ErrInvalidSpread},
+		{"NegativeSpread", 10_000, 92_000_000, -1, usd, eur, 0, ErrInvalidSpread},
+		{"Overflow", math.MaxInt64, 15_012_000_000, 0, usd, jpy, 0, ErrAmountTooLarge},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			converted, err := Convert(tc.amount, tc.rate, tc.spreadBps, tc.from, tc.to)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.converted, converted)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+)
+
+// FileProvider serves fixed rates read from a JSON file, for local use:
+//
+//	{"EUR/USD": "1.0850", "USD/EUR": "0.9200"}
+//
+// Each direction must be listed; rates are not inverted.
+type FileProvider struct {
+	rates map[string]int64
+}
+
+func NewFileProvider(path string) (*FileProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+
+	provider := &FileProvider{rates: make(map[string]int64, len(raw))}
+	for pair, value := range raw {
+		base, quote, ok := strings.Cut(pair, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid currency pair %q", pair)
+		}
+		for _, code := range []string{base, quote} {
+			if _, ok := utils.LookupCurrency(code); !ok {
+				return nil, fmt.Errorf("unknown currency code %q in pair %q", code, pair)
+			}
+		}
+
+		rate, err := ParseRate(value)
+		if err != nil {
+			return nil, err
+		}
+		provider.rates[pairKey(base, quote)] = rate
+	}
+
+	return provider, nil
+}
+
+func (provider *FileProvider) Rate(ctx context.Context, base, quote string) (int64, error) {
+	rate, ok := provider.rates[pairKey(base, quote)]
+	if !ok {
+		return 0, ErrRateNotFound
+	}
+	return rate, nil
+}
+
+func pairKey(base, quote string) string {
+	return base + "/" + quote
+}
@@ -0,0 +1,53 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeRatesFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "fx_rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestFileProvider(t *testing.T) {
+	path := writeRatesFile(t, `{"EUR/USD": "1.085", "USD/EUR": "0.92"}`)
+	provider, err := NewFileProvider(path)
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	require.Equal(t, int64(108_500_000), rate)
+
+	rate, err = provider.Rate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, int64(92_000_000), rate)
+
+	_, err = provider.Rate(context.Background(), "USD", "CAD")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestFileProviderInvalid(t *testing.T) {
+	for _, content := range []string{
+		`not json`,
+		`{"EURUSD": "1.085"}`,
+		`{"EUR/XXX": "1.085"}`,
+		`{"EUR/USD": "-1"}`,
+	} {
+		_, err := NewFileProvider(writeRatesFile(t, content))
+		require.Error(t, err, content)
+	}
+
+	_, err := NewFileProvider(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
+
+func TestRepositoryRatesFile(t *testing.T) {
+	_, err := NewFileProvider("../fx_rates.json")
+	require.NoError(t, err)
+}
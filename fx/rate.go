@@ -0,0 +1,44 @@
+// Package fx supplies exchange rates and converts amounts between currencies.
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+// RateScale is the fixed-point scale of every rate: a rate of 1.085 is
+// stored as 108500000.
+const RateScale = db.FxRateScale
+
+const rateDecimals = 8
+
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// Provider returns how many units of quote one unit of base buys,
+// scaled by RateScale.
+type Provider interface {
+	Rate(ctx context.Context, base, quote string) (int64, error)
+}
+
+// ParseRate turns a decimal string such as "1.0850" into a scaled rate.
+func ParseRate(s string) (int64, error) {
+	whole, frac, _ := strings.Cut(strings.TrimSpace(s), ".")
+	if len(frac) > rateDecimals {
+		return 0, fmt.Errorf("rate %q has more than %d decimal places", s, rateDecimals)
+	}
+	frac += strings.Repeat("0", rateDecimals-len(frac))
+
+	rate, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || strings.HasPrefix(whole, "-") || strings.HasPrefix(whole, "+") {
+		return 0, fmt.Errorf("invalid rate %q", s)
+	}
+	if rate == 0 {
+		return 0, fmt.Errorf("rate %q must be positive", s)
+	}
+	return rate, nil
+}
@@ -0,0 +1,38 @@
+package fx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	testCases := []struct {
+		input string
+		rate  int64
+		valid bool
+	}{
+		{"1", 100_000_000, true},
+		{"1.085", 108_500_000, true},
+		{" 0.92 ", 92_000_000, true},
+		{"155.12345678", 15_512_345_678, true},
+		{"0.000000001", 0, false},
+		{"0", 0, false},
+		{"-1.2", 0, false},
+		{"1.2.3", 0, false},
+		{"abc", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			rate, err := ParseRate(tc.input)
+			if !tc.valid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.rate, rate)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package fx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+// StoreProvider serves the most recent rate recorded in the fx_rates table.
+type StoreProvider struct {
+	store db.Querier
+}
+
+func NewStoreProvider(store db.Querier) *StoreProvider {
+	return &StoreProvider{store: store}
+}
+
+func (provider *StoreProvider) Rate(ctx context.Context, base, quote string) (int64, error) {
+	rate, err := provider.store.GetLatestFxRate(ctx, db.GetLatestFxRateParams{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrRateNotFound
+		}
+		return 0, err
+	}
+	return rate.Rate, nil
+}
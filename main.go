@@ -2,13 +2,18 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/thanhphuocnguyen/go-simple-bank/api"
 	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/outbox"
 	"github.com/thanhphuocnguyen/go-simple-bank/utils"
+	"github.com/thanhphuocnguyen/go-simple-bank/worker"
 )
 
 func main() {
@@ -23,6 +28,33 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
+
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		os.Exit(reconcile(store, os.Args[2:]))
+	}
+
+	scheduledTransfers := worker.NewScheduledTransferRunner(store, config.SchedulerInterval, db.ExecuteScheduledTransferTxParams{
+		MaxAttempts: config.ScheduledMaxAttempts,
+		RetryDelay:  config.ScheduledRetryDelay,
+	})
+	go scheduledTransfers.Run(context.Background())
+
+	standingOrders := worker.NewStandingOrderRunner(store, config.SchedulerInterval)
+	go standingOrders.Run(context.Background())
+
+	escrows := worker.NewEscrowRunner(store, config.SchedulerInterval)
+	go escrows.Run(context.Background())
+
+	transfers := worker.NewTransferProcessor(store, config.TransferWorkers, config.TransferPollInterval, config.TransferStaleAfter)
+	go transfers.Run(context.Background())
+
+	publisher, err := newPublisher(config.OutboxPublisher)
+	if err != nil {
+		log.Fatalf("cannot create outbox publisher: %v", err)
+	}
+	relay := worker.NewOutboxRelay(store, publisher, config.OutboxRelayName, config.OutboxPollInterval, config.OutboxBatchSize)
+	go relay.Run(context.Background())
+
 	server, err := api.NewServer(config, store)
 	if err != nil {
 		log.Fatalf("cannot create server: %v", err)
@@ -34,3 +66,50 @@ func main() {
 		log.Fatalf("cannot start server: %v", err)
 	}
 }
+
+// newPublisher returns the outbox publisher named by OUTBOX_PUBLISHER. The
+// memory publisher is left out: nothing would ever read its events.
+func newPublisher(name string) (outbox.Publisher, error) {
+	switch name {
+	case "", "stdout":
+		return outbox.NewStdoutPublisher(os.Stdout), nil
+	}
+	return nil, fmt.Errorf("unknown outbox publisher %q", name)
+}
+
+// reconcile runs "main reconcile [-format text|json]": it checks the ledger,
+// prints the report and returns the exit code, 1 when problems were found
+// and 2 when the check itself failed.
+func reconcile(store db.Store, args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	format := flags.String("format", "text", "report format, text or json")
+	flags.Parse(args)
+
+	if *format != "text" && *format != "json" {
+		log.Printf("unknown report format %q", *format)
+		return 2
+	}
+
+	report, err := store.ReconcileTx(context.Background())
+	if err != nil {
+		log.Printf("cannot reconcile ledger: %v", err)
+		return 2
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Printf("cannot write report: %v", err)
+		return 2
+	}
+
+	if !report.OK {
+		return 1
+	}
+	return 0
+}
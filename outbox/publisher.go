@@ -0,0 +1,71 @@
+// Package outbox publishes the domain events the store records in the
+// outbox table.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+// Publisher delivers an event to its consumers. The relay may hand it the
+// same event more than once, after a crash or a failed batch, so consumers
+// must deduplicate on the event id.
+type Publisher interface {
+	Publish(ctx context.Context, event db.Outbox) error
+}
+
+// StdoutPublisher writes each event as a line of JSON.
+type StdoutPublisher struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewStdoutPublisher returns a publisher writing to w, or to os.Stdout when
+// w is nil.
+func NewStdoutPublisher(w io.Writer) *StdoutPublisher {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &StdoutPublisher{encoder: json.NewEncoder(w)}
+}
+
+func (publisher *StdoutPublisher) Publish(ctx context.Context, event db.Outbox) error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	return publisher.encoder.Encode(event)
+}
+
+// MemoryPublisher keeps the events it is handed, for tests and for running
+// without a broker.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []db.Outbox
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (publisher *MemoryPublisher) Publish(ctx context.Context, event db.Outbox) error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	publisher.events = append(publisher.events, event)
+	return nil
+}
+
+// Events returns a copy of the events published so far, in order.
+func (publisher *MemoryPublisher) Events() []db.Outbox {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	events := make([]db.Outbox, len(publisher.events))
+	copy(events, publisher.events)
+	return events
+}
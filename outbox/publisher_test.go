@@ -0,0 +1,69 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+func testEvents() []db.Outbox {
+	return []db.Outbox{
+		{
+			ID:            1,
+			AggregateType: db.AggregateUser,
+			AggregateID:   "alice",
+			EventType:     db.EventUserCreated,
+			Version:       1,
+			Payload:       json.RawMessage(`{"username":"alice"}`),
+		},
+		{
+			ID:            2,
+			AggregateType: db.AggregateAccount,
+			AggregateID:   "7",
+			EventType:     db.EventAccountCreated,
+			Version:       1,
+			Payload:       json.RawMessage(`{"id":7}`),
+		},
+	}
+}
+
+func TestStdoutPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewStdoutPublisher(&buf)
+
+	events := testEvents()
+	for _, event := range events {
+		require.NoError(t, publisher.Publish(context.Background(), event))
+	}
+
+	decoder := json.NewDecoder(&buf)
+	for _, event := range events {
+		var got db.Outbox
+		require.NoError(t, decoder.Decode(&got))
+		require.Equal(t, event.ID, got.ID)
+		require.Equal(t, event.EventType, got.EventType)
+		require.Equal(t, event.Version, got.Version)
+		require.JSONEq(t, string(event.Payload), string(got.Payload))
+	}
+	require.False(t, decoder.More())
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	publisher := NewMemoryPublisher()
+	require.Empty(t, publisher.Events())
+
+	events := testEvents()
+	for _, event := range events {
+		require.NoError(t, publisher.Publish(context.Background(), event))
+	}
+
+	got := publisher.Events()
+	require.Equal(t, events, got)
+
+	got[0].ID = 100
+	require.Equal(t, int64(1), publisher.Events()[0].ID)
+}
@@ -4,7 +4,8 @@ CREATE TABLE "users" (
   "full_name" varchar NOT NULL,
   "hashed_password" varchar NOT NULL,
   "password_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
-  "created_at" timestamptz NOT NULL DEFAULT (now())
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "role" varchar NOT NULL DEFAULT 'depositor'
 );
 
 CREATE TABLE "accounts" (
@@ -12,14 +13,21 @@ CREATE TABLE "accounts" (
   "owner" varchar NOT NULL,
   "balance" bigint NOT NULL,
   "currency" varchar NOT NULL,
-  "created_at" timestamptz NOT NULL DEFAULT (now())
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "type" varchar NOT NULL DEFAULT 'checking',
+  "nickname" varchar NOT NULL DEFAULT '',
+  "account_number" varchar UNIQUE NOT NULL,
+  "approval_threshold" bigint NOT NULL DEFAULT 0
 );
 
 CREATE TABLE "entries" (
   "id" bigserial PRIMARY KEY,
   "amount" bigint NOT NULL,
   "account_id" bigint NOT NULL,
-  "created_at" timestamptz NOT NULL DEFAULT (now())
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "description" varchar NOT NULL DEFAULT '',
+  "journal_id" bigint,
+  "transfer_id" bigint
 );
 
 CREATE TABLE "transfers" (
@@ -27,25 +35,450 @@ CREATE TABLE "transfers" (
   "amount" bigint NOT NULL,
   "from_account_id" bigint NOT NULL,
   "to_account_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "to_amount" bigint NOT NULL,
+  "fx_rate" bigint NOT NULL DEFAULT 100000000,
+  "spread_bps" bigint NOT NULL DEFAULT 0,
+  "kind" varchar NOT NULL DEFAULT 'transfer',
+  "original_transfer_id" bigint,
+  "description" varchar NOT NULL DEFAULT '',
+  "external_reference" varchar NOT NULL DEFAULT '',
+  "metadata" jsonb,
+  "status" varchar NOT NULL DEFAULT 'completed',
+  "processing_at" timestamptz,
+  "completed_at" timestamptz,
+  "failed_at" timestamptz,
+  "reversed_at" timestamptz,
+  "failure_reason" varchar NOT NULL DEFAULT ''
+);
+
+CREATE TABLE "account_members" (
+  "account_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "permission" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "username")
+);
+
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "reference" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "expires_at" timestamptz NOT NULL,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "idempotency_keys" (
+  "username" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "response" jsonb NOT NULL,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "status_code" integer NOT NULL DEFAULT 200,
+  "approval_id" bigint,
+  PRIMARY KEY ("username", "key")
+);
+
+CREATE TABLE "fx_rates" (
+  "id" bigserial PRIMARY KEY,
+  "base_currency" varchar NOT NULL,
+  "quote_currency" varchar NOT NULL,
+  "rate" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "execute_at" timestamptz NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" integer NOT NULL DEFAULT 0,
+  "next_attempt_at" timestamptz NOT NULL,
+  "failure_reason" varchar NOT NULL DEFAULT '',
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "standing_order_id" bigint,
+  "description" varchar NOT NULL DEFAULT '',
+  "external_reference" varchar NOT NULL DEFAULT '',
+  "metadata" jsonb
+);
+
+CREATE TABLE "standing_orders" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "schedule" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "next_run_at" timestamptz NOT NULL,
+  "end_at" timestamptz,
+  "max_runs" integer,
+  "runs" integer NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "description" varchar NOT NULL DEFAULT '',
+  "external_reference" varchar NOT NULL DEFAULT '',
+  "metadata" jsonb
+);
+
+CREATE TABLE "transfer_batches" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "allow_partial" boolean NOT NULL DEFAULT false,
+  "status" varchar NOT NULL DEFAULT 'processing',
+  "succeeded" integer NOT NULL DEFAULT 0,
+  "failed" integer NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_batch_items" (
+  "id" bigserial PRIMARY KEY,
+  "batch_id" bigint NOT NULL,
+  "row_number" integer NOT NULL,
+  "to_account" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "memo" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL,
+  "error" varchar NOT NULL DEFAULT '',
+  "transfer_id" bigint
+);
+
+CREATE TABLE "aliases" (
+  "alias" varchar PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "username" varchar NOT NULL,
+  "verified_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "payees" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "nickname" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "cooling_off_until" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_approvals" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "external_reference" varchar NOT NULL DEFAULT '',
+  "metadata" jsonb,
+  "initiator" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "decided_by" varchar NOT NULL DEFAULT '',
+  "decided_at" timestamptz,
+  "note" varchar NOT NULL DEFAULT '',
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "reason" varchar NOT NULL DEFAULT 'threshold',
+  "payment_request_id" bigint
+);
+
+CREATE TABLE "fee_schedules" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "min_amount" bigint NOT NULL DEFAULT 0,
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "percentage_bps" bigint NOT NULL DEFAULT 0,
+  "fee_account_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "fraud_decisions" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "outcome" varchar NOT NULL,
+  "rule" varchar NOT NULL DEFAULT '',
+  "reason" varchar NOT NULL DEFAULT '',
+  "approval_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "journals" (
+  "id" bigserial PRIMARY KEY,
+  "description" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "payment_requests" (
+  "id" bigserial PRIMARY KEY,
+  "requester" varchar NOT NULL,
+  "payer" varchar NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "expires_at" timestamptz NOT NULL,
+  "transfer_id" bigint,
+  "decided_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "escrow_accounts" (
+  "currency" varchar PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "escrows" (
+  "id" bigserial PRIMARY KEY,
+  "buyer_account_id" bigint NOT NULL,
+  "seller_account_id" bigint NOT NULL,
+  "escrow_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "created_by" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'held',
+  "timeout_at" timestamptz NOT NULL,
+  "timeout_action" varchar NOT NULL,
+  "fund_transfer_id" bigint,
+  "settle_transfer_id" bigint,
+  "settled_by" varchar NOT NULL DEFAULT '',
+  "settled_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "version" integer NOT NULL,
+  "payload" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "outbox_relays" (
+  "name" varchar PRIMARY KEY,
+  "position" bigint NOT NULL DEFAULT 0,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "approval_threshold_changes" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "approval_threshold" bigint NOT NULL,
+  "requested_by" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "decided_by" varchar NOT NULL DEFAULT '',
+  "decided_at" timestamptz,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
 CREATE INDEX ON "accounts" ("owner");
 
-CREATE UNIQUE INDEX ON "accounts" ("owner", "currency");
+CREATE INDEX ON "accounts" ("owner", "type");
+
+CREATE INDEX ON "accounts" ("owner", "currency");
 
 CREATE INDEX ON "entries" ("account_id");
 
+CREATE INDEX ON "entries" ("journal_id");
+
+CREATE INDEX ON "entries" ("transfer_id");
+
 CREATE INDEX ON "transfers" ("from_account_id");
 
 CREATE INDEX ON "transfers" ("to_account_id");
 
 CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
 
+CREATE INDEX ON "transfers" ("original_transfer_id");
+
+CREATE INDEX ON "transfers" ("external_reference");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+CREATE INDEX ON "transfers" ("status", "id");
+
+CREATE INDEX ON "account_members" ("username");
+
+CREATE INDEX ON "holds" ("account_id", "status");
+
+CREATE INDEX ON "fx_rates" ("base_currency", "quote_currency", "created_at");
+
+CREATE INDEX ON "scheduled_transfers" ("username");
+
+CREATE INDEX ON "scheduled_transfers" ("status", "next_attempt_at");
+
+CREATE INDEX ON "scheduled_transfers" ("standing_order_id");
+
+CREATE INDEX ON "standing_orders" ("username");
+
+CREATE INDEX ON "standing_orders" ("status", "next_run_at");
+
+CREATE INDEX ON "transfer_batches" ("username");
+
+CREATE UNIQUE INDEX ON "transfer_batch_items" ("batch_id", "row_number");
+
+CREATE INDEX ON "aliases" ("username");
+
+CREATE UNIQUE INDEX ON "payees" ("username", "account_id");
+
+CREATE INDEX ON "transfer_approvals" ("from_account_id", "status");
+
+CREATE UNIQUE INDEX ON "fee_schedules" ("currency", "min_amount");
+
+CREATE INDEX ON "fraud_decisions" ("from_account_id");
+
+CREATE INDEX ON "payment_requests" ("requester");
+
+CREATE INDEX ON "payment_requests" ("payer");
+
+CREATE INDEX ON "escrows" ("buyer_account_id");
+
+CREATE INDEX ON "escrows" ("seller_account_id");
+
+CREATE INDEX ON "escrows" ("status", "timeout_at");
+
+CREATE INDEX ON "approval_threshold_changes" ("account_id", "status");
+
+CREATE UNIQUE INDEX ON "transfer_approvals" ("payment_request_id") WHERE "status" = 'pending';
+
+COMMENT ON COLUMN "users"."role" IS 'depositor or banker';
+
+COMMENT ON COLUMN "accounts"."type" IS 'checking or savings';
+
+COMMENT ON COLUMN "accounts"."approval_threshold" IS 'transfers above it need a second approver, 0 disables approvals';
+
 COMMENT ON COLUMN "entries"."amount" IS 'can be neg or pos number';
 
+COMMENT ON COLUMN "entries"."journal_id" IS 'the posting or transfer fee the entry belongs to, empty for transfer entries';
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'the transfer the entry is a leg of, empty for posting and fee entries';
+
 COMMENT ON COLUMN "transfers"."amount" IS 'it must be pos num';
 
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited in the destination currency';
+
+COMMENT ON COLUMN "transfers"."fx_rate" IS 'scaled by 1e8, 1e8 for same-currency transfers';
+
+COMMENT ON COLUMN "transfers"."spread_bps" IS 'spread applied to the rate in basis points';
+
+COMMENT ON COLUMN "transfers"."kind" IS 'transfer, reversal or refund';
+
+COMMENT ON COLUMN "transfers"."original_transfer_id" IS 'the transfer a reversal or refund compensates';
+
+COMMENT ON COLUMN "transfers"."external_reference" IS 'caller supplied reference, e.g. an invoice number';
+
+COMMENT ON COLUMN "transfers"."metadata" IS 'caller supplied JSON object';
+
+COMMENT ON COLUMN "transfers"."status" IS 'pending, processing, completed, failed or reversed';
+
+COMMENT ON COLUMN "transfers"."failure_reason" IS 'why an asynchronous transfer failed';
+
+COMMENT ON COLUMN "account_members"."permission" IS 'view, transact or manage';
+
+COMMENT ON COLUMN "holds"."amount" IS 'it must be pos num';
+
+COMMENT ON COLUMN "holds"."status" IS 'active, captured or released';
+
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'sha256 of the request body';
+
+COMMENT ON COLUMN "idempotency_keys"."response" IS 'serialized transfer result';
+
+COMMENT ON COLUMN "idempotency_keys"."status_code" IS 'HTTP status of the stored response, 202 for async transfers';
+
+COMMENT ON COLUMN "idempotency_keys"."approval_id" IS 'set instead of transfer_id when the transfer was parked for approval';
+
+COMMENT ON COLUMN "fx_rates"."rate" IS 'quote units per base unit, scaled by 1e8';
+
+COMMENT ON COLUMN "scheduled_transfers"."amount" IS 'it must be pos num';
+
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'pending, completed, failed or cancelled';
+
+COMMENT ON COLUMN "standing_orders"."amount" IS 'it must be pos num';
+
+COMMENT ON COLUMN "standing_orders"."schedule" IS 'e.g. weekly:mon, monthly:15 or monthly:last-business-day';
+
+COMMENT ON COLUMN "standing_orders"."status" IS 'active, paused or completed';
+
+COMMENT ON COLUMN "transfer_batches"."status" IS 'processing, completed, partial or failed';
+
+COMMENT ON COLUMN "transfer_batch_items"."to_account" IS 'account number as submitted';
+
+COMMENT ON COLUMN "transfer_batch_items"."status" IS 'succeeded or failed';
+
+COMMENT ON COLUMN "aliases"."alias" IS 'lower-cased email or E.164 phone number';
+
+COMMENT ON COLUMN "aliases"."kind" IS 'email or phone';
+
+COMMENT ON COLUMN "aliases"."verified_at" IS 'unverified aliases do not resolve';
+
+COMMENT ON COLUMN "payees"."cooling_off_until" IS 'transfers to the payee are capped at a lower limit until then';
+
+COMMENT ON COLUMN "transfer_approvals"."amount" IS 'it must be pos num';
+
+COMMENT ON COLUMN "transfer_approvals"."status" IS 'pending, approved or rejected';
+
+COMMENT ON COLUMN "transfer_approvals"."decided_by" IS 'the approver who approved or rejected the transfer';
+
+COMMENT ON COLUMN "transfer_approvals"."reason" IS 'threshold, or fraud_review when a fraud rule held the transfer';
+
+COMMENT ON COLUMN "fee_schedules"."min_amount" IS 'the tier covers amounts from here up to the next tier';
+
+COMMENT ON COLUMN "fee_schedules"."percentage_bps" IS 'charged on top of the flat fee, in basis points of the amount';
+
+COMMENT ON COLUMN "fee_schedules"."fee_account_id" IS 'system account credited with the fee, in the same currency';
+
+COMMENT ON COLUMN "fraud_decisions"."outcome" IS 'allow, block or hold';
+
+COMMENT ON COLUMN "fraud_decisions"."rule" IS 'the rule that fired, empty when the transfer was allowed';
+
+COMMENT ON COLUMN "fraud_decisions"."approval_id" IS 'the approval a held transfer waits on';
+
+COMMENT ON COLUMN "payment_requests"."to_account_id" IS 'the requester''s account the money is paid into';
+
+COMMENT ON COLUMN "payment_requests"."status" IS 'pending, paid, declined or cancelled';
+
+COMMENT ON COLUMN "payment_requests"."transfer_id" IS 'the transfer that paid the request';
+
+COMMENT ON COLUMN "escrow_accounts"."account_id" IS 'system account that holds escrowed funds in the currency';
+
+COMMENT ON COLUMN "escrows"."status" IS 'held, released or refunded';
+
+COMMENT ON COLUMN "escrows"."timeout_action" IS 'release or refund, applied when the escrow is still held at timeout_at';
+
+COMMENT ON COLUMN "escrows"."fund_transfer_id" IS 'the transfer from the buyer into the escrow account';
+
+COMMENT ON COLUMN "escrows"."settle_transfer_id" IS 'the transfer out of the escrow account to the seller or back to the buyer';
+
+COMMENT ON COLUMN "escrows"."settled_by" IS 'who released or refunded the escrow, empty when it timed out';
+
+COMMENT ON COLUMN "outbox"."id" IS 'publish order, writers hold an advisory lock so ids commit in order';
+
+COMMENT ON COLUMN "outbox"."aggregate_type" IS 'user, account or transfer';
+
+COMMENT ON COLUMN "outbox"."version" IS 'version of the payload schema of event_type';
+
+COMMENT ON COLUMN "outbox_relays"."position" IS 'id of the last event the relay published';
+
+COMMENT ON COLUMN "approval_threshold_changes"."approval_threshold" IS 'the threshold the account gets once the change is approved';
+
+COMMENT ON COLUMN "approval_threshold_changes"."status" IS 'pending, approved or rejected';
+
+COMMENT ON COLUMN "transfer_approvals"."payment_request_id" IS 'the payment request the transfer pays, marked paid when it is approved';
+
 ALTER TABLE "accounts" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
 
 ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
@@ -53,3 +486,99 @@ ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id"
 ALTER TABLE "transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
 
 ALTER TABLE "transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("original_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("standing_order_id") REFERENCES "standing_orders" ("id");
+
+ALTER TABLE "standing_orders" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "standing_orders" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "standing_orders" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_batches" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_batches" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("batch_id") REFERENCES "transfer_batches" ("id");
+
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "aliases" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("initiator") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "fee_schedules" ADD FOREIGN KEY ("fee_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "fraud_decisions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "fraud_decisions" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "fraud_decisions" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "fraud_decisions" ADD FOREIGN KEY ("approval_id") REFERENCES "transfer_approvals" ("id");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("requester") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("payer") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "escrow_accounts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("buyer_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("seller_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("escrow_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("fund_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("settle_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "approval_threshold_changes" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "approval_threshold_changes" ADD FOREIGN KEY ("requested_by") REFERENCES "users" ("username");
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("approval_id") REFERENCES "transfer_approvals" ("id");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("payment_request_id") REFERENCES "payment_requests" ("id");
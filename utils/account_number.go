@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// Account numbers follow the IBAN layout: a two letter country code, two
+// ISO 7064 mod 97-10 check digits and a twelve digit basic account number,
+// for example SB21000123456789.
+const (
+	AccountNumberCountry = "SB"
+	accountNumberBBANLen = 12
+	accountNumberLen     = len(AccountNumberCountry) + 2 + accountNumberBBANLen
+)
+
+// NewAccountNumber returns a random account number with valid check digits.
+func NewAccountNumber() (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("error generating account number: %v", err)
+	}
+
+	bban := fmt.Sprintf("%012d", binary.BigEndian.Uint64(b[:])%1_000_000_000_000)
+	check := 98 - mod97(bban+AccountNumberCountry+"00")
+	return fmt.Sprintf("%s%02d%s", AccountNumberCountry, check, bban), nil
+}
+
+// NormalizeAccountNumber drops the spaces used to group account numbers
+// for display and upper-cases the country code.
+func NormalizeAccountNumber(number string) string {
+	return strings.ToUpper(strings.ReplaceAll(number, " ", ""))
+}
+
+// IsValidAccountNumber checks the layout and the mod 97 check digits.
+func IsValidAccountNumber(number string) bool {
+	number = NormalizeAccountNumber(number)
+	if len(number) != accountNumberLen || !strings.HasPrefix(number, AccountNumberCountry) {
+		return false
+	}
+
+	for _, c := range number[2:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return mod97(number[4:]+number[:4]) == 1
+}
+
+// mod97 computes the ISO 7064 remainder, reading letters as 10 to 35.
+func mod97(s string) int {
+	remainder := 0
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		}
+	}
+	return remainder
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAccountNumber(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		number, err := NewAccountNumber()
+		require.NoError(t, err)
+		require.Len(t, number, 16)
+		require.True(t, IsValidAccountNumber(number), number)
+	}
+}
+
+func TestMod97(t *testing.T) {
+	// the IBAN registry example GB82 WEST 1234 5698 7654 32, rearranged
+	require.Equal(t, 1, mod97("WEST12345698765432GB82"))
+}
+
+func TestIsValidAccountNumber(t *testing.T) {
+	number, err := NewAccountNumber()
+	require.NoError(t, err)
+
+	// grouped for display
+	require.True(t, IsValidAccountNumber(number[:4]+" "+number[4:8]+" "+number[8:12]+" "+number[12:]))
+
+	// a single mistyped digit is always caught
+	last := number[len(number)-1]
+	typo := byte('0' + (last-'0'+1)%10)
+	require.False(t, IsValidAccountNumber(number[:len(number)-1]+string(typo)))
+
+	// so is a swap of two adjacent different digits
+	swapped := []byte(number)
+	for i := 4; i < len(swapped)-1; i++ {
+		if swapped[i] != swapped[i+1] {
+			swapped[i], swapped[i+1] = swapped[i+1], swapped[i]
+			break
+		}
+	}
+	require.False(t, IsValidAccountNumber(string(swapped)))
+
+	require.True(t, IsValidAccountNumber("SB21000123456789"))
+	require.False(t, IsValidAccountNumber(""))
+	require.False(t, IsValidAccountNumber("GB82WEST12345698765432"))
+	require.False(t, IsValidAccountNumber("SB00ABCDEFGHIJKL"))
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+)
+
+// Alias kinds. A recipient can be addressed by their username or by an
+// email or phone alias they have claimed instead of an account ID or number.
+const (
+	AliasUsername = "username"
+	AliasEmail    = "email"
+	AliasPhone    = "phone"
+)
+
+// NormalizeAlias works out what kind of alias s is and returns it in the
+// form it is stored under: emails are lower-cased, phone numbers are
+// reduced to E.164 (a leading + and 8 to 15 digits) after dropping spaces,
+// dashes, dots and parentheses, and anything else must be a username.
+func NormalizeAlias(s string) (alias string, kind string, err error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.Contains(s, "@"):
+		addr, err := mail.ParseAddress(s)
+		if err != nil || addr.Address != s {
+			return "", "", fmt.Errorf("invalid email alias %q", s)
+		}
+		return strings.ToLower(s), AliasEmail, nil
+	case strings.HasPrefix(s, "+"):
+		digits := strings.Map(func(r rune) rune {
+			switch r {
+			case ' ', '-', '.', '(', ')':
+				return -1
+			}
+			return r
+		}, s[1:])
+		if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' || !isDigits(digits) {
+			return "", "", fmt.Errorf("invalid phone alias %q", s)
+		}
+		return "+" + digits, AliasPhone, nil
+	case s != "" && isAlphanumeric(s):
+		return s, AliasUsername, nil
+	}
+	return "", "", fmt.Errorf("invalid alias %q", s)
+}
+
+// MaskName keeps the first letter of each word of a name, enough for a
+// sender to recognise the recipient without disclosing it: "Jane Doe"
+// becomes "J*** D**".
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		runes := []rune(word)
+		words[i] = string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+	return strings.Join(words, " ")
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAlias(t *testing.T) {
+	testCases := []struct {
+		input string
+		alias string
+		kind  string
+	}{
+		{"alice42", "alice42", AliasUsername},
+		{"Alice@Example.com", "alice@example.com", AliasEmail},
+		{" +44 (20) 7946-0958 ", "+442079460958", AliasPhone},
+		{"+1.415.555.0100", "+14155550100", AliasPhone},
+	}
+
+	for _, tc := range testCases {
+		alias, kind, err := NormalizeAlias(tc.input)
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.alias, alias)
+		require.Equal(t, tc.kind, kind)
+	}
+
+	for _, input := range []string{
+		"",
+		"alice smith",
+		"Alice <alice@example.com>",
+		"alice@",
+		"+0123456789",
+		"+1234",
+		"+1234567890123456",
+		"+4420x79460958",
+		"ünïcode",
+	} {
+		_, _, err := NormalizeAlias(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestMaskName(t *testing.T) {
+	require.Equal(t, "J*** D**", MaskName("Jane Doe"))
+	require.Equal(t, "Z** Ö********", MaskName("  Zoë  Österberg "))
+	require.Equal(t, "", MaskName(""))
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/spf13/viper"
@@ -15,6 +16,27 @@ type Config struct {
 	ServerAddress          string        `mapstructure:"SERVER_ADDRESS"`
 	AccessTokenDuration    time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	SymmetricEncryptionKey string        `mapstructure:"SYMMETRIC_ENCRYPTION_KEY"`
+	MaxCheckingAccounts    int64         `mapstructure:"MAX_CHECKING_ACCOUNTS"`
+	MaxSavingsAccounts     int64         `mapstructure:"MAX_SAVINGS_ACCOUNTS"`
+	EnabledCurrencies      []string      `mapstructure:"ENABLED_CURRENCIES"`
+	FXRateProvider         string        `mapstructure:"FX_RATE_PROVIDER"`
+	FXRatesFile            string        `mapstructure:"FX_RATES_FILE"`
+	FXSpreadBps            int64         `mapstructure:"FX_SPREAD_BPS"`
+	SchedulerInterval      time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	ScheduledMaxAttempts   int32         `mapstructure:"SCHEDULED_MAX_ATTEMPTS"`
+	ScheduledRetryDelay    time.Duration `mapstructure:"SCHEDULED_RETRY_DELAY"`
+	PayeeCoolingOff        time.Duration `mapstructure:"PAYEE_COOLING_OFF"`
+	PayeeCoolingOffLimit   int64         `mapstructure:"PAYEE_COOLING_OFF_LIMIT"`
+	FraudRulesFile         string        `mapstructure:"FRAUD_RULES_FILE"`
+	PaymentRequestExpiry   time.Duration `mapstructure:"PAYMENT_REQUEST_EXPIRY"`
+	EscrowTimeout          time.Duration `mapstructure:"ESCROW_TIMEOUT"`
+	TransferWorkers        int           `mapstructure:"TRANSFER_WORKERS"`
+	TransferPollInterval   time.Duration `mapstructure:"TRANSFER_POLL_INTERVAL"`
+	TransferStaleAfter     time.Duration `mapstructure:"TRANSFER_STALE_AFTER"`
+	OutboxPublisher        string        `mapstructure:"OUTBOX_PUBLISHER"`
+	OutboxRelayName        string        `mapstructure:"OUTBOX_RELAY_NAME"`
+	OutboxPollInterval     time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL"`
+	OutboxBatchSize        int32         `mapstructure:"OUTBOX_BATCH_SIZE"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -30,5 +52,36 @@ func LoadConfig(path string) (config Config, err error) {
 	}
 
 	err = viper.Unmarshal(&config)
+	if err != nil {
+		return
+	}
+
+	err = config.validate()
 	return
 }
+
+// validate rejects settings the server cannot start with, such as a
+// missing poll interval, which would make the workers' tickers panic.
+func (config Config) validate() error {
+	intervals := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"SCHEDULER_INTERVAL", config.SchedulerInterval},
+		{"TRANSFER_POLL_INTERVAL", config.TransferPollInterval},
+		{"OUTBOX_POLL_INTERVAL", config.OutboxPollInterval},
+	}
+	for _, interval := range intervals {
+		if interval.value <= 0 {
+			return fmt.Errorf("%s must be a positive duration, got %s", interval.name, interval.value)
+		}
+	}
+
+	if config.TransferWorkers <= 0 {
+		return fmt.Errorf("TRANSFER_WORKERS must be positive, got %d", config.TransferWorkers)
+	}
+	if config.OutboxBatchSize <= 0 {
+		return fmt.Errorf("OUTBOX_BATCH_SIZE must be positive, got %d", config.OutboxBatchSize)
+	}
+	return nil
+}
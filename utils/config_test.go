@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{
+		SchedulerInterval:    30 * time.Second,
+		TransferPollInterval: time.Second,
+		OutboxPollInterval:   time.Second,
+		TransferWorkers:      4,
+		OutboxBatchSize:      100,
+	}
+	require.NoError(t, valid.validate())
+
+	testCases := []struct {
+		name   string
+		modify func(config *Config)
+	}{
+		{"MissingSchedulerInterval", func(config *Config) { config.SchedulerInterval = 0 }},
+		{"MissingTransferPollInterval", func(config *Config) { config.TransferPollInterval = 0 }},
+		{"NegativeOutboxPollInterval", func(config *Config) { config.OutboxPollInterval = -time.Second }},
+		{"NoTransferWorkers", func(config *Config) { config.TransferWorkers = 0 }},
+		{"NoOutboxBatchSize", func(config *Config) { config.OutboxBatchSize = 0 }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := valid
+			tc.modify(&config)
+			require.Error(t, config.validate())
+		})
+	}
+}
@@ -1,15 +1,132 @@
 package utils
 
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
 const (
 	USD = "USD"
 	EUR = "EUR"
 	CAD = "CAD"
 )
 
-func IsSupportedCurrency(currency string) bool {
-	switch currency {
-	case USD, EUR, CAD:
-		return true
+// Currency describes an ISO 4217 currency. Amounts stored in accounts,
+// entries and transfers are integers in the currency's minor unit, so an
+// amount of 1050 is 10.50 USD but 1050 JPY.
+type Currency struct {
+	Code        string `json:"code"`
+	NumericCode string `json:"numeric_code"`
+	MinorUnits  int    `json:"minor_units"`
+	Symbol      string `json:"symbol"`
+}
+
+// FormatAmount renders an amount given in minor units as a decimal string,
+// e.g. 1050 USD becomes "10.50".
+func (c Currency) FormatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	if c.MinorUnits == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= c.MinorUnits {
+		digits = strings.Repeat("0", c.MinorUnits-len(digits)+1) + digits
+	}
+	split := len(digits) - c.MinorUnits
+	return sign + digits[:split] + "." + digits[split:]
+}
+
+//go:embed currencies.csv
+var currencyTable string
+
+var isoCurrencies = mustParseCurrencies(currencyTable)
+
+func mustParseCurrencies(table string) map[string]Currency {
+	records, err := csv.NewReader(strings.NewReader(table)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("cannot parse currency table: %v", err))
+	}
+
+	currencies := make(map[string]Currency, len(records))
+	for _, record := range records[1:] {
+		minorUnits, err := strconv.Atoi(record[2])
+		if err != nil {
+			panic(fmt.Sprintf("invalid minor units for %s: %v", record[0], err))
+		}
+		currencies[record[0]] = Currency{
+			Code:        record[0],
+			NumericCode: record[1],
+			MinorUnits:  minorUnits,
+			Symbol:      record[3],
+		}
+	}
+	return currencies
+}
+
+// LookupCurrency returns the ISO 4217 definition of a currency code,
+// whether or not it is enabled.
+func LookupCurrency(code string) (Currency, bool) {
+	currency, ok := isoCurrencies[code]
+	return currency, ok
+}
+
+// CurrencyRegistry holds the currencies the bank currently accepts.
+type CurrencyRegistry struct {
+	enabled map[string]Currency
+}
+
+// NewCurrencyRegistry enables the given ISO 4217 codes. Unknown codes are
+// rejected so a typo in the configuration fails at startup.
+func NewCurrencyRegistry(codes []string) (*CurrencyRegistry, error) {
+	registry := &CurrencyRegistry{enabled: make(map[string]Currency, len(codes))}
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		currency, ok := LookupCurrency(code)
+		if !ok {
+			return nil, fmt.Errorf("unknown currency code %q", code)
+		}
+		registry.enabled[code] = currency
+	}
+
+	if len(registry.enabled) == 0 {
+		return nil, fmt.Errorf("no currencies enabled")
+	}
+	return registry, nil
+}
+
+// Get returns an enabled currency.
+func (registry *CurrencyRegistry) Get(code string) (Currency, bool) {
+	currency, ok := registry.enabled[code]
+	return currency, ok
+}
+
+// IsEnabled reports whether code can be used for new accounts and transfers.
+func (registry *CurrencyRegistry) IsEnabled(code string) bool {
+	_, ok := registry.enabled[code]
+	return ok
+}
+
+// List returns the enabled currencies ordered by code.
+func (registry *CurrencyRegistry) List() []Currency {
+	currencies := make([]Currency, 0, len(registry.enabled))
+	for _, currency := range registry.enabled {
+		currencies = append(currencies, currency)
 	}
-	return false
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].Code < currencies[j].Code
+	})
+	return currencies
 }
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupCurrency(t *testing.T) {
+	usd, ok := LookupCurrency(USD)
+	require.True(t, ok)
+	require.Equal(t, Currency{Code: USD, NumericCode: "840", MinorUnits: 2, Symbol: "$"}, usd)
+
+	jpy, ok := LookupCurrency("JPY")
+	require.True(t, ok)
+	require.Equal(t, 0, jpy.MinorUnits)
+
+	kwd, ok := LookupCurrency("KWD")
+	require.True(t, ok)
+	require.Equal(t, 3, kwd.MinorUnits)
+
+	_, ok = LookupCurrency("XXX")
+	require.False(t, ok)
+}
+
+func TestCurrencyFormatAmount(t *testing.T) {
+	usd, _ := LookupCurrency(USD)
+	jpy, _ := LookupCurrency("JPY")
+	kwd, _ := LookupCurrency("KWD")
+
+	require.Equal(t, "10.50", usd.FormatAmount(1050))
+	require.Equal(t, "0.05", usd.FormatAmount(5))
+	require.Equal(t, "0.00", usd.FormatAmount(0))
+	require.Equal(t, "-1.00", usd.FormatAmount(-100))
+	require.Equal(t, "1050", jpy.FormatAmount(1050))
+	require.Equal(t, "1.050", kwd.FormatAmount(1050))
+}
+
+func TestNewCurrencyRegistry(t *testing.T) {
+	registry, err := NewCurrencyRegistry([]string{"usd", " EUR", "JPY", ""})
+	require.NoError(t, err)
+
+	require.True(t, registry.IsEnabled(USD))
+	require.True(t, registry.IsEnabled(EUR))
+	require.False(t, registry.IsEnabled(CAD))
+
+	list := registry.List()
+	require.Len(t, list, 3)
+	require.Equal(t, EUR, list[0].Code)
+	require.Equal(t, "JPY", list[1].Code)
+	require.Equal(t, USD, list[2].Code)
+
+	_, err = NewCurrencyRegistry([]string{USD, "ABC"})
+	require.Error(t, err)
+
+	_, err = NewCurrencyRegistry(nil)
+	require.Error(t, err)
+}
+
+func TestRandomCurrencyIsKnown(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		_, ok := LookupCurrency(RandomCurrency())
+		require.True(t, ok)
+	}
+}
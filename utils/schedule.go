@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a recurrence for standing orders. Expressions are one of
+//
+//	weekly:<mon|tue|wed|thu|fri|sat|sun>
+//	monthly:<1-31>
+//	monthly:last-business-day
+//
+// Runs fall at midnight UTC. A monthly day past the end of a shorter month
+// runs on that month's last day, and the last business day is the last
+// weekday of the month.
+type Schedule struct {
+	expr    string
+	weekly  bool
+	weekday time.Weekday
+	// day of the month; zero means the last business day
+	day int
+}
+
+const lastBusinessDay = "last-business-day"
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func ParseSchedule(expr string) (Schedule, error) {
+	expr = strings.ToLower(strings.TrimSpace(expr))
+	kind, arg, ok := strings.Cut(expr, ":")
+	if !ok {
+		return Schedule{}, fmt.Errorf("invalid schedule %q", expr)
+	}
+
+	switch kind {
+	case "weekly":
+		weekday, ok := weekdays[arg]
+		if !ok {
+			return Schedule{}, fmt.Errorf("invalid weekday %q in schedule", arg)
+		}
+		return Schedule{expr: expr, weekly: true, weekday: weekday}, nil
+	case "monthly":
+		if arg == lastBusinessDay {
+			return Schedule{expr: expr}, nil
+		}
+		day, err := strconv.Atoi(arg)
+		if err != nil || day < 1 || day > 31 {
+			return Schedule{}, fmt.Errorf("invalid day of month %q in schedule", arg)
+		}
+		return Schedule{expr: expr, day: day}, nil
+	}
+
+	return Schedule{}, fmt.Errorf("invalid schedule %q", expr)
+}
+
+// IsValidSchedule reports whether expr parses.
+func IsValidSchedule(expr string) bool {
+	_, err := ParseSchedule(expr)
+	return err == nil
+}
+
+func (schedule Schedule) String() string {
+	return schedule.expr
+}
+
+// Next returns the first run strictly after t.
+func (schedule Schedule) Next(t time.Time) time.Time {
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	if schedule.weekly {
+		next := midnight.AddDate(0, 0, (int(schedule.weekday)-int(midnight.Weekday())+7)%7)
+		if !next.After(t) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+
+	for month := 0; ; month++ {
+		first := time.Date(t.Year(), t.Month()+time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		next := schedule.dayIn(first)
+		if next.After(t) {
+			return next
+		}
+	}
+}
+
+// dayIn returns the run in the month starting at first.
+func (schedule Schedule) dayIn(first time.Time) time.Time {
+	last := first.AddDate(0, 1, -1)
+	if schedule.day == 0 {
+		for last.Weekday() == time.Saturday || last.Weekday() == time.Sunday {
+			last = last.AddDate(0, 0, -1)
+		}
+		return last
+	}
+
+	if schedule.day > last.Day() {
+		return last
+	}
+	return first.AddDate(0, 0, schedule.day-1)
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseSchedule(t *testing.T) {
+	for _, expr := range []string{"weekly:mon", "WEEKLY:Sun", "monthly:1", "monthly:31", "monthly:last-business-day"} {
+		_, err := ParseSchedule(expr)
+		require.NoError(t, err, expr)
+	}
+
+	for _, expr := range []string{"", "weekly", "weekly:monday", "monthly:0", "monthly:32", "monthly:first", "daily:1"} {
+		_, err := ParseSchedule(expr)
+		require.Error(t, err, expr)
+	}
+
+	schedule, err := ParseSchedule(" Weekly:FRI ")
+	require.NoError(t, err)
+	require.Equal(t, "weekly:fri", schedule.String())
+}
+
+func TestScheduleNext(t *testing.T) {
+	testCases := []struct {
+		expr  string
+		after time.Time
+		next  time.Time
+	}{
+		// 2026-10-19 is a Monday
+		{"weekly:mon", date(2026, 10, 19).Add(12 * time.Hour), date(2026, 10, 26)},
+		{"weekly:mon", date(2026, 10, 18), date(2026, 10, 19)},
+		{"weekly:mon", date(2026, 10, 19), date(2026, 10, 26)},
+		{"weekly:sun", date(2026, 10, 19), date(2026, 10, 25)},
+		{"monthly:1", date(2026, 10, 19), date(2026, 11, 1)},
+		{"monthly:1", date(2026, 12, 5), date(2027, 1, 1)},
+		{"monthly:25", date(2026, 10, 19), date(2026, 10, 25)},
+		{"monthly:31", date(2026, 10, 31), date(2026, 11, 30)},
+		{"monthly:31", date(2027, 1, 31), date(2027, 2, 28)},
+		{"monthly:30", date(2028, 1, 30), date(2028, 2, 29)},
+		// October 2026 ends on a Saturday
+		{"monthly:last-business-day", date(2026, 10, 1), date(2026, 10, 30)},
+		{"monthly:last-business-day", date(2026, 10, 30), date(2026, 11, 30)},
+		// January 2027 ends on a Sunday
+		{"monthly:last-business-day", date(2027, 1, 2), date(2027, 1, 29)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expr+" after "+tc.after.Format(time.RFC3339), func(t *testing.T) {
+			schedule, err := ParseSchedule(tc.expr)
+			require.NoError(t, err)
+			require.Equal(t, tc.next, schedule.Next(tc.after))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+// EscrowRunner settles escrows that are still held when they time out,
+// releasing or refunding each one as it was set up to.
+type EscrowRunner struct {
+	store    db.Store
+	interval time.Duration
+}
+
+func NewEscrowRunner(store db.Store, interval time.Duration) *EscrowRunner {
+	return &EscrowRunner{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Run polls for timed out escrows until ctx is cancelled.
+func (runner *EscrowRunner) Run(ctx context.Context) {
+	poll(ctx, runner.interval, "escrows", runner.RunDue)
+}
+
+// RunDue settles every timed out escrow and returns how many were settled.
+func (runner *EscrowRunner) RunDue(ctx context.Context) (int, error) {
+	for n := 0; ; n++ {
+		result, err := runner.store.SettleTimedOutEscrowTx(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return n, nil
+			}
+			return n, err
+		}
+
+		log.Printf("escrow %d timed out and was %s", result.Escrow.ID, result.Escrow.Status)
+	}
+}
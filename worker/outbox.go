@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/outbox"
+)
+
+// OutboxRelay publishes outbox events in id order. Its position, the id of
+// the last event it published, is stored under its name so several relays
+// can feed different publishers from the same outbox. The position is saved
+// after publishing, so a relay that stops in between publishes the same
+// events again when it restarts: delivery is at least once.
+type OutboxRelay struct {
+	store     db.Store
+	publisher outbox.Publisher
+	name      string
+	interval  time.Duration
+	batchSize int32
+}
+
+func NewOutboxRelay(store db.Store, publisher outbox.Publisher, name string, interval time.Duration, batchSize int32) *OutboxRelay {
+	return &OutboxRelay{
+		store:     store,
+		publisher: publisher,
+		name:      name,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run polls for new events until ctx is cancelled.
+func (relay *OutboxRelay) Run(ctx context.Context) {
+	poll(ctx, relay.interval, "outbox relay "+relay.name, relay.RunDue)
+}
+
+// RunDue publishes every event after the relay's position and returns how
+// many were published. When publishing fails the position is moved past
+// the events already published, and the failed one is retried next time.
+func (relay *OutboxRelay) RunDue(ctx context.Context) (int, error) {
+	position, err := relay.store.GetOutboxPosition(ctx, relay.name)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	n := 0
+	for {
+		events, err := relay.store.ListOutboxEvents(ctx, db.ListOutboxEventsParams{
+			AfterID: position,
+			Limit:   relay.batchSize,
+		})
+		if err != nil {
+			return n, err
+		}
+
+		published := position
+		for _, event := range events {
+			err = relay.publisher.Publish(ctx, event)
+			if err != nil {
+				break
+			}
+			published = event.ID
+			n++
+		}
+
+		if published > position {
+			saveErr := relay.store.SaveOutboxPosition(ctx, db.SaveOutboxPositionParams{
+				Name:     relay.name,
+				Position: published,
+			})
+			if saveErr != nil {
+				return n, saveErr
+			}
+			position = published
+		}
+
+		if err != nil {
+			return n, err
+		}
+
+		if len(events) < int(relay.batchSize) {
+			return n, nil
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+	"github.com/thanhphuocnguyen/go-simple-bank/outbox"
+)
+
+const testRelayName = "test"
+
+// failingPublisher fails on the event with id failOn and publishes the
+// rest to the embedded memory publisher.
+type failingPublisher struct {
+	*outbox.MemoryPublisher
+	failOn int64
+}
+
+func (publisher failingPublisher) Publish(ctx context.Context, event db.Outbox) error {
+	if event.ID == publisher.failOn {
+		return errors.New("broker unavailable")
+	}
+	return publisher.MemoryPublisher.Publish(ctx, event)
+}
+
+func outboxEvents(ids ...int64) []db.Outbox {
+	events := make([]db.Outbox, len(ids))
+	for i, id := range ids {
+		events[i] = db.Outbox{ID: id, EventType: db.EventTransferCompleted, Version: 1}
+	}
+	return events
+}
+
+func listAfter(after int64, limit int32) db.ListOutboxEventsParams {
+	return db.ListOutboxEventsParams{AfterID: after, Limit: limit}
+}
+
+func savePosition(position int64) db.SaveOutboxPositionParams {
+	return db.SaveOutboxPositionParams{Name: testRelayName, Position: position}
+}
+
+func TestRunDueOutbox(t *testing.T) {
+	testCases := []struct {
+		name       string
+		failOn     int64
+		buildStubs func(store *mockdb.MockStore)
+		published  []int64
+		hasErr     bool
+	}{
+		{
+			name: "PublishesInBatches",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().GetOutboxPosition(gomock.Any(), testRelayName).Return(int64(3), nil),
+					store.EXPECT().ListOutboxEvents(gomock.Any(), listAfter(3, 2)).Return(outboxEvents(4, 5), nil),
+					store.EXPECT().SaveOutboxPosition(gomock.Any(), savePosition(5)).Return(nil),
+					store.EXPECT().ListOutboxEvents(gomock.Any(), listAfter(5, 2)).Return(outboxEvents(7), nil),
+					store.EXPECT().SaveOutboxPosition(gomock.Any(), savePosition(7)).Return(nil),
+				)
+			},
+			published: []int64{4, 5, 7},
+		},
+		{
+			name: "NewRelayStartsAtBeginning",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().GetOutboxPosition(gomock.Any(), testRelayName).Return(int64(0), sql.ErrNoRows),
+					store.EXPECT().ListOutboxEvents(gomock.Any(), listAfter(0, 2)).Return(outboxEvents(1), nil),
+					store.EXPECT().SaveOutboxPosition(gomock.Any(), savePosition(1)).Return(nil),
+				)
+			},
+			published: []int64{1},
+		},
+		{
+			name: "NothingToPublish",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOutboxPosition(gomock.Any(), testRelayName).Return(int64(7), nil)
+				store.EXPECT().ListOutboxEvents(gomock.Any(), listAfter(7, 2)).Return([]db.Outbox{}, nil)
+				store.EXPECT().SaveOutboxPosition(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:   "PublishErrorKeepsProgress",
+			failOn: 5,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().GetOutboxPosition(gomock.Any(), testRelayName).Return(int64(3), nil),
+					store.EXPECT().ListOutboxEvents(gomock.Any(), listAfter(3, 2)).Return(outboxEvents(4, 5), nil),
+					store.EXPECT().SaveOutboxPosition(gomock.Any(), savePosition(4)).Return(nil),
+				)
+			},
+			published: []int64{4},
+			hasErr:    true,
+		},
+		{
+			name:   "PublishErrorOnFirstEvent",
+			failOn: 4,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOutboxPosition(gomock.Any(), testRelayName).Return(int64(3), nil)
+				store.EXPECT().ListOutboxEvents(gomock.Any(), listAfter(3, 2)).Return(outboxEvents(4, 5), nil)
+				store.EXPECT().SaveOutboxPosition(gomock.Any(), gomock.Any()).Times(0)
+			},
+			hasErr: true,
+		},
+		{
+			name: "StoreError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOutboxPosition(gomock.Any(), testRelayName).Return(int64(0), sql.ErrConnDone)
+				store.EXPECT().ListOutboxEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			hasErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			publisher := failingPublisher{MemoryPublisher: outbox.NewMemoryPublisher(), failOn: tc.failOn}
+			count, err := NewOutboxRelay(store, publisher, testRelayName, time.Minute, 2).RunDue(context.Background())
+			if tc.hasErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, len(tc.published), count)
+
+			var published []int64
+			for _, event := range publisher.Events() {
+				published = append(published, event.ID)
+			}
+			require.Equal(t, tc.published, published)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+// ScheduledTransferRunner periodically executes scheduled transfers that
+// have come due. Any number of runners may share a database; the store
+// makes sure each transfer is claimed by only one of them.
+type ScheduledTransferRunner struct {
+	store    db.Store
+	interval time.Duration
+	arg      db.ExecuteScheduledTransferTxParams
+}
+
+func NewScheduledTransferRunner(store db.Store, interval time.Duration, arg db.ExecuteScheduledTransferTxParams) *ScheduledTransferRunner {
+	return &ScheduledTransferRunner{
+		store:    store,
+		interval: interval,
+		arg:      arg,
+	}
+}
+
+// Run polls for due transfers until ctx is cancelled.
+func (runner *ScheduledTransferRunner) Run(ctx context.Context) {
+	poll(ctx, runner.interval, "scheduled transfers", runner.RunDue)
+}
+
+// RunDue executes scheduled transfers until none are due and returns how
+// many were attempted.
+func (runner *ScheduledTransferRunner) RunDue(ctx context.Context) (int, error) {
+	for n := 0; ; n++ {
+		scheduled, err := runner.store.ExecuteScheduledTransferTx(ctx, runner.arg)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return n, nil
+			}
+			return n, err
+		}
+
+		if scheduled.Status != db.ScheduledTransferStatusCompleted {
+			log.Printf("scheduled transfer %d attempt %d failed: %s", scheduled.ID, scheduled.Attempts, scheduled.FailureReason)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+func TestRunDueScheduledTransfers(t *testing.T) {
+	arg := db.ExecuteScheduledTransferTxParams{MaxAttempts: 3, RetryDelay: time.Minute}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		count      int
+		hasErr     bool
+	}{
+		{
+			name: "DrainsDueTransfers",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).
+						Return(db.ScheduledTransfer{ID: 1, Status: db.ScheduledTransferStatusCompleted}, nil),
+					store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).
+						Return(db.ScheduledTransfer{ID: 2, Status: db.ScheduledTransferStatusPending, Attempts: 1, FailureReason: db.ErrInsufficientFunds.Error()}, nil),
+					store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).
+						Return(db.ScheduledTransfer{}, sql.ErrNoRows),
+				)
+			},
+			count: 2,
+		},
+		{
+			name: "NothingDue",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ScheduledTransfer{}, sql.ErrNoRows)
+			},
+			count: 0,
+		},
+		{
+			name: "StoreError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ScheduledTransfer{}, sql.ErrConnDone)
+			},
+			count:  0,
+			hasErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			runner := NewScheduledTransferRunner(store, time.Minute, arg)
+			count, err := runner.RunDue(context.Background())
+			if tc.hasErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.count, count)
+		})
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).AnyTimes().Return(db.ScheduledTransfer{}, sql.ErrNoRows)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewScheduledTransferRunner(store, time.Millisecond, db.ExecuteScheduledTransferTxParams{}).Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("runner did not stop after the context was cancelled")
+	}
+}
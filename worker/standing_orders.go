@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+// StandingOrderRunner turns due standing orders into scheduled transfers,
+// which the ScheduledTransferRunner then executes.
+type StandingOrderRunner struct {
+	store    db.Store
+	interval time.Duration
+}
+
+func NewStandingOrderRunner(store db.Store, interval time.Duration) *StandingOrderRunner {
+	return &StandingOrderRunner{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Run polls for due standing orders until ctx is cancelled.
+func (runner *StandingOrderRunner) Run(ctx context.Context) {
+	poll(ctx, runner.interval, "standing orders", runner.RunDue)
+}
+
+// RunDue queues a run for every standing order that is due and returns how
+// many orders were processed.
+func (runner *StandingOrderRunner) RunDue(ctx context.Context) (int, error) {
+	for n := 0; ; n++ {
+		_, err := runner.store.RunStandingOrderTx(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return n, nil
+			}
+			return n, err
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+func TestRunDueStandingOrders(t *testing.T) {
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		count      int
+		hasErr     bool
+	}{
+		{
+			name: "DrainsDueOrders",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().RunStandingOrderTx(gomock.Any()).
+						Return(db.RunStandingOrderTxResult{StandingOrder: db.StandingOrder{ID: 1, Runs: 1}}, nil),
+					store.EXPECT().RunStandingOrderTx(gomock.Any()).
+						Return(db.RunStandingOrderTxResult{StandingOrder: db.StandingOrder{ID: 2, Status: db.StandingOrderStatusCompleted}}, nil),
+					store.EXPECT().RunStandingOrderTx(gomock.Any()).
+						Return(db.RunStandingOrderTxResult{}, sql.ErrNoRows),
+				)
+			},
+			count: 2,
+		},
+		{
+			name: "StoreError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RunStandingOrderTx(gomock.Any()).Times(1).Return(db.RunStandingOrderTxResult{}, sql.ErrConnDone)
+			},
+			count:  0,
+			hasErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			count, err := NewStandingOrderRunner(store, time.Minute).RunDue(context.Background())
+			if tc.hasErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.count, count)
+		})
+	}
+}
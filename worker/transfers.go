@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+// TransferProcessor executes transfers accepted asynchronously. A pool of
+// workers claims pending transfers one at a time, so transfers run in
+// parallel while each is executed by exactly one worker.
+type TransferProcessor struct {
+	store    db.Store
+	workers  int
+	interval time.Duration
+	// staleAfter is how long a transfer may stay processing before it is
+	// assumed its worker died and another one claims it.
+	staleAfter time.Duration
+}
+
+func NewTransferProcessor(store db.Store, workers int, interval, staleAfter time.Duration) *TransferProcessor {
+	return &TransferProcessor{
+		store:      store,
+		workers:    workers,
+		interval:   interval,
+		staleAfter: staleAfter,
+	}
+}
+
+// Run starts the workers and blocks until ctx is cancelled and all of them
+// have stopped.
+func (processor *TransferProcessor) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < processor.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			poll(ctx, processor.interval, "transfers", processor.RunDue)
+		}()
+	}
+	wg.Wait()
+}
+
+// RunDue executes pending transfers until none are left and returns how
+// many were executed.
+func (processor *TransferProcessor) RunDue(ctx context.Context) (int, error) {
+	for n := 0; ; {
+		staleBefore := pgtype.Timestamptz{Time: time.Now().Add(-processor.staleAfter), Valid: true}
+		claimed, err := processor.store.ClaimPendingTransfer(ctx, staleBefore)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return n, nil
+			}
+			return n, err
+		}
+
+		result, err := processor.store.ProcessTransferTx(ctx, claimed.ID)
+		if err != nil {
+			// a worker that stalled on it finished first
+			if errors.Is(err, db.ErrTransferNotProcessing) {
+				continue
+			}
+			return n, err
+		}
+		n++
+
+		if result.Transfer.Status == db.TransferStatusFailed {
+			log.Printf("transfer %d failed: %s", result.Transfer.ID, result.Transfer.FailureReason)
+		}
+	}
+}
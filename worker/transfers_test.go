@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/thanhphuocnguyen/go-simple-bank/db/mock"
+	db "github.com/thanhphuocnguyen/go-simple-bank/db/sqlc"
+)
+
+func TestRunDueTransfers(t *testing.T) {
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		count      int
+		hasErr     bool
+	}{
+		{
+			name: "DrainsPendingTransfers",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().ClaimPendingTransfer(gomock.Any(), gomock.Any()).
+						Return(db.Transfer{ID: 1, Status: db.TransferStatusProcessing}, nil),
+					store.EXPECT().ProcessTransferTx(gomock.Any(), gomock.Eq(int64(1))).
+						Return(db.TransferTxResult{Transfer: db.Transfer{ID: 1, Status: db.TransferStatusCompleted}}, nil),
+					store.EXPECT().ClaimPendingTransfer(gomock.Any(), gomock.Any()).
+						Return(db.Transfer{ID: 2, Status: db.TransferStatusProcessing}, nil),
+					store.EXPECT().ProcessTransferTx(gomock.Any(), gomock.Eq(int64(2))).
+						Return(db.TransferTxResult{Transfer: db.Transfer{ID: 2, Status: db.TransferStatusFailed, FailureReason: "insufficient funds"}}, nil),
+					store.EXPECT().ClaimPendingTransfer(gomock.Any(), gomock.Any()).
+						Return(db.Transfer{}, sql.ErrNoRows),
+				)
+			},
+			count: 2,
+		},
+		{
+			name: "FinishedByAnotherWorker",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().ClaimPendingTransfer(gomock.Any(), gomock.Any()).
+						Return(db.Transfer{ID: 1, Status: db.TransferStatusProcessing}, nil),
+					store.EXPECT().ProcessTransferTx(gomock.Any(), gomock.Eq(int64(1))).
+						Return(db.TransferTxResult{}, db.ErrTransferNotProcessing),
+					store.EXPECT().ClaimPendingTransfer(gomock.Any(), gomock.Any()).
+						Return(db.Transfer{}, sql.ErrNoRows),
+				)
+			},
+			count: 0,
+		},
+		{
+			name: "StoreError",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().ClaimPendingTransfer(gomock.Any(), gomock.Any()).
+						Return(db.Transfer{ID: 1, Status: db.TransferStatusProcessing}, nil),
+					store.EXPECT().ProcessTransferTx(gomock.Any(), gomock.Eq(int64(1))).
+						Return(db.TransferTxResult{}, sql.ErrConnDone),
+				)
+			},
+			count:  0,
+			hasErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			count, err := NewTransferProcessor(store, 1, time.Second, time.Minute).RunDue(context.Background())
+			if tc.hasErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.count, count)
+		})
+	}
+}
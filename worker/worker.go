@@ -0,0 +1,27 @@
+// Package worker runs the background jobs that share the API's store.
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// poll calls runDue right away and then every interval until ctx is
+// cancelled.
+func poll(ctx context.Context, interval time.Duration, name string, runDue func(context.Context) (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := runDue(ctx); err != nil {
+			log.Printf("cannot run %s: %v", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}